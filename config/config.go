@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -25,12 +27,25 @@ type Config struct {
 	EmailPassword string
 	EmailFrom    string
 	FrontendURL  string
+	PublicAPIURL string // Публичный адрес API для ссылок в письмах
 	YandexClientID     string
 	YandexClientSecret string
 	YandexRedirectURI  string
 	FakeYandexAuth     bool // Фейковая авторизация через Яндекс (для разработки)
 	YandexGeocoderAPIKey string // API ключ для Яндекс.Геокодера
+	GeocoderProviders    []string // Порядок опроса геокодеров: yandex, nominatim
+	NominatimURL         string   // Адрес сервера OpenStreetMap Nominatim
+	FakeGeocoder         bool     // Фейковый геокодер без сетевых запросов (для разработки)
 	CORSAllowOrigins   string // Разрешенные источники для CORS (через запятую)
+	TelegramBotToken     string // Токен Telegram-бота (пустой - бот отключен)
+	TelegramBotUsername  string // Имя бота без @ для deep-link ссылок
+	TelegramWebhookSecret string // Секрет для заголовка X-Telegram-Bot-Api-Secret-Token
+	FakeTelegramBot      bool   // Фейковый Telegram-бот (сообщения только пишутся в лог)
+	EventReminderOffsets []time.Duration // За сколько до начала события отправлять напоминания (по убыванию)
+	DefaultTimezone      string // Часовой пояс IANA для событий без города и для расписания cron-задач
+	YooKassaShopID       string // Идентификатор магазина ЮKassa (пустой - оплата отключена)
+	YooKassaSecretKey    string // Секретный ключ ЮKassa
+	FakePayments         bool   // Фейковый платежный провайдер: платежи сразу считаются оплаченными (для разработки)
 }
 
 var AppConfig *Config
@@ -54,12 +69,23 @@ func LoadConfig() {
 		EmailPassword: getEnv("EMAIL_PASSWORD", ""),
 		EmailFrom:    getEnv("EMAIL_FROM", ""),
 		FrontendURL:  getEnv("FRONTEND_URL", "http://localhost:5173"),
+		PublicAPIURL: getEnv("PUBLIC_API_URL", "http://localhost:8081"),
 		YandexClientID:     getEnv("YANDEX_CLIENT_ID", ""),
 		YandexClientSecret: getEnv("YANDEX_CLIENT_SECRET", ""),
 		YandexRedirectURI:  getEnv("YANDEX_REDIRECT_URI", "http://localhost:8081/api/auth/yandex/callback"),
 		FakeYandexAuth:     getEnv("FAKE_YANDEX_AUTH", "false") == "true",
 		YandexGeocoderAPIKey: getEnv("YANDEX_GEOCODER_API_KEY", ""),
+		NominatimURL:         getEnv("NOMINATIM_URL", "https://nominatim.openstreetmap.org"),
+		FakeGeocoder:         getEnv("FAKE_GEOCODER", "false") == "true",
 		CORSAllowOrigins:   getEnv("CORS_ALLOW_ORIGINS", "http://localhost:5173"),
+		TelegramBotToken:     getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramBotUsername:  getEnv("TELEGRAM_BOT_USERNAME", ""),
+		TelegramWebhookSecret: getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+		FakeTelegramBot:      getEnv("FAKE_TELEGRAM_BOT", "false") == "true",
+		DefaultTimezone:      getEnv("DEFAULT_TIMEZONE", "Europe/Moscow"),
+		YooKassaShopID:       getEnv("YOOKASSA_SHOP_ID", ""),
+		YooKassaSecretKey:    getEnv("YOOKASSA_SECRET_KEY", ""),
+		FakePayments:         getEnv("FAKE_PAYMENTS", "false") == "true",
 	}
 
 	expirationStr := getEnv("JWT_EXPIRATION", "24h")
@@ -71,6 +97,19 @@ func LoadConfig() {
 
 	port := getEnv("EMAIL_PORT", "587")
 	AppConfig.EmailPort = parseInt(port, 587)
+
+	AppConfig.EventReminderOffsets = parseDurations(getEnv("EVENT_REMINDER_OFFSETS", "168h,24h,2h"))
+	AppConfig.GeocoderProviders = parseList(getEnv("GEOCODER_PROVIDERS", "yandex,nominatim"))
+
+	// Без секрета любой может отправить на вебхук поддельное обновление от имени привязанного пользователя
+	if AppConfig.TelegramBotToken != "" && !AppConfig.FakeTelegramBot && AppConfig.TelegramWebhookSecret == "" {
+		log.Fatal("TELEGRAM_WEBHOOK_SECRET обязателен, если задан TELEGRAM_BOT_TOKEN")
+	}
+
+	if _, err := time.LoadLocation(AppConfig.DefaultTimezone); err != nil || AppConfig.DefaultTimezone == "Local" {
+		log.Printf("Неизвестный часовой пояс DEFAULT_TIMEZONE=%q, используется Europe/Moscow", AppConfig.DefaultTimezone)
+		AppConfig.DefaultTimezone = "Europe/Moscow"
+	}
 }
 
 func getEnv(key, defaultValue string) string {
@@ -80,6 +119,29 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// parseDurations разбирает список интервалов через запятую, пропуская некорректные, и сортирует по убыванию
+func parseDurations(s string) []time.Duration {
+	var result []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		if d, err := time.ParseDuration(strings.TrimSpace(part)); err == nil && d > 0 {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] > result[j] })
+	return result
+}
+
+// parseList разбирает список значений через запятую, пропуская пустые
+func parseList(s string) []string {
+	var result []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
 func parseInt(s string, defaultValue int) int {
 	var result int
 	if _, err := fmt.Sscanf(s, "%d", &result); err != nil {
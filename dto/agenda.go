@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateSpeakerRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Bio      string   `json:"bio"`
+	PhotoURL string   `json:"photoURL"`
+	Links    []string `json:"links"`
+}
+
+type UpdateSpeakerRequest struct {
+	Name     string   `json:"name"`
+	Bio      *string  `json:"bio"`
+	PhotoURL *string  `json:"photoURL"`
+	Links    []string `json:"links"` // Заменяет все ссылки
+}
+
+type CreateSessionRequest struct {
+	Title       string      `json:"title" binding:"required"`
+	Description string      `json:"description"`
+	StartTime   time.Time   `json:"startTime" binding:"required"`
+	EndTime     time.Time   `json:"endTime" binding:"required"`
+	Room        string      `json:"room"`
+	Track       string      `json:"track"`
+	Capacity    *int        `json:"capacity"`
+	SpeakerIDs  []uuid.UUID `json:"speakerIDs"`
+}
+
+type UpdateSessionRequest struct {
+	Title       string      `json:"title"`
+	Description *string     `json:"description"`
+	StartTime   time.Time   `json:"startTime"`
+	EndTime     time.Time   `json:"endTime"`
+	Room        *string     `json:"room"`
+	Track       *string     `json:"track"`
+	Capacity    *int        `json:"capacity"`   // 0 снимает ограничение
+	SpeakerIDs  []uuid.UUID `json:"speakerIDs"` // Заменяет всех спикеров сессии
+}
+
+type SpeakerResponse struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Bio      string   `json:"bio"`
+	PhotoURL string   `json:"photoURL"`
+	Links    []string `json:"links"`
+}
+
+type SessionResponse struct {
+	ID             string            `json:"id"`
+	Title          string            `json:"title"`
+	Description    string            `json:"description"`
+	StartTime      time.Time         `json:"startTime"`
+	EndTime        time.Time         `json:"endTime"`
+	Room           string            `json:"room"`
+	Track          string            `json:"track"`
+	Capacity       *int              `json:"capacity"`
+	AttendeesCount int64             `json:"attendeesCount"` // Сколько участников добавили сессию в личную программу
+	PlacesLeft     *int              `json:"placesLeft"`
+	InMyAgenda     bool              `json:"inMyAgenda"`
+	Speakers       []SpeakerResponse `json:"speakers"`
+}
+
+// AgendaResponse - программа события. Время сессий указано в часовом поясе события
+type AgendaResponse struct {
+	EventID  string            `json:"eventID"`
+	Timezone string            `json:"timezone"`
+	Tracks   []string          `json:"tracks"`
+	Sessions []SessionResponse `json:"sessions"`
+	Speakers []SpeakerResponse `json:"speakers"`
+}
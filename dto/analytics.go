@@ -0,0 +1,22 @@
+package dto
+
+type EventAnalyticsResponse struct {
+	EventID           string                `json:"eventID"`
+	Views             int64                 `json:"views"`         // Просмотры страницы, не более одного на зрителя в день
+	UniqueViewers     int64                 `json:"uniqueViewers"` // Разные пользователи и анонимные сессии
+	ParticipantsCount int64                 `json:"participantsCount"`
+	Joins             int64                 `json:"joins"`            // Все записи, включая впоследствии отмененные
+	Leaves            int64                 `json:"leaves"`           // Отмены участия
+	ConversionRate    float64               `json:"conversionRate"`   // Доля авторизованных зрителей, записавшихся на событие (0-1)
+	CancellationRate  float64               `json:"cancellationRate"` // Доля отмен от записей (0-1)
+	Daily             []EventAnalyticsDay   `json:"daily"`
+	Reviews           ReviewSummaryResponse `json:"reviews"`
+}
+
+type EventAnalyticsDay struct {
+	Date         string `json:"date"` // YYYY-MM-DD
+	Views        int64  `json:"views"`
+	Joins        int64  `json:"joins"`
+	Leaves       int64  `json:"leaves"`
+	Participants int64  `json:"participants"` // Участников на конец дня
+}
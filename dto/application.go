@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type RejectApplicationRequest struct {
+	Reason string `json:"reason"` // Необязательная причина, сообщается заявителю
+}
+
+type ApplicationAnswerResponse struct {
+	QuestionID string `json:"questionID"`
+	Label      string `json:"label"`
+	Value      string `json:"value"`
+}
+
+type ApplicationResponse struct {
+	ID        string                      `json:"id"`
+	User      UserInfo                    `json:"user"`
+	Message   string                      `json:"message"`
+	Status    string                      `json:"status"`
+	Reason    string                      `json:"reason,omitempty"`
+	Answers   []ApplicationAnswerResponse `json:"answers"`
+	CreatedAt time.Time                   `json:"createdAt"`
+	DecidedAt *time.Time                  `json:"decidedAt"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type BookmarkRequest struct {
+	NotifyNearlyFull *bool `json:"notifyNearlyFull"` // Напомнить, когда на событии почти не останется мест
+}
+
+type BookmarkResponse struct {
+	EventID          string    `json:"eventID"`
+	NotifyNearlyFull bool      `json:"notifyNearlyFull"`
+	BookmarkedAt     time.Time `json:"bookmarkedAt"`
+}
+
+// BookmarkedEventResponse - событие из избранного пользователя
+type BookmarkedEventResponse struct {
+	Event            EventResponse `json:"event"`
+	NotifyNearlyFull bool          `json:"notifyNearlyFull"`
+	BookmarkedAt     time.Time     `json:"bookmarkedAt"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+type CreateCityRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Timezone  string   `json:"timezone" binding:"required"` // IANA, например Europe/Moscow
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+	RadiusKm  *float64 `json:"radiusKm"` // По умолчанию 50 км
+}
+
+type UpdateCityRequest struct {
+	Name      string   `json:"name"`
+	Timezone  string   `json:"timezone"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	RadiusKm  *float64 `json:"radiusKm"`
+}
+
+type CityResponse struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Timezone  string  `json:"timezone"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	RadiusKm  float64 `json:"radiusKm"`
+}
+
+// CityInfo - краткие сведения о городе в профиле, карточке события и сообщества
+type CityInfo struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Timezone string `json:"timezone"`
+}
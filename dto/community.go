@@ -5,6 +5,7 @@ type CreateCommunityRequest struct {
 	Description string    `json:"description"`
 	InterestIDs []string  `json:"interestIDs"`
 	AutoNotify  bool      `json:"autoNotify"`
+	CityID      string    `json:"cityID"`
 }
 
 type UpdateCommunityRequest struct {
@@ -12,6 +13,7 @@ type UpdateCommunityRequest struct {
 	Description string   `json:"description"`
 	InterestIDs  []string `json:"interestIDs"`
 	AutoNotify  *bool    `json:"autoNotify"`
+	CityID      *string  `json:"cityID"` // Пустая строка отвязывает сообщество от города
 }
 
 type CommunityResponse struct {
@@ -22,6 +24,7 @@ type CommunityResponse struct {
 	Admin        UserInfo        `json:"admin"`
 	AutoNotify   bool            `json:"autoNotify"`
 	MembersCount int             `json:"membersCount"`
+	City         *CityInfo       `json:"city,omitempty"`
 	CreatedAt    string          `json:"createdAt"`
 }
 
@@ -16,12 +16,19 @@ type CreateEventRequest struct {
 	PaymentInfo     string     `json:"paymentInfo"`
 	MaxParticipants *int       `json:"maxParticipants"`
 	ParticipantIDs  []uuid.UUID `json:"participantIDs"`
+	ReminderMessage string     `json:"reminderMessage"`
 	CategoryIDs     []uuid.UUID `json:"categoryIDs"`
 	Tags            []string   `json:"tags"`
 	Address         string     `json:"address"`
 	Latitude        *float64   `json:"latitude"`
 	Longitude       *float64   `json:"longitude"`
 	YandexMapLink   string     `json:"yandexMapLink"`
+	VenueID         *uuid.UUID `json:"venueID"` // Площадка: адрес и координаты копируются с нее
+	CityID          *uuid.UUID `json:"cityID"` // Город; если не указан, определяется по координатам
+	Timezone        string     `json:"timezone"` // IANA; если не указан, берется часовой пояс города
+	Visibility      string     `json:"visibility"` // public (по умолчанию), unlisted, invite_only, community
+	CommunityID     *uuid.UUID `json:"communityID"` // Обязателен для visibility=community
+	RequiresApproval bool      `json:"requiresApproval"` // Запись по заявкам, которые одобряет организатор
 }
 
 type UpdateEventRequest struct {
@@ -33,6 +40,7 @@ type UpdateEventRequest struct {
 	ImageURL        string    `json:"imageURL"`
 	PaymentInfo     string    `json:"paymentInfo"`
 	MaxParticipants *int      `json:"maxParticipants"`
+	ReminderMessage *string   `json:"reminderMessage"` // Пустая строка удаляет текст напоминания
 	Status          string    `json:"status"`
 	CategoryIDs     []uuid.UUID `json:"categoryIDs"` // Категории события
 	Tags            []string  `json:"tags"` // Теги события (массив строк)
@@ -40,6 +48,12 @@ type UpdateEventRequest struct {
 	Latitude        *float64  `json:"latitude"`
 	Longitude       *float64  `json:"longitude"`
 	YandexMapLink   string    `json:"yandexMapLink"`
+	VenueID         *uuid.UUID `json:"venueID"` // Площадка: адрес и координаты копируются с нее
+	CityID          *uuid.UUID `json:"cityID"` // Город; если не указан, определяется по координатам
+	Timezone        string     `json:"timezone"`
+	Visibility      string     `json:"visibility"`
+	CommunityID     *uuid.UUID `json:"communityID"`
+	RequiresApproval *bool     `json:"requiresApproval"`
 }
 
 type CategoryInfo struct {
@@ -48,6 +62,45 @@ type CategoryInfo struct {
 	Description string `json:"description,omitempty"`
 }
 
+type EventListResponse struct {
+	Data       []EventResponse   `json:"data"`
+	Pagination *Pagination       `json:"pagination,omitempty"` // При пагинации page/limit
+	Cursor     *CursorPagination `json:"cursor,omitempty"`     // При пагинации after/before
+	Facets     *EventFacets      `json:"facets,omitempty"`     // Только при facets=true
+	CityID     string            `json:"cityID,omitempty"`     // Город, по которому отфильтрован список (явный или город пользователя по умолчанию)
+}
+
+// EventFacets - количество событий по значениям фильтров каталога с учетом остальных выбранных фильтров
+type EventFacets struct {
+	Categories   []CategoryFacet `json:"categories"`
+	Tags         []TagFacet      `json:"tags"` // Топ-20 тегов
+	Price        PriceFacet      `json:"price"`
+	Dates        DateFacet       `json:"dates"`
+	HasFreeSeats int64           `json:"hasFreeSeats"`
+}
+
+type CategoryFacet struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type TagFacet struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+type PriceFacet struct {
+	Free int64 `json:"free"`
+	Paid int64 `json:"paid"`
+}
+
+type DateFacet struct {
+	Week    int64 `json:"week"`
+	Weekend int64 `json:"weekend"`
+	Month   int64 `json:"month"`
+}
+
 type PaginationResponse struct {
 	Data       interface{} `json:"data"`
 	Pagination Pagination  `json:"pagination"`
@@ -60,6 +113,20 @@ type Pagination struct {
 	TotalPages int   `json:"totalPages"`
 }
 
+// CursorPaginationResponse - список с пагинацией по курсорам (after/before)
+type CursorPaginationResponse struct {
+	Data   interface{}      `json:"data"`
+	Cursor CursorPagination `json:"cursor"`
+}
+
+// CursorPagination - непрозрачные курсоры соседних страниц; пустой курсор означает, что страницы нет
+type CursorPagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"` // Только при withTotal=true
+}
+
 type EventResponse struct {
 	ID               string       `json:"id"`
 	Title            string       `json:"title"`
@@ -67,10 +134,13 @@ type EventResponse struct {
 	FullDescription  string       `json:"fullDescription"`
 	StartDate        time.Time    `json:"startDate"`
 	EndDate          time.Time    `json:"endDate"`
+	Timezone         string       `json:"timezone"` // IANA; даты отдаются со смещением этого пояса
 	ImageURL         string       `json:"imageURL"`
 	PaymentInfo      string       `json:"paymentInfo"`
 	MaxParticipants  *int         `json:"maxParticipants"`
 	Status           string       `json:"status"`
+	Visibility       string       `json:"visibility"`
+	RequiresApproval bool         `json:"requiresApproval"`
 	ParticipantsCount int         `json:"participantsCount"`
 	Categories       []CategoryInfo `json:"categories"` // Категории события
 	Tags             []string     `json:"tags"` // Теги события
@@ -78,6 +148,18 @@ type EventResponse struct {
 	Latitude         *float64     `json:"latitude"`
 	Longitude        *float64     `json:"longitude"`
 	YandexMapLink    string       `json:"yandexMapLink"`
+	TwoGISMapLink    string       `json:"twoGISMapLink"`
+	OSMMapLink       string       `json:"osmMapLink"`
+	GeocodeStatus    string       `json:"geocodeStatus,omitempty"` // Только для организатора и администратора: pending, resolved, manual, failed
+	VenueID          string       `json:"venueID,omitempty"`
+	CityID           string       `json:"cityID,omitempty"`
+	CancellationReason string     `json:"cancellationReason,omitempty"`
+	RescheduledTo    *time.Time   `json:"rescheduledTo,omitempty"`
+	SearchRank       float64      `json:"searchRank,omitempty"`     // Релевантность при поиске
+	TitleHighlight   string       `json:"titleHighlight,omitempty"` // HTML: экранированное название с подсветкой <mark>
+	SearchSnippet    string       `json:"searchSnippet,omitempty"`  // HTML: экранированный фрагмент описания с подсветкой <mark>
+	DistanceKm       *float64     `json:"distanceKm,omitempty"`     // Расстояние от точки lat/lon при геопоиске
+	IsBookmarked     *bool        `json:"isBookmarked,omitempty"`   // В избранном у текущего пользователя; только для авторизованных запросов
 	Organizer        UserInfo     `json:"organizer"`
 }
 
@@ -88,20 +170,59 @@ type EventDetailResponse struct {
 	FullDescription  string         `json:"fullDescription"`
 	StartDate        time.Time      `json:"startDate"`
 	EndDate          time.Time      `json:"endDate"`
+	Timezone         string         `json:"timezone"`
 	ImageURL         string         `json:"imageURL"`
 	PaymentInfo      string         `json:"paymentInfo"`
 	MaxParticipants  *int           `json:"maxParticipants"`
 	Status           string         `json:"status"`
+	Visibility       string         `json:"visibility"`
+	CommunityID      string         `json:"communityID,omitempty"`
+	ShareLink        string         `json:"shareLink,omitempty"` // Ссылка на событие unlisted; только для организатора и администратора
+	RequiresApproval bool           `json:"requiresApproval"`
 	ParticipantsCount int           `json:"participantsCount"`
 	IsParticipant    bool           `json:"isParticipant"`
+	ApplicationStatus string        `json:"applicationStatus,omitempty"` // Статус заявки текущего пользователя: pending, rejected
+	PendingApplications *int64      `json:"pendingApplications,omitempty"` // Заявки на рассмотрении; только для организатора и администратора
+	TicketTypes      []TicketTypeResponse `json:"ticketTypes,omitempty"` // Билеты события; без них участие бесплатное
+	IsBookmarked     *bool          `json:"isBookmarked,omitempty"` // Только для авторизованных запросов
 	AverageRating    float64        `json:"averageRating"`
 	TotalReviews     int            `json:"totalReviews"`
+	ReminderMessage  string         `json:"reminderMessage,omitempty"`
 	Categories       []CategoryInfo  `json:"categories"` // Категории события
 	Tags             []string       `json:"tags"` // Теги события
 	Address          string         `json:"address"`
 	Latitude         *float64       `json:"latitude"`
 	Longitude        *float64       `json:"longitude"`
 	YandexMapLink    string         `json:"yandexMapLink"`
+	TwoGISMapLink    string         `json:"twoGISMapLink"`
+	OSMMapLink       string         `json:"osmMapLink"`
+	GeocodeStatus    string         `json:"geocodeStatus,omitempty"` // Только для организатора и администратора: pending, resolved, manual, failed
+	Venue            *VenueInfo     `json:"venue,omitempty"`
+	City             *CityInfo      `json:"city,omitempty"`
+	CancellationReason string       `json:"cancellationReason,omitempty"`
+	CancelledAt      *time.Time     `json:"cancelledAt,omitempty"`
+	RescheduledTo    *time.Time     `json:"rescheduledTo,omitempty"`
 	Organizer        UserInfo       `json:"organizer"`
 }
 
+type CancelEventRequest struct {
+	Reason        string     `json:"reason" binding:"required"`
+	RescheduledTo *time.Time `json:"rescheduledTo"` // Необязательная новая дата проведения
+}
+
+
+type EventMapMarker struct {
+	ID                string    `json:"id"`
+	Title             string    `json:"title"`
+	StartDate         time.Time `json:"startDate"`
+	ImageURL          string    `json:"imageURL"`
+	Address           string    `json:"address"`
+	Latitude          float64   `json:"latitude"`
+	Longitude         float64   `json:"longitude"`
+	ParticipantsCount int       `json:"participantsCount"`
+}
+
+type EventMapResponse struct {
+	Data      []EventMapMarker `json:"data"`
+	Truncated bool             `json:"truncated"` // В области больше событий, чем возвращено - нужно приблизить карту
+}
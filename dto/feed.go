@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// RecommendedEventResponse - событие из ленты "для вас" с объяснением, почему оно рекомендовано
+type RecommendedEventResponse struct {
+	Event   EventResponse          `json:"event"`
+	Score   float64                `json:"score"`
+	Reasons []RecommendationReason `json:"reasons"` // По убыванию вклада в оценку
+}
+
+type RecommendationReason struct {
+	Type string `json:"type"` // interest, category, tag, matches, matchesFriends, community
+	Text string `json:"text"`
+}
+
+type RecommendedEventsResponse struct {
+	Data []RecommendedEventResponse `json:"data"`
+}
+
+// SimilarEventResponse - событие из блока "похожие события" и составляющие его оценки
+type SimilarEventResponse struct {
+	Event            EventResponse `json:"event"`
+	Score            float64       `json:"score"`
+	SharedCategories int           `json:"sharedCategories"`
+	SharedTags       int           `json:"sharedTags"`
+	CoParticipants   int           `json:"coParticipants"`       // Сколько участников исходного события записались и на это
+	DistanceKm       *float64      `json:"distanceKm,omitempty"` // Если у обоих событий есть координаты
+}
+
+type SimilarEventsResponse struct {
+	Data       []SimilarEventResponse `json:"data"`
+	ComputedAt *time.Time             `json:"computedAt,omitempty"` // Время последнего пересчета
+}
+
+// TrendingEventResponse - событие из подборки "в тренде"
+type TrendingEventResponse struct {
+	Event           EventResponse `json:"event"`
+	Score           float64       `json:"score"`
+	RecentJoins     int           `json:"recentJoins"`               // Записей за последние 14 дней
+	RecentViews     int           `json:"recentViews"`               // Просмотров за последние 14 дней
+	OrganizerRating *float64      `json:"organizerRating,omitempty"` // Средняя оценка прошлых событий организатора
+}
+
+type TrendingEventsResponse struct {
+	Data       []TrendingEventResponse `json:"data"`
+	ComputedAt *time.Time              `json:"computedAt,omitempty"` // Время последнего пересчета
+}
@@ -14,6 +14,9 @@ type GeocodeResponse struct {
 	Longitude     float64 `json:"longitude"`
 	Address       string  `json:"address"`
 	YandexMapLink string  `json:"yandexMapLink"`
+	TwoGISMapLink string  `json:"twoGISMapLink"`
+	OSMMapLink    string  `json:"osmMapLink"`
+	Provider      string  `json:"provider"` // Геокодер, вернувший результат: yandex, nominatim или fake
 }
 
 type MapLinkRequest struct {
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type CreateInvitationsRequest struct {
+	Emails []string `json:"emails" binding:"required"`
+}
+
+type InvitationResponse struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	UserID     string     `json:"userID,omitempty"`
+	AcceptedAt *time.Time `json:"acceptedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type ShareLinkResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
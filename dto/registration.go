@@ -0,0 +1,41 @@
+package dto
+
+import "github.com/google/uuid"
+
+type RegistrationQuestionRequest struct {
+	ID       *uuid.UUID `json:"id"` // Существующий вопрос; без id создается новый
+	Type     string     `json:"type"`
+	Label    string     `json:"label"`
+	Options  []string   `json:"options"` // Варианты для single_choice и multi_choice
+	Required bool       `json:"required"`
+}
+
+// UpdateRegistrationFormRequest заменяет всю анкету события; пустой список вопросов удаляет анкету
+type UpdateRegistrationFormRequest struct {
+	Questions []RegistrationQuestionRequest `json:"questions"`
+}
+
+type RegistrationQuestionResponse struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Label    string   `json:"label"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+type RegistrationFormResponse struct {
+	EventID   string                         `json:"eventID"`
+	Questions []RegistrationQuestionResponse `json:"questions"`
+}
+
+type RegistrationAnswerRequest struct {
+	QuestionID uuid.UUID `json:"questionID"`
+	Value      string    `json:"value"`  // Ответ для text, single_choice и checkbox ("true" - отмечено)
+	Values     []string  `json:"values"` // Выбранные варианты для multi_choice
+}
+
+type JoinEventRequest struct {
+	Answers      []RegistrationAnswerRequest `json:"answers"`
+	Message      string                      `json:"message"`      // Сообщение организатору в заявке на событие с одобрением
+	TicketTypeID *uuid.UUID                  `json:"ticketTypeID"` // Обязателен, если у события есть билеты
+}
@@ -5,6 +5,12 @@ type CreateReviewRequest struct {
 	Comment string `json:"comment"`
 }
 
+// QuickReviewRequest - подтверждение оценки по ссылке из приглашения (форма страницы подтверждения или JSON)
+type QuickReviewRequest struct {
+	Token  string `form:"token" json:"token" binding:"required"`
+	Rating int    `form:"rating" json:"rating" binding:"required"`
+}
+
 type UpdateReviewRequest struct {
 	Rating  int    `json:"rating"`
 	Comment string `json:"comment"`
@@ -21,10 +27,20 @@ type ReviewResponse struct {
 	UpdatedAt string    `json:"updatedAt"`
 }
 
+type ReviewSummaryResponse struct {
+	EventID           string           `json:"eventID"`
+	AverageRating     float64          `json:"averageRating"`
+	TotalReviews      int64            `json:"totalReviews"`
+	Distribution      map[string]int64 `json:"distribution"` // Количество отзывов по оценкам "1"-"5"
+	ParticipantsCount int64            `json:"participantsCount"`
+	ResponseRate      float64          `json:"responseRate"` // Доля участников, оставивших отзыв (0-1)
+}
+
 type ReviewsResponse struct {
-	Data          []ReviewResponse `json:"data"`
-	AverageRating float64          `json:"averageRating"`
-	TotalReviews  int64            `json:"totalReviews"`
-	Pagination    Pagination       `json:"pagination"`
+	Data          []ReviewResponse  `json:"data"`
+	AverageRating float64           `json:"averageRating"`
+	TotalReviews  int64             `json:"totalReviews"`
+	Pagination    *Pagination       `json:"pagination,omitempty"` // При пагинации page/limit
+	Cursor        *CursorPagination `json:"cursor,omitempty"`     // При пагинации after/before
 }
 
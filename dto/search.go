@@ -0,0 +1,23 @@
+package dto
+
+type SuggestionType string
+
+const (
+	SuggestionTypeEvent     SuggestionType = "event"
+	SuggestionTypeTag       SuggestionType = "tag"
+	SuggestionTypeCategory  SuggestionType = "category"
+	SuggestionTypeCommunity SuggestionType = "community"
+	SuggestionTypeInterest  SuggestionType = "interest"
+)
+
+type Suggestion struct {
+	Type  SuggestionType `json:"type"`
+	ID    string         `json:"id,omitempty"` // Для тегов не заполняется
+	Text  string         `json:"text"`
+	Score float64        `json:"score"` // Сходство с запросом (0-1)
+}
+
+type SuggestResponse struct {
+	Query       string       `json:"query"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
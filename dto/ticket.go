@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+// CreateTicketTypeRequest - цены указываются в копейках
+type CreateTicketTypeRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	Price       int64      `json:"price"` // 0 - бесплатный билет
+	Quota       *int       `json:"quota"` // Без значения - без ограничения
+	SalesStart  *time.Time `json:"salesStart"`
+	SalesEnd    *time.Time `json:"salesEnd"`
+}
+
+// UpdateTicketTypeRequest - изменяются только переданные поля
+type UpdateTicketTypeRequest struct {
+	Name        *string    `json:"name"`
+	Description *string    `json:"description"`
+	Price       *int64     `json:"price"` // Действует для новых заказов
+	Quota       *int       `json:"quota"` // 0 снимает ограничение
+	SalesStart  *time.Time `json:"salesStart"`
+	SalesEnd    *time.Time `json:"salesEnd"`
+}
+
+type TicketTypeResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Price       int64      `json:"price"`
+	Currency    string     `json:"currency"`
+	Quota       *int       `json:"quota"`
+	Available   *int       `json:"available"` // Осталось билетов; null - без ограничения
+	OnSale      bool       `json:"onSale"`
+	SalesStart  *time.Time `json:"salesStart"`
+	SalesEnd    *time.Time `json:"salesEnd"`
+}
+
+type OrderResponse struct {
+	ID              string     `json:"id"`
+	EventID         string     `json:"eventID"`
+	EventTitle      string     `json:"eventTitle,omitempty"`
+	TicketTypeID    string     `json:"ticketTypeID"`
+	TicketTypeName  string     `json:"ticketTypeName"`
+	Amount          int64      `json:"amount"`
+	Currency        string     `json:"currency"`
+	Status          string     `json:"status"`
+	ConfirmationURL string     `json:"confirmationURL,omitempty"` // Страница оплаты; только для неоплаченного заказа
+	ExpiresAt       time.Time  `json:"expiresAt"`
+	PaidAt          *time.Time `json:"paidAt"`
+	RefundedAt      *time.Time `json:"refundedAt"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	User            *UserInfo  `json:"user,omitempty"` // Покупатель; в списке заказов события
+}
@@ -0,0 +1,51 @@
+package dto
+
+type CreateVenueRequest struct {
+	Name              string   `json:"name" binding:"required"`
+	Description       string   `json:"description"`
+	Address           string   `json:"address" binding:"required"`
+	Latitude          *float64 `json:"latitude"`
+	Longitude         *float64 `json:"longitude"`
+	Capacity          *int     `json:"capacity"`
+	AccessibilityInfo string   `json:"accessibilityInfo"`
+	Photos            []string `json:"photos"`
+	CityID            *string  `json:"cityID"` // Если не указан, определяется по координатам
+}
+
+type UpdateVenueRequest struct {
+	Name              string   `json:"name"`
+	Description       *string  `json:"description"`
+	Address           string   `json:"address"`
+	Latitude          *float64 `json:"latitude"`
+	Longitude         *float64 `json:"longitude"`
+	Capacity          *int     `json:"capacity"`
+	AccessibilityInfo *string  `json:"accessibilityInfo"`
+	Photos            []string `json:"photos"` // Заменяет все фотографии
+	CityID            *string  `json:"cityID"`
+}
+
+type VenueResponse struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	Address           string   `json:"address"`
+	Latitude          *float64 `json:"latitude"`
+	Longitude         *float64 `json:"longitude"`
+	YandexMapLink     string   `json:"yandexMapLink"`
+	TwoGISMapLink     string   `json:"twoGISMapLink"`
+	OSMMapLink        string   `json:"osmMapLink"`
+	Capacity          *int     `json:"capacity"`
+	AccessibilityInfo string   `json:"accessibilityInfo"`
+	Photos            []string `json:"photos"`
+	CityID            string   `json:"cityID,omitempty"`
+	CreatedBy         UserInfo `json:"createdBy"`
+	CreatedAt         string   `json:"createdAt"`
+}
+
+// VenueInfo - краткие сведения о площадке в карточке события
+type VenueInfo struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	Capacity          *int   `json:"capacity"`
+	AccessibilityInfo string `json:"accessibilityInfo"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"eventTypes" binding:"required"`
+	Secret     string   `json:"secret"` // Если не указан, генерируется автоматически
+}
+
+type UpdateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	IsActive   *bool    `json:"isActive"`
+}
+
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	IsActive   bool      `json:"isActive"`
+	Secret     string    `json:"secret,omitempty"` // Возвращается только при создании
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string     `json:"id"`
+	EventType      string     `json:"eventType"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"responseStatus"`
+	LastError      string     `json:"lastError"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
@@ -13,6 +13,7 @@ import (
 	"bekend/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 )
@@ -29,6 +30,14 @@ func NewAdminHandler() *AdminHandler {
 	}
 }
 
+// userKeysetSort - порядок списка пользователей в админке: сначала новые
+var userKeysetSort = keysetSort{
+	name:    "createdAt:desc",
+	columns: []keysetColumn{{expr: "users.created_at", kind: keysetTime}},
+	idExpr:  "users.id",
+	desc:    true,
+}
+
 func (h *AdminHandler) GetUsers(c *gin.Context) {
 	var users []models.User
 	query := database.DB
@@ -47,6 +56,11 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 
 	offset := (pageInt - 1) * limitInt
 
+	cursor, ok := parseCursorPage(c, limitInt)
+	if !ok {
+		return
+	}
+
 	fullName := c.Query("fullName")
 	if fullName != "" {
 		if !utils.ValidateStringLength(fullName, 1, 100) {
@@ -76,27 +90,46 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 
 	dateFrom := c.Query("dateFrom")
 	if dateFrom != "" {
-		if t, err := time.Parse("2006-01-02", dateFrom); err == nil {
+		if t, ok := parseDateFilter(dateFrom, utils.DefaultLocation(), false); ok {
 			query = query.Where("created_at >= ?", t)
 		}
 	}
 
 	dateTo := c.Query("dateTo")
 	if dateTo != "" {
-		if t, err := time.Parse("2006-01-02", dateTo); err == nil {
-			query = query.Where("created_at <= ?", t.Add(24*time.Hour))
+		if t, ok := parseDateFilter(dateTo, utils.DefaultLocation(), true); ok {
+			query = query.Where("created_at <= ?", t)
 		}
 	}
 
 	var total int64
-	query.Model(&models.User{}).Count(&total)
+	if cursor == nil || cursor.withTotal {
+		query.Model(&models.User{}).Count(&total)
+	}
+
+	if cursor != nil {
+		var err error
+		if query, err = cursor.apply(query, userKeysetSort); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный курсор"})
+			return
+		}
+	} else {
+		query = query.Offset(offset).Limit(limitInt).Order("created_at DESC")
+	}
 
-	if err := query.Offset(offset).Limit(limitInt).Order("created_at DESC").Find(&users).Error; err != nil {
+	if err := query.Find(&users).Error; err != nil {
 		h.logger.Error("Ошибка при получении пользователей", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении пользователей"})
 		return
 	}
 
+	var cursorInfo dto.CursorPagination
+	if cursor != nil {
+		users, cursorInfo = cursorPagination(cursor, userKeysetSort, users, func(user models.User) ([]interface{}, uuid.UUID) {
+			return []interface{}{user.CreatedAt}, user.ID
+		})
+	}
+
 	result := make([]dto.UserResponse, len(users))
 	for i, user := range users {
 		result[i] = dto.UserResponse{
@@ -109,6 +142,17 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 		}
 	}
 
+	if cursor != nil {
+		if cursor.withTotal {
+			cursorInfo.Total = &total
+		}
+		c.JSON(http.StatusOK, dto.CursorPaginationResponse{
+			Data:   result,
+			Cursor: cursorInfo,
+		})
+		return
+	}
+
 	totalPages := int((total + int64(limitInt) - 1) / int64(limitInt))
 	c.JSON(http.StatusOK, dto.PaginationResponse{
 		Data: result,
@@ -411,17 +455,23 @@ func (h *AdminHandler) GetAdminEvents(c *gin.Context) {
 			Title:            event.Title,
 			ShortDescription: event.ShortDescription,
 			FullDescription:  event.FullDescription,
-			StartDate:        event.StartDate,
-			EndDate:          event.EndDate,
+			StartDate:        event.StartDate.In(utils.EventLocation(event.Timezone)),
+			EndDate:          event.EndDate.In(utils.EventLocation(event.Timezone)),
+			Timezone:         utils.EventLocation(event.Timezone).String(),
 			ImageURL:         event.ImageURL,
 			PaymentInfo:      event.PaymentInfo,
 			MaxParticipants:  event.MaxParticipants,
 			Status:           string(event.Status),
+			Visibility:       string(event.Visibility),
+			RequiresApproval: event.RequiresApproval,
 			ParticipantsCount: event.GetParticipantsCount(),
 			Address:          event.Address,
 			Latitude:         event.Latitude,
 			Longitude:        event.Longitude,
 			YandexMapLink:    event.YandexMapLink,
+			TwoGISMapLink:    event.TwoGISMapLink,
+			OSMMapLink:       event.OSMMapLink,
+			GeocodeStatus:    string(event.GeocodeStatus),
 			Organizer: dto.UserInfo{
 				ID:       event.Organizer.ID.String(),
 				FullName: event.Organizer.FullName,
@@ -477,15 +527,15 @@ func (h *AdminHandler) ExportUsers(c *gin.Context) {
 
 	dateFrom := c.Query("dateFrom")
 	if dateFrom != "" {
-		if t, err := time.Parse("2006-01-02", dateFrom); err == nil {
+		if t, ok := parseDateFilter(dateFrom, utils.DefaultLocation(), false); ok {
 			query = query.Where("created_at >= ?", t)
 		}
 	}
 
 	dateTo := c.Query("dateTo")
 	if dateTo != "" {
-		if t, err := time.Parse("2006-01-02", dateTo); err == nil {
-			query = query.Where("created_at <= ?", t.Add(24*time.Hour))
+		if t, ok := parseDateFilter(dateTo, utils.DefaultLocation(), true); ok {
+			query = query.Where("created_at <= ?", t)
 		}
 	}
 
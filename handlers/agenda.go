@@ -0,0 +1,859 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errSessionFull     = errors.New("session is full")
+	errAlreadyInAgenda = errors.New("session already in agenda")
+)
+
+type AgendaHandler struct {
+	logger *zap.Logger
+}
+
+func NewAgendaHandler() *AgendaHandler {
+	return &AgendaHandler{
+		logger: utils.GetLogger(),
+	}
+}
+
+// GetAgenda godoc
+// @Summary Программа события
+// @Description Сессии события по времени начала со спикерами, список треков и спикеров. Время указано в часовом поясе события
+// @Tags Программа события
+// @Produce json
+// @Param id path string true "UUID события"
+// @Param track query string false "Фильтр по треку"
+// @Param my query bool false "Только сессии из личной программы (требуется авторизация)"
+// @Success 200 {object} dto.AgendaResponse "Программа события"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/agenda [get]
+func (h *AgendaHandler) GetAgenda(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Select(append(eventAccessColumns, "timezone")).Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	if event.Status == models.EventStatusRejected && (userID == nil || c.GetString("role") != "Администратор") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	query := database.DB.Preload("Speakers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("speakers.name ASC")
+	}).Where("event_id = ?", event.ID)
+	if track := c.Query("track"); track != "" {
+		query = query.Where("track = ?", track)
+	}
+	if c.Query("my") == "true" {
+		if userID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Требуется авторизация"})
+			return
+		}
+		query = query.Where("id IN (?)", database.DB.Model(&models.SessionBookmark{}).Select("session_id").Where("user_id = ?", userID))
+	}
+
+	var sessions []models.EventSession
+	if err := query.Order("start_time ASC, room ASC").Find(&sessions).Error; err != nil {
+		h.logger.Error("Ошибка получения программы события", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении программы"})
+		return
+	}
+
+	var speakers []models.Speaker
+	if err := database.DB.Where("event_id = ?", event.ID).Order("name ASC").Find(&speakers).Error; err != nil {
+		h.logger.Error("Ошибка получения спикеров события", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении программы"})
+		return
+	}
+
+	tracks := []string{}
+	database.DB.Model(&models.EventSession{}).
+		Where("event_id = ? AND track <> ''", event.ID).
+		Distinct("track").Order("track ASC").
+		Pluck("track", &tracks)
+
+	sessionIDs := make([]uuid.UUID, len(sessions))
+	for i, session := range sessions {
+		sessionIDs[i] = session.ID
+	}
+	attendees := sessionAttendees(sessionIDs)
+	inMyAgenda := make(map[uuid.UUID]bool)
+	if userID != nil && len(sessionIDs) > 0 {
+		var bookmarked []uuid.UUID
+		database.DB.Model(&models.SessionBookmark{}).
+			Where("user_id = ? AND session_id IN ?", userID, sessionIDs).
+			Pluck("session_id", &bookmarked)
+		for _, id := range bookmarked {
+			inMyAgenda[id] = true
+		}
+	}
+
+	loc := utils.EventLocation(event.Timezone)
+	response := dto.AgendaResponse{
+		EventID:  event.ID.String(),
+		Timezone: loc.String(),
+		Tracks:   tracks,
+		Sessions: make([]dto.SessionResponse, len(sessions)),
+		Speakers: make([]dto.SpeakerResponse, len(speakers)),
+	}
+	for i, session := range sessions {
+		response.Sessions[i] = sessionToResponse(session, loc, attendees[session.ID], inMyAgenda[session.ID])
+	}
+	for i, speaker := range speakers {
+		response.Speakers[i] = speakerToResponse(speaker)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateSession godoc
+// @Summary Добавить сессию в программу
+// @Description Доступно организатору и администратору. Сессия должна укладываться в даты события, зал не может быть занят другой сессией в это же время
+// @Tags Программа события
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param request body dto.CreateSessionRequest true "Данные сессии"
+// @Success 201 {object} dto.SessionResponse "Сессия создана"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/sessions [post]
+func (h *AgendaHandler) CreateSession(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при создании сессии", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.EventSession{}).Where("event_id = ?", event.ID).Count(&count)
+	if count >= utils.MaxEventSessions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Максимальное количество сессий в программе - %d", utils.MaxEventSessions)})
+		return
+	}
+
+	session := models.EventSession{
+		EventID:     event.ID,
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Room:        strings.TrimSpace(req.Room),
+		Track:       strings.TrimSpace(req.Track),
+		Capacity:    req.Capacity,
+	}
+	if errMsg := validateSession(&session, event); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	if errMsg := sessionRoomConflict(&session); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	speakers, ok := loadSessionSpeakers(c, event.ID, req.SpeakerIDs)
+	if !ok {
+		return
+	}
+	session.Speakers = speakers
+
+	if err := database.DB.Create(&session).Error; err != nil {
+		h.logger.Error("Ошибка создания сессии в БД", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании сессии"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sessionToResponse(session, utils.EventLocation(event.Timezone), 0, false))
+}
+
+// UpdateSession godoc
+// @Summary Обновить сессию
+// @Description Доступно организатору и администратору. Вместимость нельзя сделать меньше числа участников, уже добавивших сессию в программу
+// @Tags Программа события
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param sessionId path string true "UUID сессии"
+// @Param request body dto.UpdateSessionRequest true "Изменяемые поля"
+// @Success 200 {object} dto.SessionResponse "Сессия обновлена"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Сессия не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/sessions/{sessionId} [put]
+func (h *AgendaHandler) UpdateSession(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	session, ok := loadEventSession(c, event.ID)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при обновлении сессии", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if req.Title != "" {
+		session.Title = strings.TrimSpace(req.Title)
+	}
+	if req.Description != nil {
+		session.Description = *req.Description
+	}
+	if !req.StartTime.IsZero() {
+		session.StartTime = req.StartTime
+	}
+	if !req.EndTime.IsZero() {
+		session.EndTime = req.EndTime
+	}
+	if req.Room != nil {
+		session.Room = strings.TrimSpace(*req.Room)
+	}
+	if req.Track != nil {
+		session.Track = strings.TrimSpace(*req.Track)
+	}
+	if req.Capacity != nil {
+		session.Capacity = req.Capacity
+		if *req.Capacity == 0 {
+			session.Capacity = nil
+		}
+	}
+	if errMsg := validateSession(session, event); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	if errMsg := sessionRoomConflict(session); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	attendees := sessionAttendees([]uuid.UUID{session.ID})[session.ID]
+	if session.Capacity != nil && int64(*session.Capacity) < attendees {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Сессию уже добавили в программу %d участников, вместимость не может быть меньше", attendees)})
+		return
+	}
+
+	if req.SpeakerIDs != nil {
+		speakers, ok := loadSessionSpeakers(c, event.ID, req.SpeakerIDs)
+		if !ok {
+			return
+		}
+		session.Speakers = speakers
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Speakers").Save(session).Error; err != nil {
+			return err
+		}
+		if req.SpeakerIDs != nil {
+			return tx.Model(session).Association("Speakers").Replace(session.Speakers)
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("Ошибка обновления сессии", zap.String("sessionID", session.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении сессии"})
+		return
+	}
+
+	inMyAgenda := false
+	if userID, exists := c.Get("userID"); exists {
+		var count int64
+		database.DB.Model(&models.SessionBookmark{}).Where("user_id = ? AND session_id = ?", userID, session.ID).Count(&count)
+		inMyAgenda = count > 0
+	}
+	c.JSON(http.StatusOK, sessionToResponse(*session, utils.EventLocation(event.Timezone), attendees, inMyAgenda))
+}
+
+// DeleteSession godoc
+// @Summary Удалить сессию
+// @Description Доступно организатору и администратору. Сессия удаляется и из личных программ участников
+// @Tags Программа события
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param sessionId path string true "UUID сессии"
+// @Success 200 {object} map[string]string "Сессия удалена"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Сессия не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/sessions/{sessionId} [delete]
+func (h *AgendaHandler) DeleteSession(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	session, ok := loadEventSession(c, event.ID)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", session.ID).Delete(&models.SessionBookmark{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(session).Association("Speakers").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(session).Error
+	})
+	if err != nil {
+		h.logger.Error("Ошибка удаления сессии", zap.String("sessionID", session.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении сессии"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия удалена"})
+}
+
+// CreateSpeaker godoc
+// @Summary Добавить спикера
+// @Description Доступно организатору и администратору. Спикер привязывается к событию и назначается на сессии через speakerIDs
+// @Tags Программа события
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param request body dto.CreateSpeakerRequest true "Данные спикера"
+// @Success 201 {object} dto.SpeakerResponse "Спикер добавлен"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/speakers [post]
+func (h *AgendaHandler) CreateSpeaker(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateSpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при создании спикера", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.Speaker{}).Where("event_id = ?", event.ID).Count(&count)
+	if count >= utils.MaxEventSpeakers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Максимальное количество спикеров события - %d", utils.MaxEventSpeakers)})
+		return
+	}
+
+	speaker := models.Speaker{
+		EventID:  event.ID,
+		Name:     strings.TrimSpace(req.Name),
+		Bio:      req.Bio,
+		PhotoURL: req.PhotoURL,
+		Links:    models.StringArray(req.Links),
+	}
+	if speaker.Links == nil {
+		speaker.Links = models.StringArray{}
+	}
+	if errMsg := validateSpeaker(&speaker); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	if err := database.DB.Create(&speaker).Error; err != nil {
+		h.logger.Error("Ошибка создания спикера в БД", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении спикера"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, speakerToResponse(speaker))
+}
+
+// UpdateSpeaker godoc
+// @Summary Обновить спикера
+// @Description Доступно организатору и администратору
+// @Tags Программа события
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param speakerId path string true "UUID спикера"
+// @Param request body dto.UpdateSpeakerRequest true "Изменяемые поля"
+// @Success 200 {object} dto.SpeakerResponse "Спикер обновлен"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Спикер не найден"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/speakers/{speakerId} [put]
+func (h *AgendaHandler) UpdateSpeaker(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	speaker, ok := loadEventSpeaker(c, event.ID)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateSpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при обновлении спикера", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if req.Name != "" {
+		speaker.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Bio != nil {
+		speaker.Bio = *req.Bio
+	}
+	if req.PhotoURL != nil {
+		speaker.PhotoURL = *req.PhotoURL
+	}
+	if req.Links != nil {
+		speaker.Links = models.StringArray(req.Links)
+	}
+	if errMsg := validateSpeaker(speaker); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	if err := database.DB.Save(speaker).Error; err != nil {
+		h.logger.Error("Ошибка обновления спикера", zap.String("speakerID", speaker.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении спикера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, speakerToResponse(*speaker))
+}
+
+// DeleteSpeaker godoc
+// @Summary Удалить спикера
+// @Description Доступно организатору и администратору. Спикер снимается со всех сессий
+// @Tags Программа события
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param speakerId path string true "UUID спикера"
+// @Success 200 {object} map[string]string "Спикер удален"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Спикер не найден"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/speakers/{speakerId} [delete]
+func (h *AgendaHandler) DeleteSpeaker(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	speaker, ok := loadEventSpeaker(c, event.ID)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(speaker).Association("Sessions").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(speaker).Error
+	})
+	if err != nil {
+		h.logger.Error("Ошибка удаления спикера", zap.String("speakerID", speaker.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении спикера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Спикер удален"})
+}
+
+// AddSessionToAgenda godoc
+// @Summary Добавить сессию в личную программу
+// @Description Доступно участникам активного события. Если у сессии задана вместимость, место закрепляется за участником
+// @Tags Программа события
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param sessionId path string true "UUID сессии"
+// @Success 200 {object} map[string]string "Сессия уже в программе"
+// @Success 201 {object} map[string]string "Сессия добавлена в программу"
+// @Failure 400 {object} map[string]string "Событие не активное"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Вы не участник события"
+// @Failure 404 {object} map[string]string "Сессия не найдена"
+// @Failure 409 {object} map[string]string "Нет свободных мест"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/sessions/{sessionId}/bookmark [post]
+func (h *AgendaHandler) AddSessionToAgenda(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Select("id", "status").Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	session, ok := loadEventSession(c, event.ID)
+	if !ok {
+		return
+	}
+	if event.Status != models.EventStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Программу можно составлять только для активных событий"})
+		return
+	}
+
+	var participants int64
+	database.DB.Model(&models.EventParticipant{}).Where("event_id = ? AND user_id = ?", event.ID, userID).Count(&participants)
+	if participants == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Добавлять сессии в программу могут только участники события"})
+		return
+	}
+
+	// Строка сессии блокируется, чтобы параллельные запросы не заняли больше мест, чем вместимость
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.EventSession
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", session.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&models.SessionBookmark{}).Where("user_id = ? AND session_id = ?", userID, session.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyInAgenda
+		}
+		if locked.Capacity != nil {
+			var taken int64
+			if err := tx.Model(&models.SessionBookmark{}).Where("session_id = ?", session.ID).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken >= int64(*locked.Capacity) {
+				return errSessionFull
+			}
+		}
+		return tx.Create(&models.SessionBookmark{UserID: userID.(uuid.UUID), SessionID: session.ID}).Error
+	})
+	switch {
+	case errors.Is(err, errAlreadyInAgenda):
+		c.JSON(http.StatusOK, gin.H{"message": "Сессия уже в вашей программе"})
+	case errors.Is(err, errSessionFull):
+		c.JSON(http.StatusConflict, gin.H{"error": "На сессии не осталось свободных мест"})
+	case err != nil:
+		h.logger.Error("Ошибка добавления сессии в программу", zap.String("sessionID", session.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении сессии в программу"})
+	default:
+		c.JSON(http.StatusCreated, gin.H{"message": "Сессия добавлена в программу"})
+	}
+}
+
+// RemoveSessionFromAgenda godoc
+// @Summary Убрать сессию из личной программы
+// @Description Освобождает место на сессии с ограниченной вместимостью
+// @Tags Программа события
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param sessionId path string true "UUID сессии"
+// @Success 200 {object} map[string]string "Сессия убрана из программы"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 404 {object} map[string]string "Сессии нет в программе"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/sessions/{sessionId}/bookmark [delete]
+func (h *AgendaHandler) RemoveSessionFromAgenda(c *gin.Context) {
+	eventID := c.Param("id")
+	sessionID := c.Param("sessionId")
+	if !utils.ValidateUUID(eventID) || !utils.ValidateUUID(sessionID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	result := database.DB.
+		Where("user_id = ? AND session_id = ?", userID, sessionID).
+		Where("session_id IN (?)", database.DB.Model(&models.EventSession{}).Select("id").Where("event_id = ?", eventID)).
+		Delete(&models.SessionBookmark{})
+	if result.Error != nil {
+		h.logger.Error("Ошибка удаления сессии из программы", zap.String("sessionID", sessionID), zap.Error(result.Error))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении сессии из программы"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Сессии нет в вашей программе"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия убрана из программы"})
+}
+
+// loadOwnEvent загружает событие из пути и проверяет, что его может менять текущий пользователь (организатор или администратор).
+// При ошибке ответ уже отправлен
+func loadOwnEvent(c *gin.Context) (*models.Event, bool) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return nil, false
+	}
+
+	var event models.Event
+	if err := database.DB.Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("userID")
+	if event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return nil, false
+	}
+	if event.Status == models.EventStatusCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Отмененное событие нельзя изменить"})
+		return nil, false
+	}
+
+	return &event, true
+}
+
+// loadEventSession загружает сессию из пути вместе со спикерами. При ошибке ответ уже отправлен
+func loadEventSession(c *gin.Context, eventID uuid.UUID) (*models.EventSession, bool) {
+	sessionID := c.Param("sessionId")
+	if !utils.ValidateUUID(sessionID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID сессии"})
+		return nil, false
+	}
+
+	var session models.EventSession
+	if err := database.DB.Preload("Speakers").Where("id = ? AND event_id = ?", sessionID, eventID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Сессия не найдена"})
+		return nil, false
+	}
+	return &session, true
+}
+
+// loadEventSpeaker загружает спикера из пути. При ошибке ответ уже отправлен
+func loadEventSpeaker(c *gin.Context, eventID uuid.UUID) (*models.Speaker, bool) {
+	speakerID := c.Param("speakerId")
+	if !utils.ValidateUUID(speakerID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID спикера"})
+		return nil, false
+	}
+
+	var speaker models.Speaker
+	if err := database.DB.Where("id = ? AND event_id = ?", speakerID, eventID).First(&speaker).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Спикер не найден"})
+		return nil, false
+	}
+	return &speaker, true
+}
+
+// loadSessionSpeakers загружает спикеров сессии и проверяет, что все они относятся к событию
+func loadSessionSpeakers(c *gin.Context, eventID uuid.UUID, speakerIDs []uuid.UUID) ([]models.Speaker, bool) {
+	speakers := []models.Speaker{}
+	if len(speakerIDs) == 0 {
+		return speakers, true
+	}
+	if err := database.DB.Where("id IN ? AND event_id = ?", speakerIDs, eventID).Find(&speakers).Error; err != nil || len(speakers) != len(uniqueUUIDs(speakerIDs)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Спикер не найден среди спикеров события"})
+		return nil, false
+	}
+	return speakers, true
+}
+
+func uniqueUUIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// validateSession проверяет поля сессии и возвращает текст ошибки для клиента
+func validateSession(session *models.EventSession, event *models.Event) string {
+	if !utils.ValidateStringLength(session.Title, 1, utils.MaxSessionTitleLength) {
+		return fmt.Sprintf("Название сессии должно быть от 1 до %d символов", utils.MaxSessionTitleLength)
+	}
+	if !utils.ValidateStringLength(session.Description, 0, utils.MaxFullDescriptionLength) {
+		return fmt.Sprintf("Описание сессии должно быть до %d символов", utils.MaxFullDescriptionLength)
+	}
+	if !utils.ValidateStringLength(session.Room, 0, utils.MaxSessionRoomLength) {
+		return fmt.Sprintf("Название зала должно быть до %d символов", utils.MaxSessionRoomLength)
+	}
+	if !utils.ValidateStringLength(session.Track, 0, utils.MaxSessionTrackLength) {
+		return fmt.Sprintf("Название трека должно быть до %d символов", utils.MaxSessionTrackLength)
+	}
+	if !session.EndTime.After(session.StartTime) {
+		return "Время окончания сессии должно быть позже времени начала"
+	}
+	if session.StartTime.Before(event.StartDate) || session.EndTime.After(event.EndDate) {
+		return fmt.Sprintf("Сессия должна проходить в рамках события: с %s до %s",
+			utils.FormatEventTime(event.StartDate, event.Timezone),
+			utils.FormatEventTime(event.EndDate, event.Timezone))
+	}
+	if session.Capacity != nil && *session.Capacity < 1 {
+		return "Вместимость сессии должна быть больше 0"
+	}
+	return ""
+}
+
+// sessionRoomConflict проверяет, что зал не занят другой сессией события в то же время
+func sessionRoomConflict(session *models.EventSession) string {
+	if session.Room == "" {
+		return ""
+	}
+	var count int64
+	database.DB.Model(&models.EventSession{}).
+		Where("event_id = ? AND id <> ? AND LOWER(room) = LOWER(?)", session.EventID, session.ID, session.Room).
+		Where("start_time < ? AND end_time > ?", session.EndTime, session.StartTime).
+		Count(&count)
+	if count > 0 {
+		return fmt.Sprintf("В зале «%s» в это время уже идет другая сессия", session.Room)
+	}
+	return ""
+}
+
+// validateSpeaker проверяет поля спикера и возвращает текст ошибки для клиента
+func validateSpeaker(speaker *models.Speaker) string {
+	if !utils.ValidateStringLength(speaker.Name, 1, utils.MaxSpeakerNameLength) {
+		return fmt.Sprintf("Имя спикера должно быть от 1 до %d символов", utils.MaxSpeakerNameLength)
+	}
+	if !utils.ValidateStringLength(speaker.Bio, 0, utils.MaxSpeakerBioLength) {
+		return fmt.Sprintf("Описание спикера должно быть до %d символов", utils.MaxSpeakerBioLength)
+	}
+	if !utils.ValidateStringLength(speaker.PhotoURL, 0, utils.MaxMapLinkLength) {
+		return fmt.Sprintf("Ссылка на фотографию должна быть до %d символов", utils.MaxMapLinkLength)
+	}
+	if len(speaker.Links) > utils.MaxSpeakerLinks {
+		return fmt.Sprintf("Максимальное количество ссылок спикера - %d", utils.MaxSpeakerLinks)
+	}
+	for _, link := range speaker.Links {
+		if !utils.ValidateStringLength(link, 1, utils.MaxMapLinkLength) {
+			return fmt.Sprintf("Ссылка должна быть от 1 до %d символов", utils.MaxMapLinkLength)
+		}
+	}
+	return ""
+}
+
+// sessionAttendees возвращает, сколько участников добавили каждую сессию в личную программу
+func sessionAttendees(sessionIDs []uuid.UUID) map[uuid.UUID]int64 {
+	result := make(map[uuid.UUID]int64, len(sessionIDs))
+	if len(sessionIDs) == 0 {
+		return result
+	}
+
+	var rows []struct {
+		SessionID uuid.UUID
+		Count     int64
+	}
+	database.DB.Model(&models.SessionBookmark{}).
+		Select("session_id, COUNT(*) AS count").
+		Where("session_id IN ?", sessionIDs).
+		Group("session_id").
+		Scan(&rows)
+	for _, row := range rows {
+		result[row.SessionID] = row.Count
+	}
+	return result
+}
+
+func sessionToResponse(session models.EventSession, loc *time.Location, attendees int64, inMyAgenda bool) dto.SessionResponse {
+	var placesLeft *int
+	if session.Capacity != nil {
+		left := *session.Capacity - int(attendees)
+		if left < 0 {
+			left = 0
+		}
+		placesLeft = &left
+	}
+	speakers := make([]dto.SpeakerResponse, len(session.Speakers))
+	for i, speaker := range session.Speakers {
+		speakers[i] = speakerToResponse(speaker)
+	}
+	return dto.SessionResponse{
+		ID:             session.ID.String(),
+		Title:          session.Title,
+		Description:    session.Description,
+		StartTime:      session.StartTime.In(loc),
+		EndTime:        session.EndTime.In(loc),
+		Room:           session.Room,
+		Track:          session.Track,
+		Capacity:       session.Capacity,
+		AttendeesCount: attendees,
+		PlacesLeft:     placesLeft,
+		InMyAgenda:     inMyAgenda,
+		Speakers:       speakers,
+	}
+}
+
+func speakerToResponse(speaker models.Speaker) dto.SpeakerResponse {
+	links := []string(speaker.Links)
+	if links == nil {
+		links = []string{}
+	}
+	return dto.SpeakerResponse{
+		ID:       speaker.ID.String(),
+		Name:     speaker.Name,
+		Bio:      speaker.Bio,
+		PhotoURL: speaker.PhotoURL,
+		Links:    links,
+	}
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/services"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+	logger           *zap.Logger
+}
+
+func NewAnalyticsHandler() *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: services.NewAnalyticsService(),
+		logger:           utils.GetLogger(),
+	}
+}
+
+// GetEventAnalytics godoc
+// @Summary Аналитика события
+// @Description Просмотры, конверсия в записи, записи и отмены по дням и статистика отзывов. Доступно организатору и администратору
+// @Tags События
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Success 200 {object} dto.EventAnalyticsResponse "Аналитика события"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/analytics [get]
+func (h *AnalyticsHandler) GetEventAnalytics(c *gin.Context) {
+	event, analytics, ok := h.loadAnalytics(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, analyticsToResponse(event, analytics))
+}
+
+// ExportEventAnalytics godoc
+// @Summary Экспорт аналитики события
+// @Description Выгрузка аналитики события в XLSX: сводка, динамика по дням и отзывы
+// @Tags События
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Success 200 {file} file "Файл с аналитикой"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/analytics/export [get]
+func (h *AnalyticsHandler) ExportEventAnalytics(c *gin.Context) {
+	event, analytics, ok := h.loadAnalytics(c)
+	if !ok {
+		return
+	}
+
+	f := excelize.NewFile()
+	defer func() {
+		if err := f.Close(); err != nil {
+			h.logger.Error("Ошибка при закрытии файла Excel", zap.Error(err))
+		}
+	}()
+
+	summarySheet := "Сводка"
+	f.SetSheetName("Sheet1", summarySheet)
+	summaryRows := [][]interface{}{
+		{"Событие", event.Title},
+		{"Дата начала", utils.FormatEventTime(event.StartDate, event.Timezone)},
+		{"Статус", string(event.Status)},
+		{"Просмотры", analytics.Views},
+		{"Уникальные зрители", analytics.UniqueViewers},
+		{"Участники", analytics.ParticipantsCount},
+		{"Записи", analytics.Joins},
+		{"Отмены участия", analytics.Leaves},
+		{"Конверсия просмотров в записи", analytics.ConversionRate},
+		{"Доля отмен", analytics.CancellationRate},
+		{"Отзывы", analytics.Reviews.TotalReviews},
+		{"Средняя оценка", analytics.Reviews.AverageRating},
+		{"Доля участников с отзывом", analytics.Reviews.ResponseRate},
+	}
+	for i, row := range summaryRows {
+		f.SetSheetRow(summarySheet, fmt.Sprintf("A%d", i+1), &row)
+	}
+	percentStyle, err := f.NewStyle(&excelize.Style{NumFmt: 10})
+	if err == nil {
+		f.SetCellStyle(summarySheet, "B9", "B10", percentStyle)
+		f.SetCellStyle(summarySheet, "B13", "B13", percentStyle)
+	}
+	f.SetColWidth(summarySheet, "A", "A", 32)
+	f.SetColWidth(summarySheet, "B", "B", 40)
+
+	dailySheet := "По дням"
+	f.NewSheet(dailySheet)
+	f.SetSheetRow(dailySheet, "A1", &[]interface{}{"Дата", "Просмотры", "Записи", "Отмены", "Участников на конец дня"})
+	for i, day := range analytics.Daily {
+		f.SetSheetRow(dailySheet, fmt.Sprintf("A%d", i+2), &[]interface{}{
+			day.Date.Format("02.01.2006"), day.Views, day.Joins, day.Leaves, day.Participants,
+		})
+	}
+	f.SetColWidth(dailySheet, "A", "E", 18)
+
+	reviewsSheet := "Отзывы"
+	f.NewSheet(reviewsSheet)
+	f.SetSheetRow(reviewsSheet, "A1", &[]interface{}{"Оценка", "Количество"})
+	for rating := 5; rating >= 1; rating-- {
+		f.SetSheetRow(reviewsSheet, fmt.Sprintf("A%d", 7-rating), &[]interface{}{rating, analytics.Reviews.Distribution[rating]})
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename=event-analytics.xlsx")
+
+	if err := f.Write(c.Writer); err != nil {
+		h.logger.Error("Ошибка при записи файла Excel", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при экспорте"})
+		return
+	}
+}
+
+// loadAnalytics проверяет доступ к событию (организатор или администратор) и собирает аналитику.
+// При ошибке ответ уже отправлен
+func (h *AnalyticsHandler) loadAnalytics(c *gin.Context) (*models.Event, *services.EventAnalytics, bool) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return nil, nil, false
+	}
+
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return nil, nil, false
+	}
+
+	if event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return nil, nil, false
+	}
+
+	analytics, err := h.analyticsService.GetEventAnalytics(&event)
+	if err != nil {
+		h.logger.Error("Ошибка получения аналитики события", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении аналитики"})
+		return nil, nil, false
+	}
+
+	return &event, analytics, true
+}
+
+func analyticsToResponse(event *models.Event, analytics *services.EventAnalytics) dto.EventAnalyticsResponse {
+	daily := make([]dto.EventAnalyticsDay, len(analytics.Daily))
+	for i, day := range analytics.Daily {
+		daily[i] = dto.EventAnalyticsDay{
+			Date:         day.Date.Format("2006-01-02"),
+			Views:        day.Views,
+			Joins:        day.Joins,
+			Leaves:       day.Leaves,
+			Participants: day.Participants,
+		}
+	}
+
+	distribution := make(map[string]int64, len(analytics.Reviews.Distribution))
+	for rating, count := range analytics.Reviews.Distribution {
+		distribution[strconv.Itoa(rating)] = count
+	}
+
+	return dto.EventAnalyticsResponse{
+		EventID:           event.ID.String(),
+		Views:             analytics.Views,
+		UniqueViewers:     analytics.UniqueViewers,
+		ParticipantsCount: analytics.ParticipantsCount,
+		Joins:             analytics.Joins,
+		Leaves:            analytics.Leaves,
+		ConversionRate:    analytics.ConversionRate,
+		CancellationRate:  analytics.CancellationRate,
+		Daily:             daily,
+		Reviews: dto.ReviewSummaryResponse{
+			EventID:           event.ID.String(),
+			AverageRating:     analytics.Reviews.AverageRating,
+			TotalReviews:      analytics.Reviews.TotalReviews,
+			Distribution:      distribution,
+			ParticipantsCount: analytics.Reviews.ParticipantsCount,
+			ResponseRate:      analytics.Reviews.ResponseRate,
+		},
+	}
+}
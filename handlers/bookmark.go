@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BookmarkEvent godoc
+// @Summary Добавить событие в избранное
+// @Description Добавляет событие в избранное. Повторный вызов обновляет настройку напоминания о заполнении мест
+// @Tags События
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param request body dto.BookmarkRequest false "Настройки закладки"
+// @Success 200 {object} dto.BookmarkResponse "Закладка обновлена"
+// @Success 201 {object} dto.BookmarkResponse "Событие добавлено в избранное"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/bookmark [post]
+func (h *EventHandler) BookmarkEvent(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	var req dto.BookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Select(eventAccessColumns).Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	if event.Status == models.EventStatusRejected && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	status := http.StatusOK
+	var bookmark models.EventBookmark
+	err := database.DB.Where("user_id = ? AND event_id = ?", userID, event.ID).First(&bookmark).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		bookmark = models.EventBookmark{
+			UserID:  userID.(uuid.UUID),
+			EventID: event.ID,
+		}
+		if req.NotifyNearlyFull != nil {
+			bookmark.NotifyNearlyFull = *req.NotifyNearlyFull
+		}
+		if err := database.DB.Create(&bookmark).Error; err != nil {
+			h.logger.Error("Ошибка добавления события в избранное", zap.String("eventID", eventID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении в избранное"})
+			return
+		}
+		status = http.StatusCreated
+	case err != nil:
+		h.logger.Error("Ошибка поиска закладки", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении в избранное"})
+		return
+	case req.NotifyNearlyFull != nil && *req.NotifyNearlyFull != bookmark.NotifyNearlyFull:
+		// При повторном включении напоминание снова может прийти
+		updates := map[string]interface{}{
+			"notify_nearly_full":      *req.NotifyNearlyFull,
+			"nearly_full_notified_at": nil,
+		}
+		if err := database.DB.Model(&bookmark).Updates(updates).Error; err != nil {
+			h.logger.Error("Ошибка обновления закладки", zap.String("eventID", eventID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении закладки"})
+			return
+		}
+		bookmark.NotifyNearlyFull = *req.NotifyNearlyFull
+	}
+
+	c.JSON(status, dto.BookmarkResponse{
+		EventID:          bookmark.EventID.String(),
+		NotifyNearlyFull: bookmark.NotifyNearlyFull,
+		BookmarkedAt:     bookmark.CreatedAt,
+	})
+}
+
+// RemoveBookmark godoc
+// @Summary Удалить событие из избранного
+// @Tags События
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Success 200 {object} map[string]string "Событие удалено из избранного"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 404 {object} map[string]string "Событие не в избранном"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/bookmark [delete]
+func (h *EventHandler) RemoveBookmark(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	result := database.DB.Where("user_id = ? AND event_id = ?", userID, eventID).Delete(&models.EventBookmark{})
+	if result.Error != nil {
+		h.logger.Error("Ошибка удаления закладки", zap.String("eventID", eventID), zap.Error(result.Error))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении из избранного"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не в избранном"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Событие удалено из избранного"})
+}
+
+// GetBookmarks godoc
+// @Summary Избранные события
+// @Description События из избранного текущего пользователя, последние добавленные первыми
+// @Tags Пользователи
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Номер страницы (по умолчанию: 1)"
+// @Param limit query int false "Количество на странице (по умолчанию: 20, максимум: 100)"
+// @Success 200 {object} dto.PaginationResponse "Избранные события"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /user/bookmarks [get]
+func (h *EventHandler) GetBookmarks(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := database.DB.Model(&models.EventBookmark{}).
+		Joins("JOIN events ON events.id = event_bookmarks.event_id AND events.deleted_at IS NULL").
+		Where("event_bookmarks.user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.logger.Error("Ошибка подсчета избранных событий", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении избранного"})
+		return
+	}
+
+	var bookmarks []models.EventBookmark
+	if err := query.
+		Preload("Event.Organizer").Preload("Event.Participants").Preload("Event.Categories").
+		Order("event_bookmarks.created_at DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&bookmarks).Error; err != nil {
+		h.logger.Error("Ошибка получения избранных событий", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении избранного"})
+		return
+	}
+
+	bookmarked := true
+	result := make([]dto.BookmarkedEventResponse, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		result[i] = dto.BookmarkedEventResponse{
+			Event:            h.eventToResponse(bookmark.Event),
+			NotifyNearlyFull: bookmark.NotifyNearlyFull,
+			BookmarkedAt:     bookmark.CreatedAt,
+		}
+		result[i].Event.IsBookmarked = &bookmarked
+	}
+
+	c.JSON(http.StatusOK, dto.PaginationResponse{
+		Data: result,
+		Pagination: dto.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	})
+}
+
+// bookmarkFlags возвращает признак "в избранном" для событий eventIDs. Для анонимного запроса
+// признак не заполняется (nil), чтобы поле isBookmarked не попадало в ответ
+func bookmarkFlags(c *gin.Context, eventIDs []uuid.UUID) func(uuid.UUID) *bool {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return func(uuid.UUID) *bool { return nil }
+	}
+
+	bookmarked := make(map[uuid.UUID]bool)
+	if len(eventIDs) > 0 {
+		var ids []uuid.UUID
+		if err := database.DB.Model(&models.EventBookmark{}).
+			Where("user_id = ? AND event_id IN ?", userID, eventIDs).
+			Pluck("event_id", &ids).Error; err != nil {
+			utils.GetLogger().Warn("Ошибка получения избранного", zap.Error(err))
+			return func(uuid.UUID) *bool { return nil }
+		}
+		for _, id := range ids {
+			bookmarked[id] = true
+		}
+	}
+
+	return func(eventID uuid.UUID) *bool {
+		value := bookmarked[eventID]
+		return &value
+	}
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/services"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// cityFilterAll в параметре cityID отключает фильтр по городу по умолчанию
+const cityFilterAll = "all"
+
+type CityHandler struct {
+	logger *zap.Logger
+}
+
+func NewCityHandler() *CityHandler {
+	return &CityHandler{
+		logger: utils.GetLogger(),
+	}
+}
+
+// GetCities godoc
+// @Summary Получить список городов
+// @Description Все города платформы, отсортированные по названию
+// @Tags Города
+// @Produce json
+// @Success 200 {array} dto.CityResponse "Список городов"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /cities [get]
+func (h *CityHandler) GetCities(c *gin.Context) {
+	var cities []models.City
+	if err := database.DB.Order("name ASC").Find(&cities).Error; err != nil {
+		h.logger.Error("Ошибка получения городов", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении городов"})
+		return
+	}
+
+	result := make([]dto.CityResponse, len(cities))
+	for i, city := range cities {
+		result[i] = cityToResponse(city)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateCity godoc
+// @Summary Создать город
+// @Description Создание города (только для администраторов). События и площадки без города, попадающие в радиус, привязываются к нему
+// @Tags Города
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateCityRequest true "Данные города"
+// @Success 201 {object} dto.CityResponse "Город создан"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 409 {object} map[string]string "Город с таким названием уже существует"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /admin/cities [post]
+func (h *CityHandler) CreateCity(c *gin.Context) {
+	var req dto.CreateCityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при создании города", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	city := models.City{
+		Name:      strings.TrimSpace(req.Name),
+		Timezone:  req.Timezone,
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		RadiusKm:  utils.DefaultCityRadiusKm,
+	}
+	if req.RadiusKm != nil {
+		city.RadiusKm = *req.RadiusKm
+	}
+	if errMsg := validateCity(&city); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	if h.cityNameTaken(city.Name, uuid.Nil) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Город с таким названием уже существует"})
+		return
+	}
+
+	if err := database.DB.Create(&city).Error; err != nil {
+		h.logger.Error("Ошибка создания города в БД", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании города"})
+		return
+	}
+
+	go services.AssignCityToExisting(&city)
+
+	c.JSON(http.StatusCreated, cityToResponse(city))
+}
+
+// UpdateCity godoc
+// @Summary Обновить город
+// @Description Изменение города (только для администраторов). Уже привязанные события и площадки не перепривязываются
+// @Tags Города
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID города"
+// @Param request body dto.UpdateCityRequest true "Изменяемые поля"
+// @Success 200 {object} dto.CityResponse "Город обновлен"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 404 {object} map[string]string "Город не найден"
+// @Failure 409 {object} map[string]string "Город с таким названием уже существует"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /admin/cities/{id} [put]
+func (h *CityHandler) UpdateCity(c *gin.Context) {
+	cityID := c.Param("id")
+	if !utils.ValidateUUID(cityID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID города"})
+		return
+	}
+
+	var req dto.UpdateCityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при обновлении города", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	var city models.City
+	if err := database.DB.Where("id = ?", cityID).First(&city).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Город не найден"})
+		return
+	}
+
+	if req.Name != "" {
+		city.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Timezone != "" {
+		city.Timezone = req.Timezone
+	}
+	if req.Latitude != nil {
+		city.Latitude = *req.Latitude
+	}
+	if req.Longitude != nil {
+		city.Longitude = *req.Longitude
+	}
+	if req.RadiusKm != nil {
+		city.RadiusKm = *req.RadiusKm
+	}
+	if errMsg := validateCity(&city); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	if h.cityNameTaken(city.Name, city.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Город с таким названием уже существует"})
+		return
+	}
+
+	if err := database.DB.Save(&city).Error; err != nil {
+		h.logger.Error("Ошибка обновления города", zap.String("cityID", cityID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении города"})
+		return
+	}
+
+	if req.Latitude != nil || req.Longitude != nil || req.RadiusKm != nil {
+		go services.AssignCityToExisting(&city)
+	}
+
+	c.JSON(http.StatusOK, cityToResponse(city))
+}
+
+// DeleteCity godoc
+// @Summary Удалить город
+// @Description Удаление города (только для администраторов). События, площадки, сообщества и пользователи остаются без города
+// @Tags Города
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID города"
+// @Success 200 {object} map[string]string "Город удален"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 404 {object} map[string]string "Город не найден"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /admin/cities/{id} [delete]
+func (h *CityHandler) DeleteCity(c *gin.Context) {
+	cityID := c.Param("id")
+	if !utils.ValidateUUID(cityID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID города"})
+		return
+	}
+
+	var city models.City
+	if err := database.DB.Where("id = ?", cityID).First(&city).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Город не найден"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.Event{}, &models.Venue{}, &models.MicroCommunity{}, &models.User{}} {
+			if err := tx.Model(model).Unscoped().Where("city_id = ?", city.ID).Update("city_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&city).Error
+	})
+	if err != nil {
+		h.logger.Error("Ошибка удаления города", zap.String("cityID", cityID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении города"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Город удален"})
+}
+
+func (h *CityHandler) cityNameTaken(name string, exceptID uuid.UUID) bool {
+	var count int64
+	database.DB.Model(&models.City{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).Count(&count)
+	return count > 0
+}
+
+// validateCity проверяет поля города и возвращает текст ошибки для клиента
+func validateCity(city *models.City) string {
+	if !utils.ValidateStringLength(city.Name, 1, utils.MaxCityNameLength) {
+		return fmt.Sprintf("Название города должно быть от 1 до %d символов", utils.MaxCityNameLength)
+	}
+	if !utils.ValidateTimezone(city.Timezone) {
+		return "Неверный часовой пояс. Укажите название из базы IANA, например Europe/Moscow"
+	}
+	if city.Latitude < -90 || city.Latitude > 90 {
+		return "Широта должна быть от -90 до 90"
+	}
+	if city.Longitude < -180 || city.Longitude > 180 {
+		return "Долгота должна быть от -180 до 180"
+	}
+	if city.RadiusKm <= 0 || city.RadiusKm > utils.MaxCityRadiusKm {
+		return fmt.Sprintf("Радиус города должен быть от 0 до %d км", utils.MaxCityRadiusKm)
+	}
+	return ""
+}
+
+// parseCityID разбирает необязательный ID города из запроса и проверяет, что город существует.
+// Пустая строка означает, что город не указан
+func parseCityID(c *gin.Context, value string) (*uuid.UUID, bool) {
+	if value == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID города"})
+		return nil, false
+	}
+	return checkCityExists(c, &id)
+}
+
+// checkCityExists проверяет, что город с указанным ID существует
+func checkCityExists(c *gin.Context, cityID *uuid.UUID) (*uuid.UUID, bool) {
+	if cityID == nil {
+		return nil, true
+	}
+	var count int64
+	if err := database.DB.Model(&models.City{}).Where("id = ?", *cityID).Count(&count).Error; err != nil || count == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Город не найден"})
+		return nil, false
+	}
+	return cityID, true
+}
+
+// cityFilter определяет город для фильтрации списков по параметру cityID. Без параметра используется
+// город из профиля авторизованного пользователя (если useDefault), значение all отключает фильтр
+func cityFilter(c *gin.Context, userID interface{}, useDefault bool) (*uuid.UUID, bool) {
+	value := c.Query("cityID")
+	switch {
+	case value == cityFilterAll:
+		return nil, true
+	case value != "":
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID города"})
+			return nil, false
+		}
+		return &id, true
+	case useDefault && userID != nil:
+		var user models.User
+		if err := database.DB.Select("city_id").Where("id = ?", userID.(uuid.UUID)).First(&user).Error; err != nil {
+			return nil, true
+		}
+		return user.CityID, true
+	}
+	return nil, true
+}
+
+// filterLocation возвращает часовой пояс для фильтров по дате: из параметра timezone, выбранного города или платформы
+func filterLocation(c *gin.Context, cityID *uuid.UUID) (*time.Location, bool) {
+	if timezone := c.Query("timezone"); timezone != "" {
+		if !utils.ValidateTimezone(timezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный часовой пояс. Укажите название из базы IANA, например Europe/Moscow"})
+			return nil, false
+		}
+		return utils.EventLocation(timezone), true
+	}
+	if cityID != nil {
+		var city models.City
+		if err := database.DB.Select("timezone").Where("id = ?", *cityID).First(&city).Error; err == nil {
+			return utils.EventLocation(city.Timezone), true
+		}
+	}
+	return utils.DefaultLocation(), true
+}
+
+func cityInfo(city *models.City) *dto.CityInfo {
+	if city == nil {
+		return nil
+	}
+	return &dto.CityInfo{
+		ID:       city.ID.String(),
+		Name:     city.Name,
+		Timezone: city.Timezone,
+	}
+}
+
+func cityToResponse(city models.City) dto.CityResponse {
+	return dto.CityResponse{
+		ID:        city.ID.String(),
+		Name:      city.Name,
+		Timezone:  city.Timezone,
+		Latitude:  city.Latitude,
+		Longitude: city.Longitude,
+		RadiusKm:  city.RadiusKm,
+	}
+}
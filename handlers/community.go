@@ -66,6 +66,11 @@ func (h *CommunityHandler) CreateCommunity(c *gin.Context) {
 		}
 	}
 
+	cityID, ok := parseCityID(c, req.CityID)
+	if !ok {
+		return
+	}
+
 	community := models.MicroCommunity{
 		Name:         strings.TrimSpace(req.Name),
 		Description:  req.Description,
@@ -73,6 +78,7 @@ func (h *CommunityHandler) CreateCommunity(c *gin.Context) {
 		AutoNotify:   req.AutoNotify,
 		MembersCount: 1,
 		Interests:    interests,
+		CityID:       cityID,
 	}
 
 	if err := database.DB.Create(&community).Error; err != nil {
@@ -80,6 +86,12 @@ func (h *CommunityHandler) CreateCommunity(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании сообщества"})
 		return
 	}
+	if community.CityID != nil {
+		var city models.City
+		if err := database.DB.Where("id = ?", *community.CityID).First(&city).Error; err == nil {
+			community.City = &city
+		}
+	}
 
 	member := models.CommunityMember{
 		UserID:      userID.(uuid.UUID),
@@ -93,6 +105,17 @@ func (h *CommunityHandler) CreateCommunity(c *gin.Context) {
 	c.JSON(http.StatusCreated, h.communityToResponse(community))
 }
 
+// communityKeysetSort - порядок каталога сообществ: сначала крупные, при равенстве - новые
+var communityKeysetSort = keysetSort{
+	name: "membersCount:desc",
+	columns: []keysetColumn{
+		{expr: "micro_communities.members_count", kind: keysetInt},
+		{expr: "micro_communities.created_at", kind: keysetTime},
+	},
+	idExpr: "micro_communities.id",
+	desc:   true,
+}
+
 func (h *CommunityHandler) GetCommunities(c *gin.Context) {
 	search := c.Query("search")
 	category := c.Query("category")
@@ -112,7 +135,12 @@ func (h *CommunityHandler) GetCommunities(c *gin.Context) {
 
 	offset := (pageInt - 1) * limitInt
 
-	query := database.DB.Model(&models.MicroCommunity{}).Preload("Admin").Preload("Interests")
+	cursor, ok := parseCursorPage(c, limitInt)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.MicroCommunity{}).Preload("Admin").Preload("Interests").Preload("City")
 
 	if search != "" {
 		if !utils.ValidateStringLength(search, 1, 100) {
@@ -129,6 +157,14 @@ func (h *CommunityHandler) GetCommunities(c *gin.Context) {
 			Where("interests.category = ?", category)
 	}
 
+	cityID, ok := cityFilter(c, nil, false)
+	if !ok {
+		return
+	}
+	if cityID != nil {
+		query = query.Where("micro_communities.city_id = ?", *cityID)
+	}
+
 	if interestID != "" {
 		if !utils.ValidateUUID(interestID) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID интереса"})
@@ -139,20 +175,50 @@ func (h *CommunityHandler) GetCommunities(c *gin.Context) {
 	}
 
 	var total int64
-	query.Count(&total)
+	if cursor == nil || cursor.withTotal {
+		query.Count(&total)
+	}
+
+	if cursor != nil {
+		var err error
+		if query, err = cursor.apply(query, communityKeysetSort); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный курсор"})
+			return
+		}
+	} else {
+		query = query.Offset(offset).Limit(limitInt).Order("members_count DESC, created_at DESC")
+	}
 
 	var communities []models.MicroCommunity
-	if err := query.Offset(offset).Limit(limitInt).Order("members_count DESC, created_at DESC").Find(&communities).Error; err != nil {
+	if err := query.Find(&communities).Error; err != nil {
 		h.logger.Error("Ошибка получения сообществ", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сообществ"})
 		return
 	}
 
+	var cursorInfo dto.CursorPagination
+	if cursor != nil {
+		communities, cursorInfo = cursorPagination(cursor, communityKeysetSort, communities, func(comm models.MicroCommunity) ([]interface{}, uuid.UUID) {
+			return []interface{}{comm.MembersCount, comm.CreatedAt}, comm.ID
+		})
+	}
+
 	result := make([]dto.CommunityResponse, len(communities))
 	for i, comm := range communities {
 		result[i] = h.communityToResponse(comm)
 	}
 
+	if cursor != nil {
+		if cursor.withTotal {
+			cursorInfo.Total = &total
+		}
+		c.JSON(http.StatusOK, dto.CursorPaginationResponse{
+			Data:   result,
+			Cursor: cursorInfo,
+		})
+		return
+	}
+
 	totalPages := int((total + int64(limitInt) - 1) / int64(limitInt))
 	c.JSON(http.StatusOK, dto.PaginationResponse{
 		Data: result,
@@ -173,7 +239,7 @@ func (h *CommunityHandler) GetCommunity(c *gin.Context) {
 	}
 
 	var community models.MicroCommunity
-	if err := database.DB.Preload("Admin").Preload("Interests").
+	if err := database.DB.Preload("Admin").Preload("Interests").Preload("City").
 		Where("id = ?", communityID).First(&community).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Сообщество не найдено"})
 		return
@@ -271,7 +337,7 @@ func (h *CommunityHandler) GetMyCommunities(c *gin.Context) {
 	}
 
 	var memberships []models.CommunityMember
-	if err := database.DB.Preload("Community").Preload("Community.Admin").Preload("Community.Interests").
+	if err := database.DB.Preload("Community").Preload("Community.Admin").Preload("Community.Interests").Preload("Community.City").
 		Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
 		h.logger.Error("Ошибка получения сообществ пользователя", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сообществ"})
@@ -338,6 +404,7 @@ func (h *CommunityHandler) communityToResponse(community models.MicroCommunity)
 		},
 		AutoNotify:   community.AutoNotify,
 		MembersCount: community.MembersCount,
+		City:         cityInfo(community.City),
 		CreatedAt:    community.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 }
@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -22,17 +25,34 @@ import (
 	"github.com/google/uuid"
 	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type EventHandler struct {
-	emailService *services.EmailService
-	logger       *zap.Logger
+	emailService          *services.EmailService
+	telegramService       *services.TelegramService
+	matchingService       *services.MatchingService
+	webhookService        *services.WebhookService
+	recommendationService *services.RecommendationService
+	trendingService       *services.TrendingService
+	analyticsService      *services.AnalyticsService
+	eventGeocodingService *services.EventGeocodingService
+	orderService          *services.OrderService
+	logger                *zap.Logger
 }
 
 func NewEventHandler() *EventHandler {
 	return &EventHandler{
-		emailService: services.NewEmailService(),
-		logger:       utils.GetLogger(),
+		emailService:          services.NewEmailService(),
+		telegramService:       services.NewTelegramService(),
+		matchingService:       services.NewMatchingService(),
+		webhookService:        services.NewWebhookService(),
+		recommendationService: services.NewRecommendationService(),
+		trendingService:       services.NewTrendingService(),
+		analyticsService:      services.NewAnalyticsService(),
+		eventGeocodingService: services.NewEventGeocodingService(),
+		orderService:          services.NewOrderService(),
+		logger:                utils.GetLogger(),
 	}
 }
 
@@ -42,18 +62,30 @@ func NewEventHandler() *EventHandler {
 // @Tags События
 // @Accept json
 // @Produce json
-// @Param tab query string false "Тип фильтрации: active, my, past"
+// @Param tab query string false "Тип фильтрации: active, my, past, invited (события, на которые пригласили)"
 // @Param page query int false "Номер страницы (по умолчанию: 1)"
 // @Param limit query int false "Количество элементов на странице (по умолчанию: 20, максимум: 100)"
-// @Param search query string false "Поиск по названию и описанию (1-200 символов)"
-// @Param status query string false "Фильтр по статусу: Активное, Прошедшее, Отклоненное (для обычных пользователей доступны только Активное и Прошедшее)"
+// @Param after query string false "Курсор следующей страницы (пустое значение - первая страница в режиме курсоров)"
+// @Param before query string false "Курсор предыдущей страницы"
+// @Param withTotal query bool false "Посчитать общее количество в режиме курсоров"
+// @Param search query string false "Полнотекстовый поиск по названию, тегам и описанию (1-200 символов, синтаксис websearch)"
+// @Param status query string false "Фильтр по статусу: Активное, Прошедшее, Отклоненное, Отмененное (для обычных пользователей доступны только Активное и Прошедшее)"
 // @Param categoryIDs query []string false "Фильтр по категориям (массив UUID)"
 // @Param tags query []string false "Фильтр по тегам (массив строк)"
-// @Param dateFrom query string false "Фильтр по дате начала (YYYY-MM-DD)"
-// @Param dateTo query string false "Фильтр по дате окончания (YYYY-MM-DD)"
-// @Param sortBy query string false "Сортировка: startDate, createdAt, participantsCount (по умолчанию: startDate)"
+// @Param dateFrom query string false "Фильтр по дате начала (YYYY-MM-DD или RFC3339)"
+// @Param dateTo query string false "Фильтр по дате окончания (YYYY-MM-DD или RFC3339)"
+// @Param timezone query string false "Часовой пояс IANA для dateFrom, dateTo и period (по умолчанию: пояс города или платформы)"
+// @Param lat query number false "Широта точки для геопоиска (вместе с lon)"
+// @Param lon query number false "Долгота точки для геопоиска (вместе с lat)"
+// @Param radiusKm query number false "Радиус геопоиска в км (по умолчанию: 10, максимум: 500)"
+// @Param sortBy query string false "Сортировка: startDate, createdAt, participantsCount, trending, relevance, distance (по умолчанию: startDate, при поиске - relevance, при геопоиске - distance)"
 // @Param sortOrder query string false "Порядок сортировки: ASC, DESC (по умолчанию: ASC)"
-// @Success 200 {object} dto.PaginationResponse{data=[]dto.EventResponse} "Список событий с пагинацией"
+// @Param period query string false "Быстрый фильтр по дате начала: week, weekend, month"
+// @Param price query string false "Фильтр по оплате: free, paid"
+// @Param hasFreeSeats query bool false "Только события со свободными местами"
+// @Param cityID query string false "UUID города; по умолчанию - город из профиля пользователя, all - все города"
+// @Param facets query bool false "Вернуть количество событий по фильтрам (facets)"
+// @Success 200 {object} dto.EventListResponse "Список событий с пагинацией и фасетами"
 // @Failure 400 {object} map[string]string "Ошибка валидации параметров"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /events [get]
@@ -86,35 +118,59 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 
 	offset := (pageInt - 1) * limitInt
 
-	query := database.DB.Model(&models.Event{}).Preload("Organizer").Preload("Participants").Preload("Categories")
+	cursor, ok := parseCursorPage(c, limitInt)
+	if !ok {
+		return
+	}
+
+	// Фильтры собираются в scopes: при подсчете фасета собственный фильтр его измерения не применяется
+	var scopes []eventScope
 
 	switch tab {
 	case "active":
-		query = query.Where("status = ?", models.EventStatusActive)
+		scopes = append(scopes, whereScope("", "status = ?", models.EventStatusActive))
 	case "my":
 		if userID != nil {
 			userUUID := userID.(uuid.UUID)
-			query = query.Where("(organizer_id = ? OR id IN (SELECT event_id FROM event_participants WHERE user_id = ?))", userUUID, userUUID).
-				Where("status IN ?", []models.EventStatus{models.EventStatusActive, models.EventStatusPast})
+			scopes = append(scopes,
+				whereScope("", "(organizer_id = ? OR id IN (SELECT event_id FROM event_participants WHERE user_id = ?))", userUUID, userUUID),
+				whereScope("", "status IN ?", []models.EventStatus{models.EventStatusActive, models.EventStatusPast, models.EventStatusCancelled}),
+			)
 		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Для просмотра своих событий требуется авторизация"})
 			return
 		}
+	case "invited":
+		if userID != nil {
+			userUUID := userID.(uuid.UUID)
+			scopes = append(scopes,
+				whereScope("", "id IN (SELECT event_id FROM event_invitations WHERE user_id = ? OR email = (SELECT LOWER(email) FROM users WHERE id = ?))", userUUID, userUUID),
+				whereScope("", "status IN ?", []models.EventStatus{models.EventStatusActive, models.EventStatusPast}),
+			)
+		} else {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Для просмотра приглашений требуется авторизация"})
+			return
+		}
 	case "past":
-		query = query.Where("status = ?", models.EventStatusPast)
+		scopes = append(scopes, whereScope("", "status = ?", models.EventStatusPast))
 	default:
-		query = query.Where("status != ?", models.EventStatusRejected)
+		scopes = append(scopes, whereScope("", "status NOT IN ?", []models.EventStatus{models.EventStatusRejected, models.EventStatusCancelled}))
 	}
 
 	if statusFilter != "" {
 		if models.IsValidEventStatus(models.EventStatus(statusFilter)) {
-			query = query.Where("status = ?", models.EventStatus(statusFilter))
+			scopes = append(scopes, whereScope("", "status = ?", models.EventStatus(statusFilter)))
 		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный статус. Допустимые значения: Активное, Прошедшее, Отклоненное"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный статус. Допустимые значения: Активное, Прошедшее, Отклоненное, Отмененное"})
 			return
 		}
-	} else if tab != "active" && tab != "past" && tab != "my" {
-		query = query.Where("status != ?", models.EventStatusRejected)
+	} else if tab != "active" && tab != "past" && tab != "my" && tab != "invited" {
+		scopes = append(scopes, whereScope("", "status != ?", models.EventStatusRejected))
+	}
+
+	// Непубличные события видны в общем списке только в своих и в приглашениях
+	if tab != "my" && tab != "invited" {
+		scopes = append(scopes, whereScope("", "visibility = ?", models.EventVisibilityPublic))
 	}
 
 	if search != "" {
@@ -122,8 +178,7 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Поисковый запрос должен быть от 1 до 200 символов"})
 			return
 		}
-		query = query.Where("title ILIKE ? OR short_description ILIKE ? OR full_description ILIKE ?",
-			"%"+search+"%", "%"+search+"%", "%"+search+"%")
+		scopes = append(scopes, whereScope("", "search_vector @@ websearch_to_tsquery(?::regconfig, ?)", models.EventSearchConfig, search))
 	}
 
 	if len(categoryIDs) > 0 {
@@ -136,42 +191,117 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 			validCategoryIDs = append(validCategoryIDs, uuid.MustParse(catIDStr))
 		}
 		if len(validCategoryIDs) > 0 {
-			query = query.Where("id IN (SELECT event_id FROM event_categories WHERE category_id IN ?)", validCategoryIDs)
+			scopes = append(scopes, whereScope(facetCategories, "id IN (SELECT event_id FROM event_categories WHERE category_id IN ?)", validCategoryIDs))
 		}
 	}
 
 	if len(tags) > 0 {
 		for _, tag := range tags {
 			if tag != "" {
-				query = query.Where("? = ANY(tags)", tag)
+				scopes = append(scopes, whereScope(facetTags, "? = ANY(tags)", tag))
+			}
+		}
+	}
+
+	switch c.Query("price") {
+	case "":
+	case "free":
+		scopes = append(scopes, whereScope(facetPrice, eventFreeCondition))
+	case "paid":
+		scopes = append(scopes, whereScope(facetPrice, "NOT ("+eventFreeCondition+")"))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверное значение price. Допустимые значения: free, paid"})
+		return
+	}
+
+	if c.Query("hasFreeSeats") == "true" {
+		scopes = append(scopes, whereScope(facetSeats, eventHasFreeSeatsCondition))
+	}
+
+	var lat, lon, radiusKm float64
+	geo := c.Query("lat") != "" || c.Query("lon") != ""
+	if geo {
+		var latErr, lonErr error
+		lat, latErr = strconv.ParseFloat(c.Query("lat"), 64)
+		lon, lonErr = strconv.ParseFloat(c.Query("lon"), 64)
+		if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Для геопоиска укажите lat (от -90 до 90) и lon (от -180 до 180)"})
+			return
+		}
+
+		radiusKm = utils.DefaultGeoRadiusKm
+		if radiusStr := c.Query("radiusKm"); radiusStr != "" {
+			r, err := strconv.ParseFloat(radiusStr, 64)
+			if err != nil || r <= 0 || r > utils.MaxGeoRadiusKm {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Радиус должен быть от 0 до 500 км"})
+				return
 			}
+			radiusKm = r
 		}
+
+		// earth_box отбирает кандидатов по GiST-индексу, earth_distance отсекает углы куба
+		scopes = append(scopes,
+			whereScope("", "latitude IS NOT NULL AND longitude IS NOT NULL"),
+			whereScope("", "earth_box(ll_to_earth(?, ?), ?) @> "+models.EventLocationExpr, lat, lon, radiusKm*1000),
+			whereScope("", "earth_distance(ll_to_earth(?, ?), "+models.EventLocationExpr+") <= ?", lat, lon, radiusKm*1000),
+		)
+	} else if sortBy == "distance" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Для сортировки по расстоянию укажите lat и lon"})
+		return
+	}
+
+	// Город из профиля не применяется к своим событиям и геопоиску: там важнее явный выбор пользователя
+	cityID, ok := cityFilter(c, userID, tab != "my" && tab != "invited" && !geo)
+	if !ok {
+		return
+	}
+	if cityID != nil {
+		scopes = append(scopes, whereScope("", "city_id = ?", *cityID))
+	}
+
+	// Даты без времени понимаются как сутки в часовом поясе из параметра timezone, выбранного города или платформы
+	loc, ok := filterLocation(c, cityID)
+	if !ok {
+		return
 	}
 
 	if dateFrom != "" {
-		if dateFromTime, err := time.Parse("2006-01-02", dateFrom); err == nil {
-			query = query.Where("start_date >= ?", dateFromTime)
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат dateFrom. Используйте YYYY-MM-DD"})
+		dateFromTime, ok := parseDateFilter(dateFrom, loc, false)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат dateFrom. Используйте YYYY-MM-DD или RFC3339"})
 			return
 		}
+		scopes = append(scopes, whereScope(facetDates, "start_date >= ?", dateFromTime))
 	}
 
 	if dateTo != "" {
-		if dateToTime, err := time.Parse("2006-01-02", dateTo); err == nil {
-			dateToTime = dateToTime.Add(24 * time.Hour).Add(-1 * time.Second)
-			query = query.Where("end_date <= ?", dateToTime)
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат dateTo. Используйте YYYY-MM-DD"})
+		dateToTime, ok := parseDateFilter(dateTo, loc, true)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат dateTo. Используйте YYYY-MM-DD или RFC3339"})
 			return
 		}
+		scopes = append(scopes, whereScope(facetDates, "end_date <= ?", dateToTime))
 	}
 
+	if period := c.Query("period"); period != "" {
+		from, to, ok := eventPeriodRange(period, time.Now().In(loc))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный период. Допустимые значения: week, weekend, month"})
+			return
+		}
+		scopes = append(scopes, whereScope(facetDates, "start_date BETWEEN ? AND ?", from, to))
+	}
+
+	query := applyEventScopes(database.DB.Model(&models.Event{}).Preload("Organizer").Preload("Participants").Preload("Categories"), scopes, "")
+
+	// В режиме курсоров COUNT(*) выполняется только по запросу клиента
 	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		h.logger.Error("Ошибка при подсчете событий", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчете событий"})
-		return
+	if cursor == nil || cursor.withTotal {
+		if err := query.Count(&total).Error; err != nil {
+			h.logger.Error("Ошибка при подсчете событий", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчете событий"})
+			return
+		}
 	}
 
 	h.logger.Info("Запрос событий",
@@ -184,33 +314,94 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 	)
 
 	orderBy := "start_date ASC"
-	if sortBy == "createdAt" {
+	// keyset - сортировка для курсорной пагинации; для релевантности, расстояния и трендов курсоры не поддерживаются
+	var keyset *keysetSort
+	if geo && (sortBy == "distance" || (c.Query("sortBy") == "" && search == "")) {
+		if sortOrder == "DESC" {
+			orderBy = "distance_km DESC"
+		} else {
+			orderBy = "distance_km ASC"
+		}
+	} else if search != "" && (c.Query("sortBy") == "" || sortBy == "relevance") {
+		orderBy = "search_rank DESC, start_date ASC"
+	} else if sortBy == "trending" {
+		// Рейтинг пересчитывается по расписанию, события без недавней активности идут после трендовых по дате начала
+		orderBy = "COALESCE((SELECT score FROM event_trending_scores WHERE event_trending_scores.event_id = events.id), 0) DESC, start_date ASC"
+	} else if sortBy == "createdAt" {
 		if sortOrder == "DESC" {
 			orderBy = "created_at DESC"
 		} else {
 			orderBy = "created_at ASC"
 		}
+		keyset = eventKeysetSort("createdAt", "events.created_at", keysetTime, sortOrder)
 	} else if sortBy == "participantsCount" {
 		if sortOrder == "DESC" {
 			orderBy = "(SELECT COUNT(*) FROM event_participants WHERE event_id = events.id) DESC"
 		} else {
 			orderBy = "(SELECT COUNT(*) FROM event_participants WHERE event_id = events.id) ASC"
 		}
+		keyset = eventKeysetSort("participantsCount", "(SELECT COUNT(*) FROM event_participants WHERE event_id = events.id)", keysetInt, sortOrder)
 	} else {
 		if sortOrder == "DESC" {
 			orderBy = "start_date DESC"
 		} else {
 			orderBy = "start_date ASC"
 		}
+		keyset = eventKeysetSort("startDate", "events.start_date", keysetTime, sortOrder)
+	}
+	if cursor != nil && keyset == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Пагинация по курсору недоступна для сортировки по релевантности, расстоянию и популярности"})
+		return
+	}
+
+	selectColumns := []string{"events.*"}
+	var selectArgs []interface{}
+	if search != "" {
+		selectColumns = append(selectColumns,
+			"ts_rank(search_vector, websearch_to_tsquery(@config::regconfig, @search)) AS search_rank",
+			"ts_headline(@config::regconfig, "+sqlEscapeHTML("title")+", websearch_to_tsquery(@config::regconfig, @search), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight",
+			"ts_headline(@config::regconfig, "+sqlEscapeHTML("concat_ws(' ', short_description, full_description)")+", websearch_to_tsquery(@config::regconfig, @search), 'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2') AS search_snippet",
+		)
+		selectArgs = append(selectArgs, sql.Named("config", models.EventSearchConfig), sql.Named("search", search))
+	}
+	if geo {
+		selectColumns = append(selectColumns, "earth_distance(ll_to_earth(@lat, @lon), "+models.EventLocationExpr+") / 1000 AS distance_km")
+		selectArgs = append(selectArgs, sql.Named("lat", lat), sql.Named("lon", lon))
+	}
+	if len(selectArgs) > 0 {
+		query = query.Select(strings.Join(selectColumns, ", "), selectArgs...)
+	}
+
+	if cursor != nil {
+		var err error
+		if query, err = cursor.apply(query, *keyset); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Курсор не соответствует выбранной сортировке"})
+			return
+		}
+	} else {
+		query = query.Offset(offset).Limit(limitInt).Order(orderBy)
 	}
 
 	var events []models.Event
-	if err := query.Offset(offset).Limit(limitInt).Order(orderBy).Find(&events).Error; err != nil {
+	if err := query.Find(&events).Error; err != nil {
 		h.logger.Error("Ошибка при получении событий", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении событий"})
 		return
 	}
 
+	var cursorInfo dto.CursorPagination
+	if cursor != nil {
+		events, cursorInfo = cursorPagination(cursor, *keyset, events, func(event models.Event) ([]interface{}, uuid.UUID) {
+			switch sortBy {
+			case "createdAt":
+				return []interface{}{event.CreatedAt}, event.ID
+			case "participantsCount":
+				return []interface{}{event.GetParticipantsCount()}, event.ID
+			}
+			return []interface{}{event.StartDate}, event.ID
+		})
+	}
+
 	h.logger.Info("Найдено событий",
 		zap.Int("count", len(events)),
 		zap.String("tab", tab),
@@ -226,6 +417,11 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 	}
 
 	result := make([]dto.EventResponse, 0, len(events))
+	eventIDs := make([]uuid.UUID, len(events))
+	for i, event := range events {
+		eventIDs[i] = event.ID
+	}
+	isBookmarked := bookmarkFlags(c, eventIDs)
 	for idx, event := range events {
 		func() {
 			defer func() {
@@ -247,48 +443,16 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 				zap.Int("participantsCount", len(event.Participants)),
 			)
 			
-			categories := make([]dto.CategoryInfo, len(event.Categories))
-			for j, cat := range event.Categories {
-				categories[j] = dto.CategoryInfo{
-					ID:   cat.ID.String(),
-					Name: cat.Name,
-				}
-			}
-
-			organizerInfo := dto.UserInfo{}
-			if event.Organizer.ID != uuid.Nil {
-				organizerInfo = dto.UserInfo{
-					ID:       event.Organizer.ID.String(),
-					FullName: event.Organizer.FullName,
-					Email:    event.Organizer.Email,
-				}
-			} else {
-				h.logger.Warn("Организатор не загружен для события",
-					zap.String("eventID", event.ID.String()),
-					zap.String("organizerID", event.OrganizerID.String()),
-				)
-			}
-
-			eventResponse := dto.EventResponse{
-				ID:                event.ID.String(),
-				Title:             event.Title,
-				ShortDescription:  event.ShortDescription,
-				FullDescription:   event.FullDescription,
-				StartDate:         event.StartDate,
-				EndDate:           event.EndDate,
-				ImageURL:          event.ImageURL,
-				PaymentInfo:       event.PaymentInfo,
-				MaxParticipants:   event.MaxParticipants,
-				Status:            string(event.Status),
-				ParticipantsCount: event.GetParticipantsCount(),
-				Categories:        categories,
-				Tags:              []string(event.Tags),
-				Address:           event.Address,
-				Latitude:          event.Latitude,
-				Longitude:         event.Longitude,
-				YandexMapLink:     event.YandexMapLink,
-				Organizer:         organizerInfo,
+			eventResponse := h.eventToResponse(event)
+			eventResponse.SearchRank = event.SearchRank
+			eventResponse.TitleHighlight = event.TitleHighlight
+			eventResponse.SearchSnippet = event.SearchSnippet
+			if geo {
+				distanceKm := event.DistanceKm
+				eventResponse.DistanceKm = &distanceKm
 			}
+			eventResponse.IsBookmarked = isBookmarked(event.ID)
+			eventResponse.GeocodeStatus = geocodeStatusFor(c, event)
 			
 			result = append(result, eventResponse)
 		}()
@@ -301,27 +465,139 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 		zap.Int("limit", limitInt),
 	)
 
-	totalPages := int((total + int64(limitInt) - 1) / int64(limitInt))
-	
-	response := dto.PaginationResponse{
-		Data: result,
-		Pagination: dto.Pagination{
+	response := dto.EventListResponse{Data: result, CityID: optionalIDString(cityID)}
+	if cursor != nil {
+		if cursor.withTotal {
+			cursorInfo.Total = &total
+		}
+		response.Cursor = &cursorInfo
+	} else {
+		response.Pagination = &dto.Pagination{
 			Page:       pageInt,
 			Limit:      limitInt,
 			Total:      total,
-			TotalPages: totalPages,
-		},
+			TotalPages: int((total + int64(limitInt) - 1) / int64(limitInt)),
+		}
+	}
+
+	if c.Query("facets") == "true" {
+		facets, err := h.buildEventFacets(scopes, loc)
+		if err != nil {
+			h.logger.Error("Ошибка при подсчете фасетов", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчете фильтров"})
+			return
+		}
+		response.Facets = facets
 	}
 
 	h.logger.Info("Отправка ответа",
 		zap.Int("dataLength", len(result)),
-		zap.Int64("total", response.Pagination.Total),
-		zap.Int("totalPages", response.Pagination.TotalPages),
+		zap.Int64("total", total),
+		zap.Bool("cursor", cursor != nil),
 	)
 
 	c.JSON(http.StatusOK, response)
 }
 
+// GetEventsMap godoc
+// @Summary События в области карты
+// @Description Активные события с координатами внутри прямоугольника видимой области карты. Возвращает облегченные маркеры, не более 500 ближайших по дате
+// @Tags События
+// @Produce json
+// @Param minLat query number true "Южная граница"
+// @Param minLon query number true "Западная граница"
+// @Param maxLat query number true "Северная граница"
+// @Param maxLon query number true "Восточная граница (может быть меньше minLon, если область пересекает 180-й меридиан)"
+// @Param dateFrom query string false "Фильтр по дате начала (YYYY-MM-DD или RFC3339)"
+// @Param dateTo query string false "Фильтр по дате окончания (YYYY-MM-DD или RFC3339)"
+// @Param timezone query string false "Часовой пояс IANA для dateFrom и dateTo (по умолчанию: пояс платформы)"
+// @Success 200 {object} dto.EventMapResponse "Маркеры событий"
+// @Failure 400 {object} map[string]string "Ошибка валидации параметров"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/map [get]
+func (h *EventHandler) GetEventsMap(c *gin.Context) {
+	bounds := make(map[string]float64, 4)
+	for _, key := range []string{"minLat", "minLon", "maxLat", "maxLon"} {
+		value, err := strconv.ParseFloat(c.Query(key), 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Необходимо указать minLat, minLon, maxLat и maxLon"})
+			return
+		}
+		bounds[key] = value
+	}
+
+	if bounds["minLat"] < -90 || bounds["maxLat"] > 90 || bounds["minLat"] > bounds["maxLat"] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Широта должна быть от -90 до 90, minLat не больше maxLat"})
+		return
+	}
+	if bounds["minLon"] < -180 || bounds["minLon"] > 180 || bounds["maxLon"] < -180 || bounds["maxLon"] > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Долгота должна быть от -180 до 180"})
+		return
+	}
+
+	query := database.DB.Model(&models.Event{}).
+		Where("status = ? AND visibility = ?", models.EventStatusActive, models.EventVisibilityPublic).
+		Where("latitude IS NOT NULL AND longitude IS NOT NULL").
+		Where("latitude BETWEEN ? AND ?", bounds["minLat"], bounds["maxLat"])
+	if bounds["minLon"] <= bounds["maxLon"] {
+		query = query.Where("longitude BETWEEN ? AND ?", bounds["minLon"], bounds["maxLon"])
+	} else {
+		query = query.Where("(longitude >= ? OR longitude <= ?)", bounds["minLon"], bounds["maxLon"])
+	}
+
+	loc, ok := filterLocation(c, nil)
+	if !ok {
+		return
+	}
+	if dateFrom := c.Query("dateFrom"); dateFrom != "" {
+		dateFromTime, ok := parseDateFilter(dateFrom, loc, false)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат dateFrom. Используйте YYYY-MM-DD или RFC3339"})
+			return
+		}
+		query = query.Where("start_date >= ?", dateFromTime)
+	}
+	if dateTo := c.Query("dateTo"); dateTo != "" {
+		dateToTime, ok := parseDateFilter(dateTo, loc, true)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат dateTo. Используйте YYYY-MM-DD или RFC3339"})
+			return
+		}
+		query = query.Where("end_date <= ?", dateToTime)
+	}
+
+	var events []models.Event
+	if err := query.Preload("Participants").Order("start_date ASC").Limit(utils.MaxMapMarkers + 1).Find(&events).Error; err != nil {
+		h.logger.Error("Ошибка при получении событий для карты", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении событий"})
+		return
+	}
+
+	truncated := len(events) > utils.MaxMapMarkers
+	if truncated {
+		events = events[:utils.MaxMapMarkers]
+	}
+
+	markers := make([]dto.EventMapMarker, 0, len(events))
+	for _, event := range events {
+		markers = append(markers, dto.EventMapMarker{
+			ID:                event.ID.String(),
+			Title:             event.Title,
+			StartDate:         event.StartDate.In(utils.EventLocation(event.Timezone)),
+			ImageURL:          event.ImageURL,
+			Address:           event.Address,
+			Latitude:          *event.Latitude,
+			Longitude:         *event.Longitude,
+			ParticipantsCount: event.GetParticipantsCount(),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.EventMapResponse{
+		Data:      markers,
+		Truncated: truncated,
+	})
+}
+
 // GetEvent godoc
 // @Summary Получить детальную информацию о событии
 // @Description Получение полной информации о событии, включая участников, отзывы и рейтинг
@@ -329,10 +605,11 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "UUID события"
+// @Param token query string false "Токен ссылки или приглашения для событий с ограниченной видимостью"
 // @Success 200 {object} dto.EventDetailResponse "Детальная информация о событии"
 // @Failure 400 {object} map[string]string "Неверный формат ID"
 // @Failure 403 {object} map[string]string "Доступ запрещен (отклоненное событие)"
-// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 404 {object} map[string]string "Событие не найдено или недоступно"
 // @Router /events/{id} [get]
 func (h *EventHandler) GetEvent(c *gin.Context) {
 	eventID := c.Param("id")
@@ -345,7 +622,7 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
 	var event models.Event
-	if err := database.DB.Preload("Organizer").Preload("Participants.User").Preload("Categories").Where("id = ?", eventID).First(&event).Error; err != nil {
+	if err := database.DB.Preload("Organizer").Preload("Participants.User").Preload("Categories").Preload("Venue").Preload("City").Where("id = ?", eventID).First(&event).Error; err != nil {
 		h.logger.Error("Событие не найдено", zap.String("eventID", eventID), zap.Error(err))
 		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
 		return
@@ -356,6 +633,17 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 		return
 	}
 
+	// Непубличное событие без доступа выглядит как несуществующее
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	// Просмотры организатором своего события не учитываются
+	if userID == nil || userID.(uuid.UUID) != event.OrganizerID {
+		h.trendingService.RecordView(event.ID, viewerKey(c))
+	}
+
 	isParticipant := false
 	if userID != nil {
 		var participant models.EventParticipant
@@ -364,6 +652,25 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 		}
 	}
 
+	var applicationStatus string
+	var pendingApplications *int64
+	if userID != nil && event.RequiresApproval && !isParticipant {
+		var application models.EventApplication
+		if err := database.DB.Select("status").Where("event_id = ? AND user_id = ?", eventID, userID).First(&application).Error; err == nil && application.Status != models.ApplicationStatusApproved {
+			applicationStatus = string(application.Status)
+		}
+	}
+	if userID != nil && (userID.(uuid.UUID) == event.OrganizerID || c.GetString("role") == "Администратор") {
+		var count int64
+		database.DB.Model(&models.EventApplication{}).Where("event_id = ? AND status = ?", eventID, models.ApplicationStatusPending).Count(&count)
+		pendingApplications = &count
+	}
+
+	ticketTypes, err := eventTicketTypes(&event)
+	if err != nil {
+		h.logger.Error("Ошибка получения билетов события", zap.String("eventID", eventID), zap.Error(err))
+	}
+
 	var reviews []models.EventReview
 	var avgRating float64
 	var totalReviews int
@@ -412,22 +719,40 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 		Title:             event.Title,
 		ShortDescription:  event.ShortDescription,
 		FullDescription:   event.FullDescription,
-		StartDate:         event.StartDate,
-		EndDate:           event.EndDate,
+		StartDate:         event.StartDate.In(utils.EventLocation(event.Timezone)),
+		EndDate:           event.EndDate.In(utils.EventLocation(event.Timezone)),
+		Timezone:          utils.EventLocation(event.Timezone).String(),
 		ImageURL:          event.ImageURL,
 		PaymentInfo:       event.PaymentInfo,
 		MaxParticipants:   event.MaxParticipants,
 		Status:            string(event.Status),
+		Visibility:        string(event.Visibility),
+		CommunityID:       optionalIDString(event.CommunityID),
+		ShareLink:         shareLinkFor(c, event),
+		RequiresApproval:  event.RequiresApproval,
 		ParticipantsCount: event.GetParticipantsCount(),
 		IsParticipant:     isParticipant,
+		ApplicationStatus: applicationStatus,
+		PendingApplications: pendingApplications,
+		TicketTypes:       ticketTypes,
+		IsBookmarked:      bookmarkFlags(c, []uuid.UUID{event.ID})(event.ID),
 		AverageRating:     avgRating,
 		TotalReviews:      totalReviews,
+		ReminderMessage:   event.ReminderMessage,
 		Categories:        categories,
 		Tags:              []string(event.Tags),
 		Address:           event.Address,
 		Latitude:          event.Latitude,
 		Longitude:         event.Longitude,
 		YandexMapLink:     event.YandexMapLink,
+		TwoGISMapLink:     event.TwoGISMapLink,
+		OSMMapLink:        event.OSMMapLink,
+		GeocodeStatus:     geocodeStatusFor(c, event),
+		Venue:             venueInfo(event.Venue),
+		City:              cityInfo(event.City),
+		CancellationReason: event.CancellationReason,
+		CancelledAt:       event.CancelledAt,
+		RescheduledTo:     event.RescheduledTo,
 		Organizer: dto.UserInfo{
 			ID:       event.Organizer.ID.String(),
 			FullName: event.Organizer.FullName,
@@ -451,6 +776,7 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 // @Param image formData file false "Изображение события (jpeg, jpg, png, gif, webp, svg, до 10MB)"
 // @Param imageURL formData string false "URL изображения (если не загружается файл)"
 // @Param paymentInfo formData string false "Информация об оплате"
+// @Param reminderMessage formData string false "Дополнительный текст в напоминаниях участникам"
 // @Param maxParticipants formData int false "Максимальное количество участников"
 // @Param categoryIDs formData []string false "ID категорий (массив UUID)"
 // @Param tags formData []string false "Теги (массив строк)"
@@ -458,18 +784,31 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 // @Param latitude formData number false "Широта"
 // @Param longitude formData number false "Долгота"
 // @Param yandexMapLink formData string false "Ссылка на Яндекс.Карты"
+// @Param venueID formData string false "UUID площадки: адрес и координаты копируются с нее, вместимость становится лимитом участников"
+// @Param cityID formData string false "UUID города; если не указан, определяется по координатам или берется у площадки"
+// @Param timezone formData string false "Часовой пояс IANA (например, Asia/Yekaterinburg); по умолчанию - пояс города или платформы"
+// @Param visibility formData string false "Видимость: public (по умолчанию), unlisted, invite_only, community"
+// @Param communityID formData string false "UUID сообщества для visibility=community"
+// @Param requiresApproval formData bool false "Запись по заявкам, которые одобряет организатор"
 // @Success 200 {object} map[string]interface{} "Событие создано"
 // @Failure 400 {object} map[string]string "Ошибка валидации"
 // @Failure 401 {object} map[string]string "Требуется авторизация"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /events [post]
 func (h *EventHandler) CreateEvent(c *gin.Context) {
-	var title, fullDescription, shortDescription, startDateStr, endDateStr, imageURL, paymentInfo, address, yandexMapLink string
+	var title, fullDescription, shortDescription, startDateStr, endDateStr, imageURL, paymentInfo, address, yandexMapLink, reminderMessage string
 	var maxParticipants *int
 	var latitude, longitude *float64
 	var categoryIDs []uuid.UUID
 	var tags []string
 	var participantIDs []uuid.UUID
+	var venueID *uuid.UUID
+	var cityID *uuid.UUID
+	var cityIDStr string
+	var timezone string
+	var visibility string
+	var communityID *uuid.UUID
+	var requiresApproval bool
 
 	contentType := c.GetHeader("Content-Type")
 	if strings.HasPrefix(contentType, "application/json") {
@@ -486,6 +825,7 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		endDateStr = req.EndDate.Format(time.RFC3339)
 		imageURL = req.ImageURL
 		paymentInfo = req.PaymentInfo
+		reminderMessage = req.ReminderMessage
 		maxParticipants = req.MaxParticipants
 		categoryIDs = req.CategoryIDs
 		tags = req.Tags
@@ -494,6 +834,12 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		longitude = req.Longitude
 		yandexMapLink = req.YandexMapLink
 		participantIDs = req.ParticipantIDs
+		venueID = req.VenueID
+		cityID = req.CityID
+		timezone = req.Timezone
+		visibility = req.Visibility
+		communityID = req.CommunityID
+		requiresApproval = req.RequiresApproval
 	} else {
 		title = c.PostForm("title")
 		fullDescription = c.PostForm("fullDescription")
@@ -502,6 +848,7 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		endDateStr = c.PostForm("endDate")
 		imageURL = c.PostForm("imageURL")
 		paymentInfo = c.PostForm("paymentInfo")
+		reminderMessage = c.PostForm("reminderMessage")
 		address = c.PostForm("address")
 		yandexMapLink = c.PostForm("yandexMapLink")
 
@@ -534,6 +881,27 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		if tagsStr := c.PostFormArray("tags"); len(tagsStr) > 0 {
 			tags = tagsStr
 		}
+
+		if venueIDStr := c.PostForm("venueID"); venueIDStr != "" {
+			if id, err := uuid.Parse(venueIDStr); err == nil {
+				venueID = &id
+			} else {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID площадки"})
+				return
+			}
+		}
+		cityIDStr = c.PostForm("cityID")
+		timezone = c.PostForm("timezone")
+		visibility = c.PostForm("visibility")
+		requiresApproval = c.PostForm("requiresApproval") == "true"
+		if communityIDStr := c.PostForm("communityID"); communityIDStr != "" {
+			id, err := uuid.Parse(communityIDStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID сообщества"})
+				return
+			}
+			communityID = &id
+		}
 	}
 
 	if title == "" || fullDescription == "" || startDateStr == "" || endDateStr == "" {
@@ -662,6 +1030,10 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Ссылка на карту должна быть до 1000 символов"})
 		return
 	}
+	if !utils.ValidateStringLength(reminderMessage, 0, utils.MaxReminderMessageLength) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Текст напоминания должен быть до 500 символов"})
+		return
+	}
 
 	if len(tags) > 0 {
 		if valid, errMsg := utils.ValidateTags(tags); !valid {
@@ -669,6 +1041,29 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 			return
 		}
 	}
+	if timezone != "" && !utils.ValidateTimezone(timezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный часовой пояс. Укажите название из базы IANA, например Europe/Moscow"})
+		return
+	}
+
+	var venue *models.Venue
+	if venueID != nil {
+		venue = &models.Venue{}
+		if err := database.DB.Where("id = ?", *venueID).First(venue).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Площадка не найдена"})
+			return
+		}
+	}
+
+	var ok bool
+	if cityIDStr != "" {
+		cityID, ok = parseCityID(c, cityIDStr)
+	} else {
+		cityID, ok = checkCityExists(c, cityID)
+	}
+	if !ok {
+		return
+	}
 
 	userID, _ := c.Get("userID")
 	organizerID := userID.(uuid.UUID)
@@ -702,6 +1097,7 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		EndDate:          endDate,
 		ImageURL:         imageURL,
 		PaymentInfo:      paymentInfo,
+		ReminderMessage:  reminderMessage,
 		MaxParticipants:  maxParticipants,
 		Status:           models.EventStatusActive,
 		OrganizerID:      organizerID,
@@ -710,7 +1106,23 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		Latitude:         latitude,
 		Longitude:        longitude,
 		YandexMapLink:    yandexMapLink,
+		Timezone:         timezone,
+		Visibility:       models.EventVisibility(visibility),
+		CommunityID:      communityID,
+		RequiresApproval: requiresApproval,
+	}
+	if !applyEventVisibility(c, &event) {
+		return
+	}
+	if venue != nil {
+		applyVenue(&event, venue)
+	}
+	if cityID != nil {
+		event.CityID = cityID
 	}
+	services.PrepareLocation(&event)
+	services.AssignEventCity(&event)
+	services.AssignEventTimezone(&event)
 
 	if err := database.DB.Create(&event).Error; err != nil {
 		h.logger.Error("Ошибка при создании события в БД", zap.Error(err))
@@ -718,6 +1130,10 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		return
 	}
 
+	if event.GeocodeStatus == models.GeocodeStatusPending {
+		go h.eventGeocodingService.GeocodeEvent(event.ID)
+	}
+
 	h.logger.Info("Событие успешно создано в БД",
 		zap.String("eventID", event.ID.String()),
 		zap.String("imageURL", event.ImageURL))
@@ -764,7 +1180,8 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 						UserID:  user.ID,
 					}
 					if err := database.DB.Create(&participant).Error; err == nil {
-						go h.emailService.SendEventNotification(user.Email, event.Title, "Вы были добавлены в новое событие: "+event.Title+". Дата начала: "+event.StartDate.Format("02.01.2006 15:04"))
+						h.analyticsService.RecordParticipation(event.ID, user.ID, models.ParticipationJoined)
+						go h.emailService.SendEventNotification(user.Email, event.Title, "Вы были добавлены в новое событие: "+event.Title+". Дата начала: "+utils.FormatEventTime(event.StartDate, event.Timezone))
 					} else {
 						h.logger.Error("Ошибка добавления участника при создании события", zap.Any("userID", user.ID), zap.String("eventID", event.ID.String()), zap.Error(err))
 					}
@@ -784,6 +1201,11 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		"id":      event.ID,
 		"message": "Событие создано",
 		"imageURL": event.ImageURL,
+		"geocodeStatus": event.GeocodeStatus,
+		"cityID":   event.CityID,
+		"timezone": utils.EventLocation(event.Timezone).String(),
+		"visibility": event.Visibility,
+		"shareLink": shareLinkFor(c, event),
 	})
 }
 
@@ -799,16 +1221,18 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 // @Success 200 {object} map[string]string "Событие обновлено"
 // @Failure 400 {object} map[string]string "Ошибка валидации"
 // @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен (не организатор события)"
 // @Failure 404 {object} map[string]string "Событие не найдено"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /events/{id} [put]
 func (h *EventHandler) UpdateEvent(c *gin.Context) {
-	eventID := c.Param("id")
-
-	if !utils.ValidateUUID(eventID) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+	// Менять событие (в том числе видимость, сообщество и премодерацию заявок) может только организатор или администратор
+	ownEvent, ok := loadOwnEvent(c)
+	if !ok {
 		return
 	}
+	event := *ownEvent
+	eventID := event.ID.String()
 
 	var req dto.UpdateEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -817,13 +1241,6 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		return
 	}
 
-	var event models.Event
-	if err := database.DB.Where("id = ?", eventID).First(&event).Error; err != nil {
-		h.logger.Error("Событие не найдено для обновления", zap.String("eventID", eventID), zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
-		return
-	}
-
 	oldEvent := models.Event{
 		Title:            event.Title,
 		ShortDescription: event.ShortDescription,
@@ -833,6 +1250,7 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		Address:          event.Address,
 		PaymentInfo:      event.PaymentInfo,
 		MaxParticipants:  event.MaxParticipants,
+		Timezone:         event.Timezone,
 	}
 
 	if req.Title != "" {
@@ -870,6 +1288,17 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		}
 		event.EndDate = req.EndDate
 	}
+	if !event.StartDate.Equal(oldEvent.StartDate) || !event.EndDate.Equal(oldEvent.EndDate) {
+		// Сессии программы должны оставаться в пределах дат события
+		var outside int64
+		database.DB.Model(&models.EventSession{}).
+			Where("event_id = ? AND (start_time < ? OR end_time > ?)", event.ID, event.StartDate, event.EndDate).
+			Count(&outside)
+		if outside > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Сессии программы выходят за новые даты события. Сначала перенесите их"})
+			return
+		}
+	}
 	if req.ImageURL != "" {
 		event.ImageURL = req.ImageURL
 	}
@@ -880,6 +1309,13 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		}
 		event.PaymentInfo = req.PaymentInfo
 	}
+	if req.ReminderMessage != nil {
+		if !utils.ValidateStringLength(*req.ReminderMessage, 0, utils.MaxReminderMessageLength) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Текст напоминания должен быть до 500 символов"})
+			return
+		}
+		event.ReminderMessage = *req.ReminderMessage
+	}
 	if req.MaxParticipants != nil {
 		if *req.MaxParticipants < 1 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Максимальное количество участников должно быть больше 0"})
@@ -892,6 +1328,10 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный статус. Допустимые значения: Активное, Прошедшее, Отклоненное"})
 			return
 		}
+		if models.EventStatus(req.Status) == models.EventStatusCancelled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Для отмены события используйте POST /api/events/:id/cancel"})
+			return
+		}
 		event.Status = models.EventStatus(req.Status)
 	}
 	if req.Address != "" {
@@ -923,6 +1363,84 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		event.YandexMapLink = req.YandexMapLink
 	}
 
+	if _, ok := checkCityExists(c, req.CityID); !ok {
+		return
+	}
+	if req.Timezone != "" {
+		if !utils.ValidateTimezone(req.Timezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный часовой пояс. Укажите название из базы IANA, например Europe/Moscow"})
+			return
+		}
+		event.Timezone = req.Timezone
+	}
+	oldCityID := event.CityID
+
+	// При смене адреса без новых координат старая точка уже неверна: адрес уйдет на геокодинг заново.
+	// Выбор площадки заменяет адрес и координаты целиком, ручная смена адреса отвязывает событие от площадки.
+	// Город при смене места определяется заново, если организатор не указал его явно
+	addressChanged := req.Address != "" && event.Address != oldEvent.Address
+	locationChanged := req.VenueID != nil || addressChanged || req.Latitude != nil || req.Longitude != nil
+	if locationChanged {
+		event.CityID = nil
+	}
+	if req.VenueID != nil {
+		var venue models.Venue
+		if err := database.DB.Where("id = ?", *req.VenueID).First(&venue).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Площадка не найдена"})
+			return
+		}
+		applyVenue(&event, &venue)
+		services.PrepareLocation(&event)
+	} else if addressChanged || req.Latitude != nil || req.Longitude != nil {
+		if addressChanged {
+			event.VenueID = nil
+		}
+		if addressChanged && (req.Latitude == nil || req.Longitude == nil) {
+			event.Latitude = nil
+			event.Longitude = nil
+		}
+		if req.YandexMapLink == "" {
+			event.YandexMapLink = ""
+		}
+		services.PrepareLocation(&event)
+	}
+	if req.CityID != nil {
+		event.CityID = req.CityID
+	}
+	if locationChanged {
+		services.AssignEventCity(&event)
+	}
+	// Часовой пояс, не указанный явно, следует за городом события
+	if req.Timezone == "" && optionalIDString(oldCityID) != optionalIDString(event.CityID) {
+		event.Timezone = ""
+		services.AssignEventTimezone(&event)
+	}
+
+	// Заявки на рассмотрении после отключения одобрения остаются, организатор может их рассмотреть
+	if req.RequiresApproval != nil {
+		if *req.RequiresApproval && !event.RequiresApproval {
+			var ticketTypes int64
+			database.DB.Model(&models.TicketType{}).Where("event_id = ?", event.ID).Count(&ticketTypes)
+			if ticketTypes > 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "На событие с билетами нельзя включить одобрение заявок"})
+				return
+			}
+		}
+		event.RequiresApproval = *req.RequiresApproval
+	}
+
+	if req.Visibility != "" || req.CommunityID != nil {
+		if req.Visibility != "" {
+			event.Visibility = models.EventVisibility(req.Visibility)
+		}
+		if req.CommunityID != nil {
+			event.CommunityID = req.CommunityID
+		}
+		if !applyEventVisibility(c, &event) {
+			return
+		}
+	}
+
 	if req.Tags != nil {
 		if valid, errMsg := utils.ValidateTags(req.Tags); !valid {
 			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
@@ -937,6 +1455,16 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		return
 	}
 
+	if !event.StartDate.Equal(oldEvent.StartDate) {
+		if err := services.ResetEventReminders(event.ID); err != nil {
+			h.logger.Error("Ошибка очистки журнала напоминаний при переносе события", zap.String("eventID", eventID), zap.Error(err))
+		}
+	}
+
+	if event.GeocodeStatus == models.GeocodeStatusPending {
+		go h.eventGeocodingService.GeocodeEvent(event.ID)
+	}
+
 	if req.CategoryIDs != nil {
 		if len(req.CategoryIDs) > 10 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Максимальное количество категорий на событие - 10"})
@@ -968,15 +1496,18 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 	}
 	if !req.StartDate.IsZero() && !event.StartDate.Equal(oldEvent.StartDate) {
 		changes = append(changes, fmt.Sprintf("Дата начала: с %s на %s", 
-			oldEvent.StartDate.Format("02.01.2006 15:04"), 
-			event.StartDate.Format("02.01.2006 15:04")))
+			utils.FormatEventTime(oldEvent.StartDate, oldEvent.Timezone), 
+			utils.FormatEventTime(event.StartDate, event.Timezone)))
 	}
 	if !req.EndDate.IsZero() && !event.EndDate.Equal(oldEvent.EndDate) {
 		changes = append(changes, fmt.Sprintf("Дата окончания: с %s на %s", 
-			oldEvent.EndDate.Format("02.01.2006 15:04"), 
-			event.EndDate.Format("02.01.2006 15:04")))
+			utils.FormatEventTime(oldEvent.EndDate, oldEvent.Timezone), 
+			utils.FormatEventTime(event.EndDate, event.Timezone)))
+	}
+	if event.Timezone != oldEvent.Timezone {
+		changes = append(changes, fmt.Sprintf("Время события указано в часовом поясе %s", utils.EventLocation(event.Timezone).String()))
 	}
-	if req.Address != "" && event.Address != oldEvent.Address {
+	if (req.Address != "" || req.VenueID != nil) && event.Address != oldEvent.Address {
 		oldAddr := oldEvent.Address
 		if oldAddr == "" {
 			oldAddr = "не указано"
@@ -1014,6 +1545,10 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		notificationMessage = "Данные события были обновлены. Проверьте информацию о событии."
 	}
 
+	go h.webhookService.Dispatch(models.WebhookEventEventUpdated, &event, map[string]interface{}{
+		"changes": changes,
+	})
+
 	var participants []models.EventParticipant
 	database.DB.Where("event_id = ?", eventID).Find(&participants)
 	for _, p := range participants {
@@ -1062,6 +1597,8 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 		return
 	}
 
+	go h.webhookService.Dispatch(models.WebhookEventEventCancelled, &event, nil)
+
 	var participants []models.EventParticipant
 	database.DB.Where("event_id = ?", eventID).Find(&participants)
 	for _, p := range participants {
@@ -1076,19 +1613,145 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Событие удалено"})
 }
 
+// CancelEvent godoc
+// @Summary Отменить событие
+// @Description Отмена события организатором или администратором с указанием причины и (необязательно) новой даты. Событие остается в истории участников со статусом "Отмененное", участники получают уведомление.
+// @Tags События
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param request body dto.CancelEventRequest true "Причина отмены и новая дата"
+// @Success 200 {object} map[string]string "Событие отменено"
+// @Failure 400 {object} map[string]string "Ошибка валидации или событие уже отменено/прошло"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Отменить событие может только организатор"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/cancel [post]
+func (h *EventHandler) CancelEvent(c *gin.Context) {
+	eventID := c.Param("id")
+
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	var req dto.CancelEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходимо указать причину отмены"})
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !utils.ValidateStringLength(req.Reason, 1, 1000) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Причина отмены должна быть от 1 до 1000 символов"})
+		return
+	}
+	if req.RescheduledTo != nil && req.RescheduledTo.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Новая дата должна быть в будущем"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	if event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Отменить событие может только организатор"})
+		return
+	}
+
+	if event.Status != models.EventStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Отменить можно только активное событие"})
+		return
+	}
+
+	now := time.Now()
+	event.Status = models.EventStatusCancelled
+	event.CancellationReason = req.Reason
+	event.CancelledAt = &now
+	event.RescheduledTo = req.RescheduledTo
+
+	if err := database.DB.Save(&event).Error; err != nil {
+		h.logger.Error("Ошибка при отмене события в БД", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отмене события"})
+		return
+	}
+
+	if err := h.matchingService.CloseEventMatching(event.ID); err != nil {
+		h.logger.Error("Ошибка закрытия матчинга отмененного события", zap.String("eventID", eventID), zap.Error(err))
+	}
+
+	webhookData := map[string]interface{}{
+		"reason": event.CancellationReason,
+	}
+	if event.RescheduledTo != nil {
+		webhookData["rescheduledTo"] = event.RescheduledTo
+	}
+	go h.webhookService.Dispatch(models.WebhookEventEventCancelled, &event, webhookData)
+
+	notificationMessage := "Событие отменено организатором.\n\nПричина: " + event.CancellationReason
+	if event.RescheduledTo != nil {
+		notificationMessage += "\nНовая дата проведения: " + utils.FormatEventTime(*event.RescheduledTo, event.Timezone)
+	}
+
+	var participants []models.EventParticipant
+	database.DB.Preload("User").Where("event_id = ?", eventID).Find(&participants)
+	for i := range participants {
+		user := participants[i].User
+		go h.emailService.SendEventNotification(user.Email, event.Title, notificationMessage)
+		go h.telegramService.SendEventCancelled(&user, &event)
+	}
+
+	// Брони снимаются, оплата билетов возвращается
+	go h.orderService.CloseEventOrders(event.ID)
+
+	// Заявки на рассмотрении отклоняются, заявители получают то же уведомление об отмене
+	var applications []models.EventApplication
+	database.DB.Preload("User").Where("event_id = ? AND status = ?", eventID, models.ApplicationStatusPending).Find(&applications)
+	if len(applications) > 0 {
+		if err := database.DB.Model(&models.EventApplication{}).
+			Where("event_id = ? AND status = ?", eventID, models.ApplicationStatusPending).
+			Updates(map[string]interface{}{
+				"status":     models.ApplicationStatusRejected,
+				"reason":     event.CancellationReason,
+				"decided_at": now,
+			}).Error; err != nil {
+			h.logger.Error("Ошибка отклонения заявок отмененного события", zap.String("eventID", eventID), zap.Error(err))
+		}
+	}
+	for i := range applications {
+		user := applications[i].User
+		go h.emailService.SendEventNotification(user.Email, event.Title, notificationMessage)
+		go h.telegramService.SendEventCancelled(&user, &event)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Событие отменено"})
+}
+
 // JoinEvent godoc
 // @Summary Присоединиться к событию
-// @Description Подтверждение участия в событии
+// @Description Подтверждение участия в событии. Если у события есть анкета, передаются ответы на ее вопросы. На событие с одобрением создается заявка, которую рассматривает организатор. На событие с билетами оформляется заказ выбранного билета: бесплатный сразу подтверждает участие, платный бронирует место до оплаты по confirmationURL
 // @Tags События
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "UUID события"
-// @Success 200 {object} map[string]string "Участие подтверждено"
-// @Failure 400 {object} map[string]interface{} "Событие не активное, лимит участников, уже участвуете"
+// @Param token query string false "Токен ссылки или приглашения для событий с ограниченной видимостью"
+// @Param request body dto.JoinEventRequest false "Ответы на анкету регистрации и сообщение организатору"
+// @Success 200 {object} map[string]interface{} "Участие подтверждено, заявка отправлена или заказ билета оформлен"
+// @Failure 400 {object} map[string]interface{} "Событие не активное, лимит участников, уже участвуете, заявка уже подана, ошибка в ответах анкеты, не выбран билет или продажа закрыта"
 // @Failure 401 {object} map[string]string "Требуется авторизация"
-// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 403 {object} map[string]string "Событие только по приглашению"
+// @Failure 404 {object} map[string]string "Событие или тип билета не найдены"
+// @Failure 409 {object} map[string]string "Билеты закончились или нет свободных мест"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Failure 503 {object} map[string]string "Оплата билетов не настроена"
 // @Router /events/{id}/join [post]
 func (h *EventHandler) JoinEvent(c *gin.Context) {
 	eventID := c.Param("id")
@@ -1098,6 +1761,12 @@ func (h *EventHandler) JoinEvent(c *gin.Context) {
 		return
 	}
 
+	var req dto.JoinEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
 	userID, _ := c.Get("userID")
 
 	var event models.Event
@@ -1107,6 +1776,22 @@ func (h *EventHandler) JoinEvent(c *gin.Context) {
 		return
 	}
 
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	// На событие только по приглашению записываются по своему приглашению: чужое, уже привязанное
+	// к другому аккаунту, не подходит
+	var invitation *models.EventInvitation
+	if event.Visibility == models.EventVisibilityInviteOnly && event.OrganizerID != userID.(uuid.UUID) {
+		invitation = findInvitation(c, event.ID)
+		if invitation == nil || (invitation.UserID != nil && *invitation.UserID != userID.(uuid.UUID)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Записаться на событие можно только по приглашению"})
+			return
+		}
+	}
+
 	if event.Status != models.EventStatusActive {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Можно участвовать только в активных событиях"})
 		return
@@ -1126,17 +1811,63 @@ func (h *EventHandler) JoinEvent(c *gin.Context) {
 		return
 	}
 
+	questions, err := registrationQuestions(event.ID)
+	if err != nil {
+		h.logger.Error("Ошибка получения анкеты события", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подтверждении участия"})
+		return
+	}
+	answers, errMsg := collectRegistrationAnswers(questions, req.Answers)
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	// На событие с билетами записываются покупкой билета
+	var ticketTypes int64
+	database.DB.Model(&models.TicketType{}).Where("event_id = ?", event.ID).Count(&ticketTypes)
+	if ticketTypes > 0 {
+		h.buyTicket(c, &event, req.TicketTypeID, answers, invitation)
+		return
+	}
+
+	// На событие с одобрением подается заявка, организатор записывается сам без нее
+	if event.RequiresApproval && event.OrganizerID != userID.(uuid.UUID) {
+		h.submitApplication(c, &event, req.Message, answers, invitation)
+		return
+	}
+
 	participant := models.EventParticipant{
 		EventID: event.ID,
 		UserID:  userID.(uuid.UUID),
 	}
 
-	if err := database.DB.Create(&participant).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&participant).Error; err != nil {
+			return err
+		}
+		if invitation != nil {
+			if err := acceptInvitation(tx, invitation, participant.UserID); err != nil {
+				return err
+			}
+		}
+		if len(answers) == 0 {
+			return nil
+		}
+		for i := range answers {
+			answers[i].ParticipantID = &participant.ID
+		}
+		return tx.Create(&answers).Error
+	})
+	if err != nil {
 		h.logger.Error("Ошибка при подтверждении участия в БД", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подтверждении участия"})
 		return
 	}
 
+	h.analyticsService.RecordParticipation(event.ID, participant.UserID, models.ParticipationJoined)
+	go h.dispatchParticipantWebhook(models.WebhookEventParticipantJoined, event, participant.UserID)
+
 	var organizer models.User
 	if err := database.DB.Where("id = ?", event.OrganizerID).First(&organizer).Error; err == nil {
 		var user models.User
@@ -1156,16 +1887,16 @@ func (h *EventHandler) JoinEvent(c *gin.Context) {
 
 // LeaveEvent godoc
 // @Summary Покинуть событие
-// @Description Отмена участия в событии
+// @Description Отмена участия в событии. Если пользователь еще не участник, снимается бронь неоплаченного билета или отзывается заявка на рассмотрении. С оплаченным билетом участие отменяется возвратом через организатора
 // @Tags События
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "UUID события"
 // @Success 200 {object} map[string]string "Участие отменено"
-// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 400 {object} map[string]string "Неверный формат ID или билет оплачен"
 // @Failure 401 {object} map[string]string "Требуется авторизация"
-// @Failure 404 {object} map[string]string "Участие не найдено"
+// @Failure 404 {object} map[string]string "Участие или заявка не найдены"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /events/{id}/leave [delete]
 func (h *EventHandler) LeaveEvent(c *gin.Context) {
@@ -1180,18 +1911,63 @@ func (h *EventHandler) LeaveEvent(c *gin.Context) {
 
 	var participant models.EventParticipant
 	if err := database.DB.Where("event_id = ? AND user_id = ?", eventID, userID).First(&participant).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Участие не найдено"})
+		// Не оплаченный еще билет: бронь снимается
+		result := database.DB.Model(&models.TicketOrder{}).
+			Where("event_id = ? AND user_id = ? AND status = ?", eventID, userID, models.OrderStatusPending).
+			Update("status", models.OrderStatusExpired)
+		if result.Error == nil && result.RowsAffected > 0 {
+			c.JSON(http.StatusOK, gin.H{"message": "Бронь билета снята"})
+			return
+		}
+		h.withdrawApplication(c, eventID, userID.(uuid.UUID))
 		return
 	}
 
+	// Деньги за билет возвращает организатор, бесплатный билет погашается вместе с участием
+	var order models.TicketOrder
+	if err := database.DB.Where("event_id = ? AND user_id = ? AND status = ?", eventID, userID, models.OrderStatusPaid).First(&order).Error; err == nil {
+		if order.Amount > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Билет оплачен: чтобы отменить участие, обратитесь к организатору за возвратом"})
+			return
+		}
+		if err := database.DB.Model(&order).Updates(map[string]interface{}{
+			"status":      models.OrderStatusRefunded,
+			"refunded_at": time.Now(),
+		}).Error; err != nil {
+			h.logger.Error("Ошибка погашения бесплатного билета", zap.String("orderID", order.ID.String()), zap.Error(err))
+		}
+	}
+
 	if err := database.DB.Delete(&participant).Error; err != nil {
 		h.logger.Error("Ошибка при отмене участия в БД", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отмене участия"})
 		return
 	}
+	h.analyticsService.RecordParticipation(participant.EventID, participant.UserID, models.ParticipationLeft)
+
+	// Места на сессиях программы освобождаются вместе с участием
+	if err := database.DB.
+		Where("user_id = ? AND session_id IN (?)", participant.UserID, database.DB.Model(&models.EventSession{}).Select("id").Where("event_id = ?", participant.EventID)).
+		Delete(&models.SessionBookmark{}).Error; err != nil {
+		h.logger.Error("Ошибка очистки личной программы при отмене участия", zap.String("eventID", eventID), zap.Error(err))
+	}
+	if err := database.DB.Where("participant_id = ?", participant.ID).Delete(&models.RegistrationAnswer{}).Error; err != nil {
+		h.logger.Error("Ошибка удаления ответов анкеты при отмене участия", zap.String("eventID", eventID), zap.Error(err))
+	}
+	// Журнал напоминаний очищается, чтобы при повторной записи участник снова их получал
+	if err := database.DB.Where("event_id = ? AND user_id = ?", participant.EventID, participant.UserID).Delete(&models.EventReminder{}).Error; err != nil {
+		h.logger.Error("Ошибка очистки журнала напоминаний при отмене участия", zap.String("eventID", eventID), zap.Error(err))
+	}
+	// Одобренная заявка удаляется, чтобы на событие с одобрением можно было подать заявку снова
+	if err := database.DB.Where("event_id = ? AND user_id = ? AND status = ?", participant.EventID, participant.UserID, models.ApplicationStatusApproved).
+		Delete(&models.EventApplication{}).Error; err != nil {
+		h.logger.Error("Ошибка удаления заявки при отмене участия", zap.String("eventID", eventID), zap.Error(err))
+	}
 
 	var event models.Event
 	if err := database.DB.Where("id = ?", eventID).First(&event).Error; err == nil {
+		go h.dispatchParticipantWebhook(models.WebhookEventParticipantLeft, event, participant.UserID)
+
 		var organizer models.User
 		if err := database.DB.Where("id = ?", event.OrganizerID).First(&organizer).Error; err == nil {
 			var user models.User
@@ -1214,7 +1990,7 @@ func (h *EventHandler) LeaveEvent(c *gin.Context) {
 
 // ExportParticipants godoc
 // @Summary Экспорт участников события
-// @Description Экспорт списка участников события в CSV или XLSX формате
+// @Description Экспорт списка участников события в CSV или XLSX формате. Ответы на анкету регистрации выгружаются отдельными колонками. Доступно организатору и администратору
 // @Tags События
 // @Accept json
 // @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
@@ -1225,6 +2001,7 @@ func (h *EventHandler) LeaveEvent(c *gin.Context) {
 // @Success 200 {file} file "Файл с участниками"
 // @Failure 400 {object} map[string]string "Неверный формат ID"
 // @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
 // @Failure 404 {object} map[string]string "Событие не найдено"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /events/{id}/export [get]
@@ -1237,21 +2014,64 @@ func (h *EventHandler) ExportParticipants(c *gin.Context) {
 	}
 
 	format := c.Query("format")
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Select("id", "organizer_id").Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	// В выгрузке контакты и ответы анкеты участников
+	if event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
 
 	var participants []models.EventParticipant
-	if err := database.DB.Preload("User").Preload("Event").Where("event_id = ?", eventID).Find(&participants).Error; err != nil {
+	if err := database.DB.Preload("User").Where("event_id = ?", eventID).Order("created_at ASC").Find(&participants).Error; err != nil {
 		h.logger.Error("Ошибка при получении участников для экспорта", zap.String("eventID", eventID), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении участников"})
 		return
 	}
 
+	questions, err := registrationQuestions(event.ID)
+	if err != nil {
+		h.logger.Error("Ошибка при получении анкеты для экспорта", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении участников"})
+		return
+	}
+	participantIDs := make([]uuid.UUID, len(participants))
+	for i, p := range participants {
+		participantIDs[i] = p.ID
+	}
+	var answers []models.RegistrationAnswer
+	if len(questions) > 0 && len(participantIDs) > 0 {
+		if err := database.DB.Where("participant_id IN ?", participantIDs).Find(&answers).Error; err != nil {
+			h.logger.Error("Ошибка при получении ответов анкеты для экспорта", zap.String("eventID", eventID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении участников"})
+			return
+		}
+	}
+	answerIndex := make(map[uuid.UUID]map[uuid.UUID]*models.RegistrationAnswer, len(participants))
+	for i := range answers {
+		a := &answers[i]
+		if answerIndex[*a.ParticipantID] == nil {
+			answerIndex[*a.ParticipantID] = make(map[uuid.UUID]*models.RegistrationAnswer)
+		}
+		answerIndex[*a.ParticipantID][a.QuestionID] = a
+	}
+
+	header, rows := participantExportRows(participants, questions, answerIndex)
+
 	if format == "csv" {
 		c.Header("Content-Type", "text/csv; charset=utf-8")
 		c.Header("Content-Disposition", "attachment; filename=participants.csv")
 		c.Writer.WriteString("\xEF\xBB\xBF")
-		c.Writer.WriteString("ФИО,Email\n")
-		for _, p := range participants {
-			c.Writer.WriteString(fmt.Sprintf("%s,%s\n", p.User.FullName, p.User.Email))
+		writer := csv.NewWriter(c.Writer)
+		writer.Write(header)
+		writer.WriteAll(rows)
+		if err := writer.Error(); err != nil {
+			h.logger.Error("Ошибка при записи CSV", zap.Error(err))
 		}
 		return
 	}
@@ -1267,12 +2087,16 @@ func (h *EventHandler) ExportParticipants(c *gin.Context) {
 	f.DeleteSheet("Sheet1")
 	sheetName := "Участники"
 	f.NewSheet(sheetName)
-	f.SetCellValue(sheetName, "A1", "ФИО")
-	f.SetCellValue(sheetName, "B1", "Email")
+	for col, title := range header {
+		cell, _ := excelize.CoordinatesToCellName(col+1, 1)
+		f.SetCellValue(sheetName, cell, title)
+	}
 
-	for i, p := range participants {
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", i+2), p.User.FullName)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", i+2), p.User.Email)
+	for i, row := range rows {
+		for col, value := range row {
+			cell, _ := excelize.CoordinatesToCellName(col+1, i+2)
+			f.SetCellValue(sheetName, cell, value)
+		}
 	}
 
 	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
@@ -1285,6 +2109,47 @@ func (h *EventHandler) ExportParticipants(c *gin.Context) {
 	}
 }
 
+// participantExportRows собирает заголовок и строки выгрузки участников с ответами на анкету
+func participantExportRows(participants []models.EventParticipant, questions []models.RegistrationQuestion, answerIndex map[uuid.UUID]map[uuid.UUID]*models.RegistrationAnswer) ([]string, [][]string) {
+	header := []string{"ФИО", "Email"}
+	for _, q := range questions {
+		header = append(header, spreadsheetCell(q.Label))
+	}
+	rows := make([][]string, len(participants))
+	for i, p := range participants {
+		row := []string{spreadsheetCell(p.User.FullName), spreadsheetCell(p.User.Email)}
+		for _, q := range questions {
+			row = append(row, spreadsheetCell(formatRegistrationAnswer(q, answerIndex[p.ID][q.ID])))
+		}
+		rows[i] = row
+	}
+	return header, rows
+}
+
+// spreadsheetCell экранирует значение, которое Excel или LibreOffice выполнили бы как формулу
+// (например, "=HYPERLINK(...)" в ответе анкеты): такое значение начинается с апострофа и выводится как текст
+func spreadsheetCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (h *EventHandler) dispatchParticipantWebhook(eventType models.WebhookEventType, event models.Event, userID uuid.UUID) {
+	var user models.User
+	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		h.logger.Error("Ошибка получения пользователя для вебхука", zap.String("userID", userID.String()), zap.Error(err))
+		return
+	}
+
+	h.webhookService.Dispatch(eventType, &event, map[string]interface{}{
+		"participant": services.WebhookUserData{
+			ID:       user.ID.String(),
+			FullName: user.FullName,
+		},
+	})
+}
+
 func (h *EventHandler) processEventImageFile(fileHeader *multipart.FileHeader) (string, error) {
 	h.logger.Info("Начало обработки файла изображения",
 		zap.String("originalFilename", fileHeader.Filename),
@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errEventFull = errors.New("event is full")
+
+// submitApplication создает заявку на участие в событии с одобрением вместо записи участником.
+// Ответы на анкету сохраняются вместе с заявкой и переходят участнику при одобрении
+func (h *EventHandler) submitApplication(c *gin.Context, event *models.Event, message string, answers []models.RegistrationAnswer, invitation *models.EventInvitation) {
+	userID, _ := c.Get("userID")
+
+	message = strings.TrimSpace(message)
+	if !utils.ValidateStringLength(message, 0, utils.MaxApplicationMessageLength) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Сообщение организатору должно быть до %d символов", utils.MaxApplicationMessageLength)})
+		return
+	}
+
+	var existing models.EventApplication
+	if err := database.DB.Where("event_id = ? AND user_id = ?", event.ID, userID).First(&existing).Error; err == nil {
+		switch existing.Status {
+		case models.ApplicationStatusPending:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Заявка уже отправлена и ожидает решения организатора"})
+			return
+		case models.ApplicationStatusRejected:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Организатор отклонил вашу заявку"})
+			return
+		}
+	}
+
+	application := models.EventApplication{
+		EventID: event.ID,
+		UserID:  userID.(uuid.UUID),
+		Message: message,
+		Status:  models.ApplicationStatusPending,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Одобренная заявка без участия остается, если участника удалили: ее заменяет новая
+		if existing.ID != uuid.Nil {
+			if err := tx.Where("application_id = ?", existing.ID).Delete(&models.RegistrationAnswer{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&existing).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&application).Error; err != nil {
+			return err
+		}
+		if invitation != nil {
+			if err := acceptInvitation(tx, invitation, application.UserID); err != nil {
+				return err
+			}
+		}
+		if len(answers) == 0 {
+			return nil
+		}
+		for i := range answers {
+			answers[i].ApplicationID = &application.ID
+		}
+		return tx.Create(&answers).Error
+	})
+	if err != nil {
+		h.logger.Error("Ошибка при создании заявки на участие", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке заявки"})
+		return
+	}
+
+	var organizer models.User
+	if err := database.DB.Where("id = ?", event.OrganizerID).First(&organizer).Error; err == nil {
+		var user models.User
+		if err := database.DB.Where("id = ?", userID).First(&user).Error; err == nil {
+			go h.emailService.SendEventNotification(organizer.Email, event.Title, user.FullName+" отправил заявку на участие в событии")
+		}
+	} else {
+		h.logger.Error("Ошибка получения организатора для уведомления о заявке", zap.Any("organizerID", event.OrganizerID), zap.Error(err))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Заявка отправлена организатору",
+		"applicationID": application.ID,
+		"status":        application.Status,
+	})
+}
+
+// GetApplications godoc
+// @Summary Заявки на участие в событии
+// @Description Заявки на событие с одобрением вместе с ответами на анкету. Доступно организатору и администратору
+// @Tags События
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param status query string false "Фильтр по статусу: pending, approved, rejected"
+// @Success 200 {array} dto.ApplicationResponse "Заявки"
+// @Failure 400 {object} map[string]string "Неверный формат ID или статуса"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/applications [get]
+func (h *EventHandler) GetApplications(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.Select("id", "organizer_id").Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	userID, _ := c.Get("userID")
+	if event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	query := database.DB.Preload("User").Where("event_id = ?", event.ID)
+	if status := c.Query("status"); status != "" {
+		if !models.IsValidApplicationStatus(models.ApplicationStatus(status)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный статус заявки. Допустимые значения: pending, approved, rejected"})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	var applications []models.EventApplication
+	if err := query.Order("created_at ASC").Find(&applications).Error; err != nil {
+		h.logger.Error("Ошибка получения заявок", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок"})
+		return
+	}
+
+	questions, err := registrationQuestions(event.ID)
+	if err != nil {
+		h.logger.Error("Ошибка получения анкеты события", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок"})
+		return
+	}
+	applicationIDs := make([]uuid.UUID, len(applications))
+	for i, application := range applications {
+		applicationIDs[i] = application.ID
+	}
+	var answers []models.RegistrationAnswer
+	if len(questions) > 0 && len(applicationIDs) > 0 {
+		if err := database.DB.Where("application_id IN ?", applicationIDs).Find(&answers).Error; err != nil {
+			h.logger.Error("Ошибка получения ответов анкеты для заявок", zap.String("eventID", eventID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок"})
+			return
+		}
+	}
+	answerIndex := make(map[uuid.UUID]map[uuid.UUID]*models.RegistrationAnswer, len(applications))
+	for i := range answers {
+		a := &answers[i]
+		if answerIndex[*a.ApplicationID] == nil {
+			answerIndex[*a.ApplicationID] = make(map[uuid.UUID]*models.RegistrationAnswer)
+		}
+		answerIndex[*a.ApplicationID][a.QuestionID] = a
+	}
+
+	result := make([]dto.ApplicationResponse, len(applications))
+	for i, application := range applications {
+		result[i] = applicationToResponse(application, questions, answerIndex[application.ID])
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ApproveApplication godoc
+// @Summary Одобрить заявку
+// @Description Заявитель становится участником события и получает уведомление. Одобрить можно, только пока есть свободные места
+// @Tags События
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param applicationId path string true "UUID заявки"
+// @Success 200 {object} dto.ApplicationResponse "Заявка одобрена"
+// @Failure 400 {object} map[string]string "Заявка уже рассмотрена, событие не активное"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Заявка не найдена"
+// @Failure 409 {object} map[string]string "Достигнут лимит участников"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/applications/{applicationId}/approve [post]
+func (h *EventHandler) ApproveApplication(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	application, ok := loadPendingApplication(c, event.ID)
+	if !ok {
+		return
+	}
+	if event.Status != models.EventStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Можно участвовать только в активных событиях"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	deciderID := userID.(uuid.UUID)
+	now := time.Now()
+
+	// Событие блокируется, чтобы параллельные одобрения не превысили лимит участников
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "max_participants").Where("id = ?", event.ID).First(&locked).Error; err != nil {
+			return err
+		}
+
+		var participant models.EventParticipant
+		err := tx.Where("event_id = ? AND user_id = ?", event.ID, application.UserID).First(&participant).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if locked.MaxParticipants != nil {
+				var count int64
+				if err := tx.Model(&models.EventParticipant{}).Where("event_id = ?", event.ID).Count(&count).Error; err != nil {
+					return err
+				}
+				if count >= int64(*locked.MaxParticipants) {
+					return errEventFull
+				}
+			}
+			participant = models.EventParticipant{EventID: event.ID, UserID: application.UserID}
+			if err := tx.Create(&participant).Error; err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.RegistrationAnswer{}).Where("application_id = ?", application.ID).
+			Update("participant_id", participant.ID).Error; err != nil {
+			return err
+		}
+
+		application.Status = models.ApplicationStatusApproved
+		application.DecidedByID = &deciderID
+		application.DecidedAt = &now
+		return tx.Save(application).Error
+	})
+	switch {
+	case errors.Is(err, errEventFull):
+		c.JSON(http.StatusConflict, gin.H{"error": "Достигнут максимальный лимит участников"})
+		return
+	case err != nil:
+		h.logger.Error("Ошибка одобрения заявки", zap.String("applicationID", application.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при одобрении заявки"})
+		return
+	}
+
+	h.analyticsService.RecordParticipation(event.ID, application.UserID, models.ParticipationJoined)
+	go h.dispatchParticipantWebhook(models.WebhookEventParticipantJoined, *event, application.UserID)
+	h.notifyApplicationDecision(event, application)
+
+	c.JSON(http.StatusOK, applicationToResponse(*application, nil, nil))
+}
+
+// RejectApplication godoc
+// @Summary Отклонить заявку
+// @Description Заявитель получает уведомление с причиной, если она указана. Повторно подать заявку на это событие нельзя
+// @Tags События
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param applicationId path string true "UUID заявки"
+// @Param request body dto.RejectApplicationRequest false "Причина отказа"
+// @Success 200 {object} dto.ApplicationResponse "Заявка отклонена"
+// @Failure 400 {object} map[string]string "Заявка уже рассмотрена, ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Заявка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/applications/{applicationId}/reject [post]
+func (h *EventHandler) RejectApplication(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	application, ok := loadPendingApplication(c, event.ID)
+	if !ok {
+		return
+	}
+
+	var req dto.RejectApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if !utils.ValidateStringLength(reason, 0, utils.MaxApplicationMessageLength) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Причина отказа должна быть до %d символов", utils.MaxApplicationMessageLength)})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	deciderID := userID.(uuid.UUID)
+	now := time.Now()
+	application.Status = models.ApplicationStatusRejected
+	application.Reason = reason
+	application.DecidedByID = &deciderID
+	application.DecidedAt = &now
+	if err := database.DB.Save(application).Error; err != nil {
+		h.logger.Error("Ошибка отклонения заявки", zap.String("applicationID", application.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отклонении заявки"})
+		return
+	}
+
+	h.notifyApplicationDecision(event, application)
+
+	c.JSON(http.StatusOK, applicationToResponse(*application, nil, nil))
+}
+
+// loadPendingApplication загружает заявку из пути запроса, ожидающую решения. При ошибке ответ уже отправлен
+func loadPendingApplication(c *gin.Context, eventID uuid.UUID) (*models.EventApplication, bool) {
+	applicationID := c.Param("applicationId")
+	if !utils.ValidateUUID(applicationID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID заявки"})
+		return nil, false
+	}
+
+	var application models.EventApplication
+	if err := database.DB.Preload("User").Where("id = ? AND event_id = ?", applicationID, eventID).First(&application).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Заявка не найдена"})
+		return nil, false
+	}
+	if application.Status != models.ApplicationStatusPending {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Заявка уже рассмотрена"})
+		return nil, false
+	}
+	return &application, true
+}
+
+// notifyApplicationDecision сообщает заявителю решение по заявке на email и в Telegram
+func (h *EventHandler) notifyApplicationDecision(event *models.Event, application *models.EventApplication) {
+	user := application.User
+	var message string
+	if application.Status == models.ApplicationStatusApproved {
+		message = "Ваша заявка на участие одобрена. Начало: " + utils.FormatEventTime(event.StartDate, event.Timezone)
+	} else {
+		message = "Ваша заявка на участие отклонена организатором"
+		if application.Reason != "" {
+			message += ". Причина: " + application.Reason
+		}
+	}
+
+	go h.emailService.SendEventNotification(user.Email, event.Title, message)
+	go h.telegramService.SendApplicationDecision(&user, event, application)
+}
+
+func applicationToResponse(application models.EventApplication, questions []models.RegistrationQuestion, answers map[uuid.UUID]*models.RegistrationAnswer) dto.ApplicationResponse {
+	result := dto.ApplicationResponse{
+		ID: application.ID.String(),
+		User: dto.UserInfo{
+			ID:       application.User.ID.String(),
+			FullName: application.User.FullName,
+			Email:    application.User.Email,
+		},
+		Message:   application.Message,
+		Status:    string(application.Status),
+		Reason:    application.Reason,
+		Answers:   make([]dto.ApplicationAnswerResponse, 0, len(questions)),
+		CreatedAt: application.CreatedAt,
+		DecidedAt: application.DecidedAt,
+	}
+	for _, question := range questions {
+		result.Answers = append(result.Answers, dto.ApplicationAnswerResponse{
+			QuestionID: question.ID.String(),
+			Label:      question.Label,
+			Value:      formatRegistrationAnswer(question, answers[question.ID]),
+		})
+	}
+	return result
+}
+
+// withdrawApplication отзывает заявку пользователя, ожидающую решения (DELETE /events/:id/leave без участия)
+func (h *EventHandler) withdrawApplication(c *gin.Context, eventID string, userID uuid.UUID) {
+	var application models.EventApplication
+	if err := database.DB.Where("event_id = ? AND user_id = ? AND status = ?", eventID, userID, models.ApplicationStatusPending).
+		First(&application).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Участие не найдено"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("application_id = ?", application.ID).Delete(&models.RegistrationAnswer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&application).Error
+	})
+	if err != nil {
+		h.logger.Error("Ошибка отзыва заявки", zap.String("applicationID", application.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отзыве заявки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка отозвана"})
+}
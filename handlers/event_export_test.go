@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"testing"
+
+	"bekend/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSpreadsheetCellEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`=HYPERLINK("http://evil.example.com","Нажмите")`, `'=HYPERLINK("http://evil.example.com","Нажмите")`},
+		{"+79991234567", "'+79991234567"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"Иванов Иван", "Иванов Иван"},
+		{"a=b", "a=b"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := spreadsheetCell(tt.value); got != tt.want {
+			t.Errorf("spreadsheetCell(%q) = %q, ожидалось %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParticipantExportRowsEscapesAnswers(t *testing.T) {
+	question := models.RegistrationQuestion{ID: uuid.New(), Type: models.QuestionTypeText, Label: "Компания"}
+	participant := models.EventParticipant{
+		ID:   uuid.New(),
+		User: models.User{FullName: "=1+1", Email: "user@example.com"},
+	}
+	answerIndex := map[uuid.UUID]map[uuid.UUID]*models.RegistrationAnswer{
+		participant.ID: {question.ID: {QuestionID: question.ID, Values: models.StringArray{`=HYPERLINK("http://evil.example.com")`}}},
+	}
+
+	header, rows := participantExportRows([]models.EventParticipant{participant}, []models.RegistrationQuestion{question}, answerIndex)
+
+	if len(header) != 3 || header[2] != "Компания" {
+		t.Fatalf("заголовок %v, ожидался столбец вопроса", header)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("ожидалась 1 строка, получено %d", len(rows))
+	}
+	want := []string{"'=1+1", "user@example.com", `'=HYPERLINK("http://evil.example.com")`}
+	for i := range want {
+		if rows[0][i] != want[i] {
+			t.Errorf("ячейка %d: %q, ожидалось %q", i, rows[0][i], want[i])
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetRecommendedEvents godoc
+// @Summary Рекомендованные события
+// @Description Лента "для вас": предстоящие события, подобранные по интересам, истории посещений, мэтчам и сообществам пользователя, с объяснением причин
+// @Tags События
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Количество событий (по умолчанию: 20, максимум: 50)"
+// @Success 200 {object} dto.RecommendedEventsResponse "Рекомендованные события"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/recommended [get]
+func (h *EventHandler) GetRecommendedEvents(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Требуется авторизация"})
+		return
+	}
+
+	limit := utils.DefaultRecommendationLimit
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= utils.MaxRecommendationLimit {
+		limit = l
+	}
+
+	recommended, err := h.recommendationService.GetRecommendedEvents(userID.(uuid.UUID), limit)
+	if err != nil {
+		h.logger.Error("Ошибка построения рекомендаций", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении рекомендаций"})
+		return
+	}
+
+	eventIDs := make([]uuid.UUID, len(recommended))
+	for i, item := range recommended {
+		eventIDs[i] = item.Event.ID
+	}
+	isBookmarked := bookmarkFlags(c, eventIDs)
+
+	result := make([]dto.RecommendedEventResponse, len(recommended))
+	for i, item := range recommended {
+		reasons := make([]dto.RecommendationReason, len(item.Reasons))
+		for j, reason := range item.Reasons {
+			reasons[j] = dto.RecommendationReason{
+				Type: string(reason.Type),
+				Text: reason.Text,
+			}
+		}
+		result[i] = dto.RecommendedEventResponse{
+			Event:   h.eventToResponse(item.Event),
+			Score:   item.Score,
+			Reasons: reasons,
+		}
+		result[i].Event.IsBookmarked = isBookmarked(item.Event.ID)
+	}
+
+	c.JSON(http.StatusOK, dto.RecommendedEventsResponse{Data: result})
+}
+
+// GetSimilarEvents godoc
+// @Summary Похожие события
+// @Description Предстоящие события, похожие на указанное по категориям, тегам, месту проведения и общим участникам. Рассчитываются периодически
+// @Tags События
+// @Produce json
+// @Param id path string true "ID события"
+// @Param limit query int false "Количество событий (по умолчанию: 6, максимум: 20)"
+// @Success 200 {object} dto.SimilarEventsResponse "Похожие события"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/similar [get]
+func (h *EventHandler) GetSimilarEvents(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	limit := utils.DefaultSimilarEventsLimit
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= utils.MaxSimilarEventsLimit {
+		limit = l
+	}
+
+	var event models.Event
+	if err := database.DB.Select(eventAccessColumns).Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	if event.Status == models.EventStatusRejected && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	// Предрассчитанные пары могли устареть: похожие события, которые уже начались или были отменены, отбрасываются
+	var similar []models.SimilarEvent
+	if err := database.DB.
+		Preload("SimilarEvent.Organizer").Preload("SimilarEvent.Participants").Preload("SimilarEvent.Categories").
+		Joins("JOIN events ON events.id = similar_events.similar_event_id").
+		Where("similar_events.event_id = ? AND events.deleted_at IS NULL AND events.status = ? AND events.visibility = ? AND events.start_date > ?",
+			eventID, models.EventStatusActive, models.EventVisibilityPublic, time.Now()).
+		Order("similar_events.score DESC").
+		Limit(limit).
+		Find(&similar).Error; err != nil {
+		h.logger.Error("Ошибка получения похожих событий", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении похожих событий"})
+		return
+	}
+
+	eventIDs := make([]uuid.UUID, len(similar))
+	for i, item := range similar {
+		eventIDs[i] = item.SimilarEventID
+	}
+	isBookmarked := bookmarkFlags(c, eventIDs)
+
+	response := dto.SimilarEventsResponse{Data: make([]dto.SimilarEventResponse, len(similar))}
+	for i, item := range similar {
+		response.Data[i] = dto.SimilarEventResponse{
+			Event:            h.eventToResponse(item.SimilarEvent),
+			Score:            item.Score,
+			SharedCategories: item.SharedCategories,
+			SharedTags:       item.SharedTags,
+			CoParticipants:   item.CoParticipants,
+			DistanceKm:       item.DistanceKm,
+		}
+		response.Data[i].Event.IsBookmarked = isBookmarked(item.SimilarEventID)
+		if response.ComputedAt == nil {
+			computedAt := item.ComputedAt
+			response.ComputedAt = &computedAt
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTrendingEvents godoc
+// @Summary События в тренде
+// @Description Активные события с наибольшим рейтингом популярности: недавние записи и просмотры с затуханием по времени и оценки прошлых событий организатора
+// @Tags События
+// @Produce json
+// @Param cityID query string false "UUID города; по умолчанию - город из профиля пользователя, all - все города"
+// @Param city query string false "Название города (для событий без города - поиск по вхождению в адрес)"
+// @Param categoryIDs query []string false "Фильтр по категориям (массив UUID)"
+// @Param limit query int false "Количество событий (по умолчанию: 20, максимум: 50)"
+// @Success 200 {object} dto.TrendingEventsResponse "События в тренде"
+// @Failure 400 {object} map[string]string "Ошибка валидации параметров"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/trending [get]
+func (h *EventHandler) GetTrendingEvents(c *gin.Context) {
+	limit := utils.DefaultTrendingLimit
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= utils.MaxTrendingLimit {
+		limit = l
+	}
+
+	query := database.DB.Model(&models.EventTrendingScore{}).
+		Preload("Event.Organizer").Preload("Event.Participants").Preload("Event.Categories").
+		Joins("JOIN events ON events.id = event_trending_scores.event_id").
+		Where("events.deleted_at IS NULL AND events.status = ? AND events.visibility = ? AND events.end_date > ? AND event_trending_scores.score > 0",
+			models.EventStatusActive, models.EventVisibilityPublic, time.Now())
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		userID = nil
+	}
+	city := c.Query("city")
+	cityID, ok := cityFilter(c, userID, city == "")
+	if !ok {
+		return
+	}
+	if cityID != nil {
+		query = query.Where("events.city_id = ?", *cityID)
+	}
+
+	if city != "" {
+		if !utils.ValidateStringLength(city, 1, 100) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Название города должно быть от 1 до 100 символов"})
+			return
+		}
+		query = query.Where("(events.city_id IN (SELECT id FROM cities WHERE LOWER(name) = LOWER(?)) OR (events.city_id IS NULL AND events.address ILIKE ?))",
+			city, "%"+escapeLike(city)+"%")
+	}
+
+	if categoryIDs := c.QueryArray("categoryIDs"); len(categoryIDs) > 0 {
+		for _, id := range categoryIDs {
+			if !utils.ValidateUUID(id) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID категории"})
+				return
+			}
+		}
+		query = query.Where("events.id IN (SELECT event_id FROM event_categories WHERE category_id IN ?)", categoryIDs)
+	}
+
+	var trending []models.EventTrendingScore
+	if err := query.Order("event_trending_scores.score DESC").Limit(limit).Find(&trending).Error; err != nil {
+		h.logger.Error("Ошибка получения трендовых событий", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении событий"})
+		return
+	}
+
+	eventIDs := make([]uuid.UUID, len(trending))
+	for i, item := range trending {
+		eventIDs[i] = item.EventID
+	}
+	isBookmarked := bookmarkFlags(c, eventIDs)
+
+	response := dto.TrendingEventsResponse{Data: make([]dto.TrendingEventResponse, len(trending))}
+	for i, item := range trending {
+		response.Data[i] = dto.TrendingEventResponse{
+			Event:           h.eventToResponse(item.Event),
+			Score:           item.Score,
+			RecentJoins:     item.RecentJoins,
+			RecentViews:     item.RecentViews,
+			OrganizerRating: item.OrganizerRating,
+		}
+		response.Data[i].Event.IsBookmarked = isBookmarked(item.EventID)
+		if response.ComputedAt == nil {
+			computedAt := item.ComputedAt
+			response.ComputedAt = &computedAt
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// viewerKey идентифицирует зрителя для подсчета просмотров: пользователя - по ID, анонимного посетителя - по IP.
+// Заголовки клиента (User-Agent и т.п.) не учитываются: иначе один клиент, меняя их, накручивал бы просмотры
+func viewerKey(c *gin.Context) string {
+	if userID, exists := c.Get("userID"); exists {
+		return "user:" + userID.(uuid.UUID).String()
+	}
+	sum := sha256.Sum256([]byte(c.ClientIP()))
+	return "anon:" + hex.EncodeToString(sum[:16])
+}
+
+// eventToResponse собирает карточку события для списков; Organizer, Participants и Categories должны быть загружены
+func (h *EventHandler) eventToResponse(event models.Event) dto.EventResponse {
+	categories := make([]dto.CategoryInfo, len(event.Categories))
+	for i, cat := range event.Categories {
+		categories[i] = dto.CategoryInfo{
+			ID:   cat.ID.String(),
+			Name: cat.Name,
+		}
+	}
+
+	organizerInfo := dto.UserInfo{}
+	if event.Organizer.ID != uuid.Nil {
+		organizerInfo = dto.UserInfo{
+			ID:       event.Organizer.ID.String(),
+			FullName: event.Organizer.FullName,
+			Email:    event.Organizer.Email,
+		}
+	} else {
+		h.logger.Warn("Организатор не загружен для события",
+			zap.String("eventID", event.ID.String()),
+			zap.String("organizerID", event.OrganizerID.String()),
+		)
+	}
+
+	loc := utils.EventLocation(event.Timezone)
+	return dto.EventResponse{
+		ID:                 event.ID.String(),
+		Title:              event.Title,
+		ShortDescription:   event.ShortDescription,
+		FullDescription:    event.FullDescription,
+		StartDate:          event.StartDate.In(loc),
+		EndDate:            event.EndDate.In(loc),
+		Timezone:           loc.String(),
+		ImageURL:           event.ImageURL,
+		PaymentInfo:        event.PaymentInfo,
+		MaxParticipants:    event.MaxParticipants,
+		Status:             string(event.Status),
+		Visibility:         string(event.Visibility),
+		RequiresApproval:   event.RequiresApproval,
+		ParticipantsCount:  event.GetParticipantsCount(),
+		Categories:         categories,
+		Tags:               []string(event.Tags),
+		Address:            event.Address,
+		Latitude:           event.Latitude,
+		Longitude:          event.Longitude,
+		YandexMapLink:      event.YandexMapLink,
+		TwoGISMapLink:      event.TwoGISMapLink,
+		OSMMapLink:         event.OSMMapLink,
+		VenueID:            optionalIDString(event.VenueID),
+		CityID:             optionalIDString(event.CityID),
+		CancellationReason: event.CancellationReason,
+		RescheduledTo:      event.RescheduledTo,
+		Organizer:          organizerInfo,
+	}
+}
+
+// geocodeStatusFor возвращает статус геокодинга адреса, если событие видит его организатор или администратор
+func geocodeStatusFor(c *gin.Context, event models.Event) string {
+	userID, exists := c.Get("userID")
+	if !exists || (userID.(uuid.UUID) != event.OrganizerID && c.GetString("role") != "Администратор") {
+		return ""
+	}
+	return string(event.GeocodeStatus)
+}
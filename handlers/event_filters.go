@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/services"
+
+	"gorm.io/gorm"
+)
+
+// Измерения фасетов каталога событий
+const (
+	facetCategories = "categories"
+	facetTags       = "tags"
+	facetDates      = "dates"
+	facetPrice      = "price"
+	facetSeats      = "seats"
+)
+
+// Событие платное, если указана информация об оплате или продаются платные билеты.
+// Места занимают участники и неоплаченные брони билетов (services.EventTakenSeatsSQL)
+const (
+	eventFreeCondition         = "(coalesce(payment_info, '') = '' AND NOT EXISTS (SELECT 1 FROM ticket_types WHERE ticket_types.event_id = events.id AND ticket_types.price > 0))"
+	eventHasFreeSeatsCondition = "(max_participants IS NULL OR max_participants > " + services.EventTakenSeatsSQL + ")"
+	maxTagFacets               = 20
+)
+
+// eventScope - условие фильтрации списка событий; facet - измерение, к которому относится фильтр (пусто - применяется всегда)
+type eventScope struct {
+	facet string
+	apply func(*gorm.DB) *gorm.DB
+}
+
+func whereScope(facet string, query string, args ...interface{}) eventScope {
+	return eventScope{
+		facet: facet,
+		apply: func(db *gorm.DB) *gorm.DB {
+			return db.Where(query, args...)
+		},
+	}
+}
+
+// applyEventScopes применяет все фильтры, кроме относящихся к измерению skipFacet
+func applyEventScopes(db *gorm.DB, scopes []eventScope, skipFacet string) *gorm.DB {
+	for _, scope := range scopes {
+		if skipFacet != "" && scope.facet == skipFacet {
+			continue
+		}
+		db = scope.apply(db)
+	}
+	return db
+}
+
+// eventKeysetSort описывает сортировку каталога для курсорной пагинации
+func eventKeysetSort(name, expr string, kind keysetKind, sortOrder string) *keysetSort {
+	desc := sortOrder == "DESC"
+	if desc {
+		name += ":desc"
+	}
+	return &keysetSort{
+		name:    name,
+		columns: []keysetColumn{{expr: expr, kind: kind}},
+		idExpr:  "events.id",
+		desc:    desc,
+	}
+}
+
+// sqlEscapeHTML экранирует HTML в SQL-выражении. Текст для ts_headline экранируется до подсветки:
+// в ответ попадают только теги <mark>, а разметка из названия и описания события остается текстом
+func sqlEscapeHTML(expr string) string {
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"'", "&#39;"}} {
+		expr = "replace(" + expr + ", '" + strings.ReplaceAll(r[0], "'", "''") + "', '" + r[1] + "')"
+	}
+	return expr
+}
+
+// parseDateFilter разбирает границу фильтра по дате. Дата YYYY-MM-DD понимается как сутки в часовом поясе loc
+// (для верхней границы - последняя секунда суток), значение RFC3339 - как точный момент со своим смещением
+func parseDateFilter(value string, loc *time.Location, endOfDay bool) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Second), true
+	}
+	return day, true
+}
+
+// eventPeriodRange возвращает интервал для быстрых фильтров "на этой неделе", "на выходных", "в этом месяце".
+// Границы суток считаются в часовом поясе now
+func eventPeriodRange(period string, now time.Time) (time.Time, time.Time, bool) {
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	daysToSunday := (7 - int(now.Weekday())) % 7
+	endOfWeek := startOfDay.AddDate(0, 0, daysToSunday+1).Add(-time.Second)
+
+	switch period {
+	case "week":
+		return now, endOfWeek, true
+	case "weekend":
+		from := startOfDay.AddDate(0, 0, daysToSunday-1)
+		if from.Before(now) {
+			from = now
+		}
+		return from, endOfWeek, true
+	case "month":
+		endOfMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()).Add(-time.Second)
+		return now, endOfMonth, true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// buildEventFacets считает фасеты для текущего набора фильтров.
+// Для каждого измерения его собственный фильтр не учитывается, чтобы показывать количество по альтернативным значениям.
+// Периоды считаются в часовом поясе loc, как фильтр period
+func (h *EventHandler) buildEventFacets(scopes []eventScope, loc *time.Location) (*dto.EventFacets, error) {
+	facetQuery := func(skipFacet string) *gorm.DB {
+		return applyEventScopes(database.DB.Model(&models.Event{}), scopes, skipFacet)
+	}
+
+	facets := &dto.EventFacets{
+		Categories: []dto.CategoryFacet{},
+		Tags:       []dto.TagFacet{},
+	}
+
+	if err := database.DB.Table("event_categories").
+		Select("categories.id::text AS id, categories.name AS name, COUNT(*) AS count").
+		Joins("JOIN categories ON categories.id = event_categories.category_id").
+		Where("event_categories.event_id IN (?)", facetQuery(facetCategories).Select("events.id")).
+		Group("categories.id, categories.name").
+		Order("count DESC, categories.name").
+		Scan(&facets.Categories).Error; err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Raw(`SELECT tag, COUNT(*) AS count FROM (?) AS e, unnest(e.tags) AS tag
+		GROUP BY tag ORDER BY count DESC, tag LIMIT ?`,
+		facetQuery(facetTags).Select("events.tags"), maxTagFacets,
+	).Scan(&facets.Tags).Error; err != nil {
+		return nil, err
+	}
+
+	if err := facetQuery(facetPrice).
+		Select("COUNT(*) FILTER (WHERE " + eventFreeCondition + ") AS free, COUNT(*) FILTER (WHERE NOT (" + eventFreeCondition + ")) AS paid").
+		Scan(&facets.Price).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(loc)
+	weekFrom, weekTo, _ := eventPeriodRange("week", now)
+	weekendFrom, weekendTo, _ := eventPeriodRange("weekend", now)
+	monthFrom, monthTo, _ := eventPeriodRange("month", now)
+	if err := facetQuery(facetDates).
+		Select("COUNT(*) FILTER (WHERE start_date BETWEEN ? AND ?) AS week, "+
+			"COUNT(*) FILTER (WHERE start_date BETWEEN ? AND ?) AS weekend, "+
+			"COUNT(*) FILTER (WHERE start_date BETWEEN ? AND ?) AS month",
+			weekFrom, weekTo, weekendFrom, weekendTo, monthFrom, monthTo).
+		Scan(&facets.Dates).Error; err != nil {
+		return nil, err
+	}
+
+	if err := facetQuery(facetSeats).
+		Where(eventHasFreeSeatsCondition).
+		Count(&facets.HasFreeSeats).Error; err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bekend/config"
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// eventAccessColumns - колонки события, нужные для проверки доступа в canViewEvent
+var eventAccessColumns = []string{"id", "status", "organizer_id", "visibility", "share_token", "community_id"}
+
+// canViewEvent проверяет, доступно ли событие текущему пользователю с учетом видимости. Непубличные события
+// видят организатор, администратор и участники, а также: unlisted - по токену ссылки (параметр token),
+// invite_only - приглашенные (по аккаунту или токену из письма), community - участники сообщества
+func canViewEvent(c *gin.Context, event *models.Event) bool {
+	if event.Visibility == models.EventVisibilityPublic || event.Visibility == "" {
+		return true
+	}
+	if c.GetString("role") == "Администратор" {
+		return true
+	}
+
+	userID, exists := c.Get("userID")
+	if exists {
+		if userID.(uuid.UUID) == event.OrganizerID {
+			return true
+		}
+		var count int64
+		database.DB.Model(&models.EventParticipant{}).Where("event_id = ? AND user_id = ?", event.ID, userID).Count(&count)
+		if count > 0 {
+			return true
+		}
+	}
+
+	switch event.Visibility {
+	case models.EventVisibilityUnlisted:
+		token := c.Query("token")
+		return token != "" && event.ShareToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(event.ShareToken)) == 1
+	case models.EventVisibilityInviteOnly:
+		return findInvitation(c, event.ID) != nil
+	case models.EventVisibilityCommunity:
+		return exists && event.CommunityID != nil && isCommunityMember(*event.CommunityID, userID.(uuid.UUID))
+	}
+	return false
+}
+
+// findInvitation ищет приглашение текущего пользователя на событие: по токену из ссылки, по аккаунту
+// или по email аккаунта
+func findInvitation(c *gin.Context, eventID uuid.UUID) *models.EventInvitation {
+	var conditions []string
+	var args []interface{}
+	if token := c.Query("token"); token != "" {
+		conditions = append(conditions, "token = ?")
+		args = append(args, token)
+	}
+	if userID, exists := c.Get("userID"); exists {
+		conditions = append(conditions, "user_id = ?", "email = (SELECT LOWER(email) FROM users WHERE id = ?)")
+		args = append(args, userID, userID)
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	var invitation models.EventInvitation
+	if err := database.DB.Where("event_id = ?", eventID).
+		Where("("+strings.Join(conditions, " OR ")+")", args...).
+		First(&invitation).Error; err != nil {
+		return nil
+	}
+	return &invitation
+}
+
+func isCommunityMember(communityID, userID uuid.UUID) bool {
+	var count int64
+	database.DB.Model(&models.MicroCommunity{}).
+		Where("id = ? AND (admin_id = ? OR id IN (SELECT community_id FROM community_members WHERE user_id = ?))", communityID, userID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// applyEventVisibility проверяет видимость события и сообщество для visibility=community, при необходимости
+// создает токен ссылки. При ошибке ответ уже отправлен
+func applyEventVisibility(c *gin.Context, event *models.Event) bool {
+	if event.Visibility == "" {
+		event.Visibility = models.EventVisibilityPublic
+	}
+	if !models.IsValidEventVisibility(event.Visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверная видимость. Допустимые значения: public, unlisted, invite_only, community"})
+		return false
+	}
+
+	if event.Visibility == models.EventVisibilityCommunity {
+		if event.CommunityID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Для события сообщества укажите communityID"})
+			return false
+		}
+		var count int64
+		if err := database.DB.Model(&models.MicroCommunity{}).Where("id = ?", *event.CommunityID).Count(&count).Error; err != nil || count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Сообщество не найдено"})
+			return false
+		}
+		userID, _ := c.Get("userID")
+		if !isCommunityMember(*event.CommunityID, userID.(uuid.UUID)) && c.GetString("role") != "Администратор" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Создавать события для сообщества могут только его участники"})
+			return false
+		}
+	} else {
+		event.CommunityID = nil
+	}
+
+	if event.Visibility == models.EventVisibilityUnlisted && event.ShareToken == "" {
+		event.ShareToken = utils.GenerateRandomString(32)
+	}
+	return true
+}
+
+// shareLinkFor возвращает ссылку на событие unlisted для организатора и администратора
+func shareLinkFor(c *gin.Context, event models.Event) string {
+	if event.Visibility != models.EventVisibilityUnlisted || event.ShareToken == "" {
+		return ""
+	}
+	userID, exists := c.Get("userID")
+	if !exists || (userID.(uuid.UUID) != event.OrganizerID && c.GetString("role") != "Администратор") {
+		return ""
+	}
+	return eventLink(event.ID, event.ShareToken)
+}
+
+func eventLink(eventID uuid.UUID, token string) string {
+	return fmt.Sprintf("%s/events/%s?token=%s", config.AppConfig.FrontendURL, eventID, token)
+}
+
+// RegenerateShareLink godoc
+// @Summary Обновить ссылку на событие
+// @Description Создает новый токен ссылки для события с видимостью unlisted; старая ссылка перестает работать. Доступно организатору и администратору
+// @Tags События
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Success 200 {object} dto.ShareLinkResponse "Новая ссылка"
+// @Failure 400 {object} map[string]string "Событие не unlisted"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/share-link [post]
+func (h *EventHandler) RegenerateShareLink(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	if event.Visibility != models.EventVisibilityUnlisted {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ссылка с токеном нужна только событиям с видимостью unlisted"})
+		return
+	}
+
+	token := utils.GenerateRandomString(32)
+	if err := database.DB.Model(event).Update("share_token", token).Error; err != nil {
+		h.logger.Error("Ошибка обновления ссылки на событие", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении ссылки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ShareLinkResponse{
+		Token: token,
+		URL:   eventLink(event.ID, token),
+	})
+}
+
+// GetInvitations godoc
+// @Summary Приглашения на событие
+// @Description Список отправленных приглашений. Доступно организатору и администратору
+// @Tags События
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Success 200 {array} dto.InvitationResponse "Приглашения"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/invitations [get]
+func (h *EventHandler) GetInvitations(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.Select("id", "organizer_id").Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	userID, _ := c.Get("userID")
+	if event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	var invitations []models.EventInvitation
+	if err := database.DB.Where("event_id = ?", event.ID).Order("created_at DESC").Find(&invitations).Error; err != nil {
+		h.logger.Error("Ошибка получения приглашений", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении приглашений"})
+		return
+	}
+
+	result := make([]dto.InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		result[i] = invitationToResponse(invitation)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateInvitations godoc
+// @Summary Пригласить на событие
+// @Description Отправляет приглашения на email со ссылкой на событие. Повторное приглашение отправляет письмо еще раз. Доступно организатору и администратору
+// @Tags События
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param request body dto.CreateInvitationsRequest true "Адреса приглашенных"
+// @Success 201 {array} dto.InvitationResponse "Приглашения отправлены"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/invitations [post]
+func (h *EventHandler) CreateInvitations(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	if event.Status != models.EventStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Приглашать можно только на активные события"})
+		return
+	}
+
+	var req dto.CreateInvitationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные приглашений", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+	if len(req.Emails) == 0 || len(req.Emails) > utils.MaxInvitationsPerRequest {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Укажите от 1 до %d адресов", utils.MaxInvitationsPerRequest)})
+		return
+	}
+
+	emails := make([]string, 0, len(req.Emails))
+	seen := make(map[string]bool, len(req.Emails))
+	for _, email := range req.Emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if !utils.ValidateEmail(email) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный email: " + email})
+			return
+		}
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+
+	var existing []models.EventInvitation
+	if err := database.DB.Where("event_id = ? AND email IN ?", event.ID, emails).Find(&existing).Error; err != nil {
+		h.logger.Error("Ошибка получения приглашений", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке приглашений"})
+		return
+	}
+	byEmail := make(map[string]models.EventInvitation, len(existing))
+	for _, invitation := range existing {
+		byEmail[invitation.Email] = invitation
+	}
+
+	var users []models.User
+	database.DB.Select("id", "email").Where("LOWER(email) IN ?", emails).Find(&users)
+	userIDs := make(map[string]uuid.UUID, len(users))
+	for _, user := range users {
+		userIDs[strings.ToLower(user.Email)] = user.ID
+	}
+
+	userID, _ := c.Get("userID")
+	invitations := make([]models.EventInvitation, 0, len(emails))
+	for _, email := range emails {
+		invitation, exists := byEmail[email]
+		if !exists {
+			invitation = models.EventInvitation{
+				EventID:     event.ID,
+				Email:       email,
+				Token:       utils.GenerateRandomString(32),
+				InvitedByID: userID.(uuid.UUID),
+			}
+			if id, ok := userIDs[email]; ok {
+				invitation.UserID = &id
+			}
+			if err := database.DB.Create(&invitation).Error; err != nil {
+				h.logger.Error("Ошибка создания приглашения", zap.String("eventID", event.ID.String()), zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке приглашений"})
+				return
+			}
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	var inviter models.User
+	database.DB.Select("full_name").Where("id = ?", userID).First(&inviter)
+	for _, invitation := range invitations {
+		if invitation.AcceptedAt != nil {
+			continue
+		}
+		go func(email, link string) {
+			if err := h.emailService.SendEventInvitation(email, inviter.FullName, event, link); err != nil {
+				h.logger.Error("Ошибка отправки приглашения на событие", zap.String("email", email), zap.Error(err))
+			}
+		}(invitation.Email, eventLink(event.ID, invitation.Token))
+	}
+
+	result := make([]dto.InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		result[i] = invitationToResponse(invitation)
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// DeleteInvitation godoc
+// @Summary Отозвать приглашение
+// @Description Ссылка из приглашения перестает работать. Если приглашенный уже записался, участие сохраняется
+// @Tags События
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param invitationId path string true "UUID приглашения"
+// @Success 200 {object} map[string]string "Приглашение отозвано"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Приглашение не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/invitations/{invitationId} [delete]
+func (h *EventHandler) DeleteInvitation(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	invitationID := c.Param("invitationId")
+	if !utils.ValidateUUID(invitationID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID приглашения"})
+		return
+	}
+
+	result := database.DB.Where("id = ? AND event_id = ?", invitationID, event.ID).Delete(&models.EventInvitation{})
+	if result.Error != nil {
+		h.logger.Error("Ошибка удаления приглашения", zap.String("invitationID", invitationID), zap.Error(result.Error))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отзыве приглашения"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Приглашение не найдено"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Приглашение отозвано"})
+}
+
+// acceptInvitation отмечает приглашение принятым и привязывает его к аккаунту записавшегося пользователя
+func acceptInvitation(tx *gorm.DB, invitation *models.EventInvitation, userID uuid.UUID) error {
+	return tx.Model(invitation).Updates(map[string]interface{}{
+		"user_id":     userID,
+		"accepted_at": time.Now(),
+	}).Error
+}
+
+func invitationToResponse(invitation models.EventInvitation) dto.InvitationResponse {
+	return dto.InvitationResponse{
+		ID:         invitation.ID.String(),
+		Email:      invitation.Email,
+		UserID:     optionalIDString(invitation.UserID),
+		AcceptedAt: invitation.AcceptedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
@@ -1,41 +1,29 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 
-	"bekend/config"
 	"bekend/dto"
+	"bekend/services"
+	"bekend/utils"
+
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-type GeocoderHandler struct{}
-
-func NewGeocoderHandler() *GeocoderHandler {
-	return &GeocoderHandler{}
+type GeocoderHandler struct {
+	geocoderService *services.GeocoderService
+	logger          *zap.Logger
 }
 
-
-type YandexGeocoderResponse struct {
-	Response struct {
-		GeoObjectCollection struct {
-			FeatureMember []struct {
-				GeoObject struct {
-					Point struct {
-						Pos string `json:"pos"`
-					} `json:"Point"`
-					MetaDataProperty struct {
-						GeocoderMetaData struct {
-							Text string `json:"text"`
-						} `json:"GeocoderMetaData"`
-					} `json:"metaDataProperty"`
-				} `json:"GeoObject"`
-			} `json:"featureMember"`
-		} `json:"GeoObjectCollection"`
-	} `json:"response"`
+func NewGeocoderHandler() *GeocoderHandler {
+	return &GeocoderHandler{
+		geocoderService: services.NewGeocoderService(),
+		logger:          utils.GetLogger(),
+	}
 }
 
 func (h *GeocoderHandler) GeocodeAddress(c *gin.Context) {
@@ -45,74 +33,18 @@ func (h *GeocoderHandler) GeocodeAddress(c *gin.Context) {
 		return
 	}
 
-	if config.AppConfig.YandexGeocoderAPIKey == "" {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Яндекс.Геокодер не настроен"})
+	if !utils.ValidateStringLength(req.Address, 1, utils.MaxAddressLength) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Адрес должен быть от 1 до %d символов", utils.MaxAddressLength)})
 		return
 	}
 
-	// Вызов API Яндекс.Геокодера
-	geocodeURL := fmt.Sprintf(
-		"https://geocode-maps.yandex.ru/1.x/?apikey=%s&geocode=%s&format=json",
-		config.AppConfig.YandexGeocoderAPIKey,
-		url.QueryEscape(req.Address),
-	)
-
-	resp, err := http.Get(geocodeURL)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обращении к геокодеру"})
-		return
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	result, err := h.geocoderService.Geocode(c.Request.Context(), req.Address)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при чтении ответа геокодера"})
-		return
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка геокодера: " + string(body)})
-		return
-	}
-
-	var geocodeResp YandexGeocoderResponse
-	if err := json.Unmarshal(body, &geocodeResp); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка парсинга ответа геокодера"})
-		return
-	}
-
-	if len(geocodeResp.Response.GeoObjectCollection.FeatureMember) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Адрес не найден"})
-		return
-	}
-
-	// Получаем первый результат
-	geoObject := geocodeResp.Response.GeoObjectCollection.FeatureMember[0].GeoObject
-	
-	// Парсим координаты (формат: "долгота широта")
-	var longitude, latitude float64
-	pos := geoObject.Point.Pos
-	if _, err := fmt.Sscanf(pos, "%f %f", &longitude, &latitude); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка парсинга координат"})
+		h.geocoderError(c, err, "Адрес не найден")
 		return
 	}
 
-	// Формируем ссылку на Яндекс.Карты
-	yandexMapLink := fmt.Sprintf("https://yandex.ru/maps/?pt=%f,%f&z=16", longitude, latitude)
-
-	response := dto.GeocodeResponse{
-		Latitude:      latitude,
-		Longitude:     longitude,
-		Address:       geoObject.MetaDataProperty.GeocoderMetaData.Text,
-		YandexMapLink: yandexMapLink,
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-type ReverseGeocodeRequest struct {
-	Latitude  float64 `json:"latitude" binding:"required"`
-	Longitude float64 `json:"longitude" binding:"required"`
+	c.JSON(http.StatusOK, geocodeToResponse(result))
 }
 
 func (h *GeocoderHandler) ReverseGeocode(c *gin.Context) {
@@ -132,61 +64,38 @@ func (h *GeocoderHandler) ReverseGeocode(c *gin.Context) {
 		return
 	}
 
-	if config.AppConfig.YandexGeocoderAPIKey == "" {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Яндекс.Геокодер не настроен"})
-		return
-	}
-
-	// Обратный геокодинг (координаты -> адрес)
-	geocodeURL := fmt.Sprintf(
-		"https://geocode-maps.yandex.ru/1.x/?apikey=%s&geocode=%f,%f&format=json",
-		config.AppConfig.YandexGeocoderAPIKey,
-		req.Longitude,
-		req.Latitude,
-	)
-
-	resp, err := http.Get(geocodeURL)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обращении к геокодеру"})
-		return
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	result, err := h.geocoderService.Reverse(c.Request.Context(), req.Latitude, req.Longitude)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при чтении ответа геокодера"})
+		h.geocoderError(c, err, "Адрес не найден для данных координат")
 		return
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка геокодера: " + string(body)})
-		return
-	}
+	c.JSON(http.StatusOK, geocodeToResponse(result))
+}
 
-	var geocodeResp YandexGeocoderResponse
-	if err := json.Unmarshal(body, &geocodeResp); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка парсинга ответа геокодера"})
-		return
+// geocoderError отвечает клиенту по ошибке GeocoderService
+func (h *GeocoderHandler) geocoderError(c *gin.Context, err error, notFoundMessage string) {
+	switch {
+	case errors.Is(err, services.ErrGeocoderNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Геокодер не настроен"})
+	case errors.Is(err, services.ErrAddressNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFoundMessage})
+	default:
+		h.logger.Error("Ошибка геокодинга", zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Ошибка при обращении к геокодеру"})
 	}
+}
 
-	if len(geocodeResp.Response.GeoObjectCollection.FeatureMember) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Адрес не найден для данных координат"})
-		return
-	}
-
-	geoObject := geocodeResp.Response.GeoObjectCollection.FeatureMember[0].GeoObject
-	
-	// Формируем ссылку на Яндекс.Карты
-	yandexMapLink := fmt.Sprintf("https://yandex.ru/maps/?pt=%f,%f&z=16", req.Longitude, req.Latitude)
-
-	response := dto.GeocodeResponse{
-		Latitude:      req.Latitude,
-		Longitude:     req.Longitude,
-		Address:       geoObject.MetaDataProperty.GeocoderMetaData.Text,
-		YandexMapLink: yandexMapLink,
+func geocodeToResponse(result *services.GeocodeResult) dto.GeocodeResponse {
+	return dto.GeocodeResponse{
+		Latitude:      result.Latitude,
+		Longitude:     result.Longitude,
+		Address:       result.Address,
+		YandexMapLink: utils.YandexMapLink(result.Latitude, result.Longitude),
+		TwoGISMapLink: utils.TwoGISMapLink(result.Latitude, result.Longitude),
+		OSMMapLink:    utils.OSMMapLink(result.Latitude, result.Longitude),
+		Provider:      result.Provider,
 	}
-
-	c.JSON(http.StatusOK, response)
 }
 
 func (h *GeocoderHandler) GenerateMapLink(c *gin.Context) {
@@ -201,7 +110,7 @@ func (h *GeocoderHandler) GenerateMapLink(c *gin.Context) {
 
 	if req.Latitude != nil && req.Longitude != nil {
 		// Если есть координаты, используем их
-		mapLink = fmt.Sprintf("https://yandex.ru/maps/?pt=%f,%f&z=16", *req.Longitude, *req.Latitude)
+		mapLink = utils.YandexMapLink(*req.Latitude, *req.Longitude)
 	} else if req.Address != "" {
 		// Если есть адрес, формируем ссылку с адресом
 		mapLink = fmt.Sprintf("https://yandex.ru/maps/?text=%s", url.QueryEscape(req.Address))
@@ -214,4 +123,3 @@ func (h *GeocoderHandler) GenerateMapLink(c *gin.Context) {
 		YandexMapLink: mapLink,
 	})
 }
-
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"bekend/database"
 	"bekend/dto"
 	"bekend/models"
+	"bekend/services"
 	"bekend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -14,12 +16,16 @@ import (
 )
 
 type MatchingHandler struct {
-	logger *zap.Logger
+	matchingService *services.MatchingService
+	telegramService *services.TelegramService
+	logger          *zap.Logger
 }
 
 func NewMatchingHandler() *MatchingHandler {
 	return &MatchingHandler{
-		logger: utils.GetLogger(),
+		matchingService: services.NewMatchingService(),
+		telegramService: services.NewTelegramService(),
+		logger:          utils.GetLogger(),
 	}
 }
 
@@ -47,6 +53,15 @@ func (h *MatchingHandler) CreateEventMatching(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
 		return
 	}
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	if event.Status == models.EventStatusCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Событие отменено"})
+		return
+	}
 
 	status := models.MatchStatusLooking
 	if req.Status != "" {
@@ -95,6 +110,16 @@ func (h *MatchingHandler) GetMatches(c *gin.Context) {
 		return
 	}
 
+	var event models.Event
+	if err := database.DB.Select(eventAccessColumns).Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
 	var userMatching models.EventMatching
 	if err := database.DB.Where("user_id = ? AND event_id = ?", userID, eventID).First(&userMatching).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не отметили, что ищете компанию для этого события"})
@@ -183,6 +208,21 @@ func (h *MatchingHandler) CreateMatchRequest(c *gin.Context) {
 		return
 	}
 
+	var event models.Event
+	if err := database.DB.Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	if event.Status == models.EventStatusCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Событие отменено"})
+		return
+	}
+
 	var existingRequest models.MatchRequest
 	if err := database.DB.Where("from_user_id = ? AND to_user_id = ? AND event_id = ?", userID, req.ToUserID, eventID).First(&existingRequest).Error; err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Запрос уже отправлен"})
@@ -203,6 +243,8 @@ func (h *MatchingHandler) CreateMatchRequest(c *gin.Context) {
 		return
 	}
 
+	go h.telegramService.SendMatchRequest(matchRequest.ID)
+
 	c.JSON(http.StatusCreated, gin.H{"message": "Запрос отправлен"})
 }
 
@@ -267,33 +309,13 @@ func (h *MatchingHandler) AcceptMatchRequest(c *gin.Context) {
 		return
 	}
 
-	var request models.MatchRequest
-	if err := database.DB.Where("id = ? AND to_user_id = ?", requestID, userID).First(&request).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Запрос не найден"})
-		return
-	}
-
-	if request.Status != models.MatchRequestStatusPending {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Запрос уже обработан"})
-		return
-	}
-
-	request.Status = models.MatchRequestStatusAccepted
-	if err := database.DB.Save(&request).Error; err != nil {
-		h.logger.Error("Ошибка принятия запроса", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при принятии запроса"})
+	request, err := h.matchingService.AcceptMatchRequest(uuid.MustParse(requestID), userID.(uuid.UUID))
+	if err != nil {
+		h.respondMatchRequestError(c, err, "Ошибка принятия запроса", "Ошибка при принятии запроса")
 		return
 	}
 
-	var fromMatching models.EventMatching
-	database.DB.Where("user_id = ? AND event_id = ?", request.FromUserID, request.EventID).First(&fromMatching)
-	fromMatching.Status = models.MatchStatusFound
-	database.DB.Save(&fromMatching)
-
-	var toMatching models.EventMatching
-	database.DB.Where("user_id = ? AND event_id = ?", request.ToUserID, request.EventID).First(&toMatching)
-	toMatching.Status = models.MatchStatusFound
-	database.DB.Save(&toMatching)
+	go h.telegramService.SendMatchRequestResult(request)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Запрос принят"})
 }
@@ -311,27 +333,29 @@ func (h *MatchingHandler) RejectMatchRequest(c *gin.Context) {
 		return
 	}
 
-	var request models.MatchRequest
-	if err := database.DB.Where("id = ? AND to_user_id = ?", requestID, userID).First(&request).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Запрос не найден"})
-		return
-	}
-
-	if request.Status != models.MatchRequestStatusPending {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Запрос уже обработан"})
+	request, err := h.matchingService.RejectMatchRequest(uuid.MustParse(requestID), userID.(uuid.UUID))
+	if err != nil {
+		h.respondMatchRequestError(c, err, "Ошибка отклонения запроса", "Ошибка при отклонении запроса")
 		return
 	}
 
-	request.Status = models.MatchRequestStatusRejected
-	if err := database.DB.Save(&request).Error; err != nil {
-		h.logger.Error("Ошибка отклонения запроса", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отклонении запроса"})
-		return
-	}
+	go h.telegramService.SendMatchRequestResult(request)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Запрос отклонен"})
 }
 
+func (h *MatchingHandler) respondMatchRequestError(c *gin.Context, err error, logMessage, errorMessage string) {
+	switch {
+	case errors.Is(err, services.ErrMatchRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Запрос не найден"})
+	case errors.Is(err, services.ErrMatchRequestProcessed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Запрос уже обработан"})
+	default:
+		h.logger.Error(logMessage, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage})
+	}
+}
+
 func (h *MatchingHandler) RemoveEventMatching(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
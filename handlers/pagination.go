@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"bekend/dto"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// keysetKind - тип значения ключа сортировки, сохраняемого в курсоре
+type keysetKind int
+
+const (
+	keysetTime keysetKind = iota
+	keysetInt
+)
+
+type keysetColumn struct {
+	expr string
+	kind keysetKind
+}
+
+// keysetSort - сортировка списка, для которой строятся курсоры. Все столбцы сортируются в одном направлении,
+// idExpr добавляется последним ключом, чтобы порядок был однозначным при равных значениях
+type keysetSort struct {
+	name    string
+	columns []keysetColumn
+	idExpr  string
+	desc    bool
+}
+
+// cursorPage - параметры курсорной пагинации запроса
+type cursorPage struct {
+	limit     int
+	cursor    *utils.PageCursor
+	backward  bool
+	withTotal bool
+}
+
+// parseCursorPage включает курсорную пагинацию, если передан after или before (пустой after - первая страница).
+// Возвращает nil, true для обычной пагинации page/limit и false, если ответ с ошибкой уже отправлен
+func parseCursorPage(c *gin.Context, limit int) (*cursorPage, bool) {
+	after, hasAfter := c.GetQuery("after")
+	before, hasBefore := c.GetQuery("before")
+	if !hasAfter && !hasBefore {
+		return nil, true
+	}
+	if hasAfter && hasBefore {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите только один из параметров after или before"})
+		return nil, false
+	}
+
+	page := &cursorPage{
+		limit:     limit,
+		backward:  hasBefore,
+		withTotal: c.Query("withTotal") == "true",
+	}
+
+	value := after
+	if hasBefore {
+		value = before
+		if value == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Для параметра before требуется курсор"})
+			return nil, false
+		}
+	}
+	if value != "" {
+		cursor, err := utils.DecodeCursor(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный курсор"})
+			return nil, false
+		}
+		page.cursor = cursor
+	}
+	return page, true
+}
+
+// apply добавляет условие "после/до курсора", сортировку и лимит с запасом в одну запись для определения следующей страницы
+func (p *cursorPage) apply(db *gorm.DB, sort keysetSort) (*gorm.DB, error) {
+	desc := sort.desc != p.backward
+
+	if p.cursor != nil {
+		if p.cursor.Sort != sort.name || len(p.cursor.Values) != len(sort.columns) {
+			return nil, utils.ErrInvalidCursor
+		}
+
+		exprs := make([]string, 0, len(sort.columns)+1)
+		args := make([]interface{}, 0, len(sort.columns)+1)
+		for i, column := range sort.columns {
+			value, err := parseKeysetValue(column.kind, p.cursor.Values[i])
+			if err != nil {
+				return nil, utils.ErrInvalidCursor
+			}
+			exprs = append(exprs, column.expr)
+			args = append(args, value)
+		}
+		exprs = append(exprs, sort.idExpr)
+		args = append(args, p.cursor.ID)
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+		db = db.Where("("+strings.Join(exprs, ", ")+") "+op+" ("+placeholders+")", args...)
+	}
+
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+	for _, column := range sort.columns {
+		db = db.Order(column.expr + direction)
+	}
+	return db.Order(sort.idExpr + direction).Limit(p.limit + 1), nil
+}
+
+// cursorPagination обрезает запасную запись, восстанавливает порядок страницы для before и строит курсоры соседних страниц.
+// key возвращает значения ключей сортировки записи в порядке sort.columns и ее ID
+func cursorPagination[T any](p *cursorPage, sort keysetSort, items []T, key func(T) ([]interface{}, uuid.UUID)) ([]T, dto.CursorPagination) {
+	hasMore := len(items) > p.limit
+	if hasMore {
+		items = items[:p.limit]
+	}
+	if p.backward {
+		slices.Reverse(items)
+	}
+
+	pagination := dto.CursorPagination{Limit: p.limit}
+	encode := func(item T) string {
+		values, id := key(item)
+		cursor := utils.PageCursor{Sort: sort.name, Values: make([]string, len(values)), ID: id}
+		for i, value := range values {
+			cursor.Values[i] = formatKeysetValue(value)
+		}
+		return utils.EncodeCursor(cursor)
+	}
+
+	if len(items) == 0 {
+		// Пустая страница: даем вернуться туда, откуда пришли
+		if p.cursor != nil {
+			if p.backward {
+				pagination.NextCursor = utils.EncodeCursor(*p.cursor)
+			} else {
+				pagination.PrevCursor = utils.EncodeCursor(*p.cursor)
+			}
+		}
+		return items, pagination
+	}
+
+	if p.backward {
+		if hasMore {
+			pagination.PrevCursor = encode(items[0])
+		}
+		pagination.NextCursor = encode(items[len(items)-1])
+	} else {
+		if hasMore {
+			pagination.NextCursor = encode(items[len(items)-1])
+		}
+		if p.cursor != nil {
+			pagination.PrevCursor = encode(items[0])
+		}
+	}
+	return items, pagination
+}
+
+func parseKeysetValue(kind keysetKind, value string) (interface{}, error) {
+	switch kind {
+	case keysetTime:
+		return time.Parse(time.RFC3339Nano, value)
+	case keysetInt:
+		return strconv.ParseInt(value, 10, 64)
+	}
+	return nil, utils.ErrInvalidCursor
+}
+
+func formatKeysetValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+	return fmt.Sprint(value)
+}
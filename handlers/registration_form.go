@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// checkboxChecked - значение отмеченного флажка в ответе анкеты
+const checkboxChecked = "true"
+
+// GetRegistrationForm godoc
+// @Summary Анкета регистрации на событие
+// @Description Вопросы, на которые нужно ответить при записи на событие
+// @Tags События
+// @Produce json
+// @Param id path string true "UUID события"
+// @Success 200 {object} dto.RegistrationFormResponse "Анкета события"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/registration-form [get]
+func (h *EventHandler) GetRegistrationForm(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Select(eventAccessColumns).Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	if event.Status == models.EventStatusRejected && (userID == nil || c.GetString("role") != "Администратор") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	questions, err := registrationQuestions(event.ID)
+	if err != nil {
+		h.logger.Error("Ошибка получения анкеты события", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении анкеты"})
+		return
+	}
+
+	c.JSON(http.StatusOK, registrationFormToResponse(event.ID, questions))
+}
+
+// UpdateRegistrationForm godoc
+// @Summary Изменить анкету регистрации
+// @Description Заменяет анкету события целиком (только организатор и администратор). Вопросы с id обновляются, без id - создаются, не переданные удаляются вместе с ответами. При смене типа вопроса его ответы удаляются, при смене вариантов - ответы с исчезнувшими вариантами
+// @Tags События
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param request body dto.UpdateRegistrationFormRequest true "Вопросы анкеты"
+// @Success 200 {object} dto.RegistrationFormResponse "Анкета обновлена"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/registration-form [put]
+func (h *EventHandler) UpdateRegistrationForm(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateRegistrationFormRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные анкеты", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+	if len(req.Questions) > utils.MaxRegistrationQuestions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Максимальное количество вопросов в анкете - %d", utils.MaxRegistrationQuestions)})
+		return
+	}
+
+	existing, err := registrationQuestions(event.ID)
+	if err != nil {
+		h.logger.Error("Ошибка получения анкеты события", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении анкеты"})
+		return
+	}
+	existingByID := make(map[uuid.UUID]models.RegistrationQuestion, len(existing))
+	for _, question := range existing {
+		existingByID[question.ID] = question
+	}
+
+	questions := make([]models.RegistrationQuestion, len(req.Questions))
+	kept := make(map[uuid.UUID]bool, len(req.Questions))
+	// Ответы на вопрос со сменившимся типом удаляются целиком, со сменившимися вариантами - если выбранного варианта больше нет
+	var retyped []uuid.UUID
+	reoptioned := make(map[uuid.UUID]models.StringArray)
+	for i, item := range req.Questions {
+		question := models.RegistrationQuestion{EventID: event.ID}
+		if item.ID != nil {
+			found, exists := existingByID[*item.ID]
+			if !exists || kept[*item.ID] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Вопрос анкеты не найден"})
+				return
+			}
+			question = found
+			kept[*item.ID] = true
+		}
+		question.Position = i
+		question.Type = models.QuestionType(item.Type)
+		question.Label = strings.TrimSpace(item.Label)
+		question.Options = models.StringArray(trimStrings(item.Options))
+		question.Required = item.Required
+		if errMsg := validateRegistrationQuestion(&question); errMsg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Вопрос %d: %s", i+1, errMsg)})
+			return
+		}
+		if item.ID != nil {
+			previous := existingByID[*item.ID]
+			switch {
+			case previous.Type != question.Type:
+				retyped = append(retyped, question.ID)
+			case !slices.Equal(previous.Options, question.Options):
+				reoptioned[question.ID] = question.Options
+			}
+		}
+		questions[i] = question
+	}
+
+	var removed []uuid.UUID
+	for _, question := range existing {
+		if !kept[question.ID] {
+			removed = append(removed, question.ID)
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(removed) > 0 {
+			if err := tx.Where("question_id IN ?", removed).Delete(&models.RegistrationAnswer{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", removed).Delete(&models.RegistrationQuestion{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(retyped) > 0 {
+			if err := tx.Where("question_id IN ?", retyped).Delete(&models.RegistrationAnswer{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := deleteStaleChoiceAnswers(tx, reoptioned); err != nil {
+			return err
+		}
+		for i := range questions {
+			if err := tx.Save(&questions[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("Ошибка сохранения анкеты события", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении анкеты"})
+		return
+	}
+
+	c.JSON(http.StatusOK, registrationFormToResponse(event.ID, questions))
+}
+
+// deleteStaleChoiceAnswers удаляет ответы, в которых выбран вариант, которого больше нет среди options вопроса
+func deleteStaleChoiceAnswers(tx *gorm.DB, options map[uuid.UUID]models.StringArray) error {
+	if len(options) == 0 {
+		return nil
+	}
+	questionIDs := make([]uuid.UUID, 0, len(options))
+	for id := range options {
+		questionIDs = append(questionIDs, id)
+	}
+
+	var answers []models.RegistrationAnswer
+	if err := tx.Where("question_id IN ?", questionIDs).Find(&answers).Error; err != nil {
+		return err
+	}
+	var stale []uuid.UUID
+	for _, answer := range answers {
+		for _, value := range answer.Values {
+			if !slices.Contains(options[answer.QuestionID], value) {
+				stale = append(stale, answer.ID)
+				break
+			}
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return tx.Where("id IN ?", stale).Delete(&models.RegistrationAnswer{}).Error
+}
+
+// registrationQuestions возвращает вопросы анкеты события в порядке показа
+func registrationQuestions(eventID uuid.UUID) ([]models.RegistrationQuestion, error) {
+	var questions []models.RegistrationQuestion
+	err := database.DB.Where("event_id = ?", eventID).Order("position ASC").Find(&questions).Error
+	return questions, err
+}
+
+// validateRegistrationQuestion проверяет вопрос анкеты и возвращает текст ошибки для клиента
+func validateRegistrationQuestion(question *models.RegistrationQuestion) string {
+	if !models.IsValidQuestionType(question.Type) {
+		return "тип вопроса должен быть text, single_choice, multi_choice или checkbox"
+	}
+	if !utils.ValidateStringLength(question.Label, 1, utils.MaxQuestionLabelLength) {
+		return fmt.Sprintf("текст вопроса должен быть от 1 до %d символов", utils.MaxQuestionLabelLength)
+	}
+
+	if question.Type != models.QuestionTypeSingleChoice && question.Type != models.QuestionTypeMultiChoice {
+		question.Options = models.StringArray{}
+		return ""
+	}
+	if len(question.Options) < 2 || len(question.Options) > utils.MaxQuestionOptions {
+		return fmt.Sprintf("у вопроса с выбором должно быть от 2 до %d вариантов", utils.MaxQuestionOptions)
+	}
+	seen := make(map[string]bool, len(question.Options))
+	for _, option := range question.Options {
+		if !utils.ValidateStringLength(option, 1, utils.MaxQuestionOptionLength) {
+			return fmt.Sprintf("вариант ответа должен быть от 1 до %d символов", utils.MaxQuestionOptionLength)
+		}
+		if seen[option] {
+			return "варианты ответа не должны повторяться"
+		}
+		seen[option] = true
+	}
+	return ""
+}
+
+// collectRegistrationAnswers проверяет ответы участника на анкету события и возвращает их для сохранения.
+// Если ответы не прошли проверку, возвращает текст ошибки для клиента
+func collectRegistrationAnswers(questions []models.RegistrationQuestion, answers []dto.RegistrationAnswerRequest) ([]models.RegistrationAnswer, string) {
+	byQuestion := make(map[uuid.UUID]dto.RegistrationAnswerRequest, len(answers))
+	for _, answer := range answers {
+		byQuestion[answer.QuestionID] = answer
+	}
+
+	known := make(map[uuid.UUID]bool, len(questions))
+	result := make([]models.RegistrationAnswer, 0, len(questions))
+	for _, question := range questions {
+		known[question.ID] = true
+		answer := byQuestion[question.ID]
+
+		var values []string
+		switch question.Type {
+		case models.QuestionTypeText:
+			if value := strings.TrimSpace(answer.Value); value != "" {
+				if !utils.ValidateStringLength(value, 1, utils.MaxRegistrationTextAnswer) {
+					return nil, fmt.Sprintf("Ответ на вопрос «%s» должен быть до %d символов", question.Label, utils.MaxRegistrationTextAnswer)
+				}
+				values = []string{value}
+			}
+		case models.QuestionTypeSingleChoice:
+			if answer.Value != "" {
+				if !containsString(question.Options, answer.Value) {
+					return nil, fmt.Sprintf("Недопустимый вариант ответа на вопрос «%s»", question.Label)
+				}
+				values = []string{answer.Value}
+			}
+		case models.QuestionTypeMultiChoice:
+			for _, value := range answer.Values {
+				if !containsString(question.Options, value) {
+					return nil, fmt.Sprintf("Недопустимый вариант ответа на вопрос «%s»", question.Label)
+				}
+				if !containsString(values, value) {
+					values = append(values, value)
+				}
+			}
+		case models.QuestionTypeCheckbox:
+			if answer.Value == checkboxChecked {
+				values = []string{checkboxChecked}
+			}
+		}
+
+		if len(values) == 0 {
+			if question.Required {
+				return nil, fmt.Sprintf("Ответьте на обязательный вопрос «%s»", question.Label)
+			}
+			continue
+		}
+		result = append(result, models.RegistrationAnswer{
+			QuestionID: question.ID,
+			Values:     models.StringArray(values),
+		})
+	}
+
+	for _, answer := range answers {
+		if !known[answer.QuestionID] {
+			return nil, "Вопрос анкеты не найден"
+		}
+	}
+	return result, ""
+}
+
+// formatRegistrationAnswer возвращает ответ на вопрос анкеты в виде текста для выгрузки
+func formatRegistrationAnswer(question models.RegistrationQuestion, answer *models.RegistrationAnswer) string {
+	if question.Type == models.QuestionTypeCheckbox {
+		if answer != nil && containsString(answer.Values, checkboxChecked) {
+			return "Да"
+		}
+		return "Нет"
+	}
+	if answer == nil {
+		return ""
+	}
+	return strings.Join(answer.Values, ", ")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func trimStrings(values []string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = strings.TrimSpace(value)
+	}
+	return result
+}
+
+func registrationFormToResponse(eventID uuid.UUID, questions []models.RegistrationQuestion) dto.RegistrationFormResponse {
+	result := dto.RegistrationFormResponse{
+		EventID:   eventID.String(),
+		Questions: make([]dto.RegistrationQuestionResponse, len(questions)),
+	}
+	for i, question := range questions {
+		options := []string(question.Options)
+		if options == nil {
+			options = []string{}
+		}
+		result.Questions[i] = dto.RegistrationQuestionResponse{
+			ID:       question.ID.String(),
+			Type:     string(question.Type),
+			Label:    question.Label,
+			Options:  options,
+			Required: question.Required,
+		}
+	}
+	return result
+}
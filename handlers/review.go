@@ -1,22 +1,36 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"bekend/config"
 	"bekend/database"
 	"bekend/dto"
 	"bekend/models"
+	"bekend/services"
 	"bekend/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
-type ReviewHandler struct{}
+type ReviewHandler struct {
+	reviewService *services.ReviewService
+	logger        *zap.Logger
+}
 
 func NewReviewHandler() *ReviewHandler {
-	return &ReviewHandler{}
+	return &ReviewHandler{
+		reviewService: services.NewReviewService(),
+		logger:        utils.GetLogger(),
+	}
 }
 
 type CreateReviewRequest struct {
@@ -103,6 +117,8 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
 
 	database.DB.Preload("User").First(&review, review.ID)
 
+	go h.reviewService.NotifyReviewCreated(&event, &review)
+
 	c.JSON(http.StatusOK, gin.H{
 		"id": review.ID,
 		"rating": review.Rating,
@@ -115,6 +131,14 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
 	})
 }
 
+// reviewKeysetSort - порядок отзывов события: сначала новые
+var reviewKeysetSort = keysetSort{
+	name:    "createdAt:desc",
+	columns: []keysetColumn{{expr: "event_reviews.created_at", kind: keysetTime}},
+	idExpr:  "event_reviews.id",
+	desc:    true,
+}
+
 // GetEventReviews godoc
 // @Summary Получить отзывы о событии
 // @Description Получение списка отзывов о событии с пагинацией
@@ -125,6 +149,8 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
 // @Param id path string true "UUID события"
 // @Param page query int false "Номер страницы (по умолчанию: 1)"
 // @Param limit query int false "Количество элементов на странице (по умолчанию: 20, максимум: 100)"
+// @Param after query string false "Курсор следующей страницы (пустое значение - первая страница в режиме курсоров)"
+// @Param before query string false "Курсор предыдущей страницы"
 // @Success 200 {object} dto.ReviewsResponse "Список отзывов с пагинацией"
 // @Failure 400 {object} map[string]string "Неверный формат ID"
 // @Failure 401 {object} map[string]string "Требуется авторизация"
@@ -152,21 +178,51 @@ func (h *ReviewHandler) GetEventReviews(c *gin.Context) {
 
 	offset := (pageInt - 1) * limitInt
 
+	cursor, ok := parseCursorPage(c, limitInt)
+	if !ok {
+		return
+	}
+
+	// Количество и средняя оценка нужны в ответе в любом режиме, поэтому считаются одним агрегатом
+	var stats struct {
+		Total     int64
+		AvgRating float64
+	}
+	if err := database.DB.Model(&models.EventReview{}).
+		Select("COUNT(*) AS total, COALESCE(AVG(rating), 0) AS avg_rating").
+		Where("event_id = ?", eventID).
+		Scan(&stats).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении отзывов"})
+		return
+	}
+	total := stats.Total
+
 	var reviews []models.EventReview
 	query := database.DB.Preload("User").Where("event_id = ?", eventID)
+	if cursor != nil {
+		var err error
+		if query, err = cursor.apply(query, reviewKeysetSort); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный курсор"})
+			return
+		}
+	} else {
+		query = query.Offset(offset).Limit(limitInt).Order("created_at DESC")
+	}
 
-	var total int64
-	query.Model(&models.EventReview{}).Count(&total)
-
-	if err := query.Offset(offset).Limit(limitInt).Order("created_at DESC").Find(&reviews).Error; err != nil {
+	if err := query.Find(&reviews).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении отзывов"})
 		return
 	}
 
+	var cursorInfo dto.CursorPagination
+	if cursor != nil {
+		reviews, cursorInfo = cursorPagination(cursor, reviewKeysetSort, reviews, func(review models.EventReview) ([]interface{}, uuid.UUID) {
+			return []interface{}{review.CreatedAt}, review.ID
+		})
+	}
+
 	result := make([]dto.ReviewResponse, len(reviews))
-	var totalRating int
 	for i, review := range reviews {
-		totalRating += review.Rating
 		result[i] = dto.ReviewResponse{
 			ID:        review.ID.String(),
 			EventID:   review.EventID.String(),
@@ -183,29 +239,23 @@ func (h *ReviewHandler) GetEventReviews(c *gin.Context) {
 		}
 	}
 
-	var avgRating float64
-	if total > 0 {
-		var allReviews []models.EventReview
-		database.DB.Where("event_id = ?", eventID).Find(&allReviews)
-		var allRating int
-		for _, r := range allReviews {
-			allRating += r.Rating
-		}
-		avgRating = float64(allRating) / float64(total)
-	}
-
-	totalPages := int((total + int64(limitInt) - 1) / int64(limitInt))
-	c.JSON(http.StatusOK, dto.ReviewsResponse{
+	response := dto.ReviewsResponse{
 		Data:          result,
-		AverageRating: avgRating,
+		AverageRating: stats.AvgRating,
 		TotalReviews:  total,
-		Pagination: dto.Pagination{
+	}
+	if cursor != nil {
+		cursorInfo.Total = &total
+		response.Cursor = &cursorInfo
+	} else {
+		response.Pagination = &dto.Pagination{
 			Page:       pageInt,
 			Limit:      limitInt,
 			Total:      total,
-			TotalPages: totalPages,
-		},
-	})
+			TotalPages: int((total + int64(limitInt) - 1) / int64(limitInt)),
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // UpdateReview godoc
@@ -306,3 +356,179 @@ func (h *ReviewHandler) DeleteReview(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Отзыв удален"})
 }
 
+
+// GetFeedbackSummary godoc
+// @Summary Сводка отзывов для организатора
+// @Description Средняя оценка, распределение оценок и доля участников, оставивших отзыв. Доступно организатору события и администратору
+// @Tags Отзывы
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Success 200 {object} dto.ReviewSummaryResponse "Сводка отзывов"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/reviews/summary [get]
+func (h *ReviewHandler) GetFeedbackSummary(c *gin.Context) {
+	eventID := c.Param("id")
+
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	if event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	summary, err := h.reviewService.GetFeedbackSummary(event.ID)
+	if err != nil {
+		h.logger.Error("Ошибка получения сводки отзывов", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сводки отзывов"})
+		return
+	}
+
+	distribution := make(map[string]int64, len(summary.Distribution))
+	for rating, count := range summary.Distribution {
+		distribution[strconv.Itoa(rating)] = count
+	}
+
+	c.JSON(http.StatusOK, dto.ReviewSummaryResponse{
+		EventID:           event.ID.String(),
+		AverageRating:     summary.AverageRating,
+		TotalReviews:      summary.TotalReviews,
+		Distribution:      distribution,
+		ParticipantsCount: summary.ParticipantsCount,
+		ResponseRate:      summary.ResponseRate,
+	})
+}
+
+// QuickReviewPage godoc
+// @Summary Страница подтверждения оценки
+// @Description Открывается по ссылке из приглашения (email/Telegram) и показывает форму подтверждения оценки. Сама ссылка ничего не меняет: почтовые сканеры и предпросмотр ссылок открывают все ссылки письма, поэтому отзыв создается только отправкой формы (POST /reviews/quick)
+// @Tags Отзывы
+// @Produce html
+// @Param token query string true "Токен приглашения"
+// @Param rating query int true "Оценка 1-5"
+// @Success 200 {string} string "HTML-страница подтверждения"
+// @Failure 400 {object} map[string]string "Неверная оценка или недействительная ссылка"
+// @Router /reviews/quick [get]
+func (h *ReviewHandler) QuickReviewPage(c *gin.Context) {
+	rating, err := strconv.Atoi(c.Query("rating"))
+	if err != nil || rating < 1 || rating > 5 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Рейтинг должен быть от 1 до 5"})
+		return
+	}
+
+	token := c.Query("token")
+	event, _, err := h.reviewService.QuickReviewEvent(token)
+	switch {
+	case errors.Is(err, services.ErrReviewEventNotPast):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Отзыв можно оставить только для прошедших событий"})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ссылка недействительна или устарела"})
+		return
+	}
+
+	var page bytes.Buffer
+	if err := quickReviewPageTemplate.Execute(&page, map[string]interface{}{
+		"EventTitle": event.Title,
+		"Token":      token,
+		"Rating":     rating,
+		"Stars":      strings.Repeat("★", rating),
+	}); err != nil {
+		h.logger.Error("Ошибка формирования страницы подтверждения оценки", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// QuickReview godoc
+// @Summary Оценка события в один клик
+// @Description Создает отзыв с оценкой по подписанной ссылке из приглашения после подтверждения на странице GET /reviews/quick и перенаправляет на страницу события во фронтенде с параметром review=created|exists
+// @Tags Отзывы
+// @Accept x-www-form-urlencoded
+// @Accept json
+// @Param request body dto.QuickReviewRequest true "Токен приглашения и оценка 1-5"
+// @Success 303 "Перенаправление на страницу события"
+// @Failure 400 {object} map[string]string "Неверная оценка или недействительная ссылка"
+// @Failure 403 {object} map[string]string "Вы не участвовали в этом событии"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /reviews/quick [post]
+func (h *ReviewHandler) QuickReview(c *gin.Context) {
+	var req dto.QuickReviewRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Рейтинг должен быть от 1 до 5"})
+		return
+	}
+
+	review, err := h.reviewService.CreateQuickReview(req.Token, req.Rating)
+	result := "created"
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrReviewAlreadyExists):
+		result = "exists"
+	case errors.Is(err, services.ErrReviewInvitationInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ссылка недействительна или устарела"})
+		return
+	case errors.Is(err, services.ErrReviewEventNotPast):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Отзыв можно оставить только для прошедших событий"})
+		return
+	case errors.Is(err, services.ErrReviewNotParticipant):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Вы не участвовали в этом событии"})
+		return
+	default:
+		h.logger.Error("Ошибка создания отзыва по ссылке", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании отзыва"})
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("%s/events/%s?review=%s", config.AppConfig.FrontendURL, review.EventID, result))
+}
+
+// quickReviewPageTemplate - страница подтверждения оценки; форма отправляется на тот же адрес методом POST
+var quickReviewPageTemplate = template.Must(template.New("quickReview").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<meta name="robots" content="noindex">
+	<title>Оценка события</title>
+	<style>
+		body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+		.container { max-width: 600px; margin: 40px auto; padding: 20px; text-align: center; }
+		.stars { font-size: 32px; color: #ffc107; }
+		button { padding: 10px 24px; background: #ffc107; color: #333; border: none; border-radius: 5px; font-size: 16px; font-weight: bold; cursor: pointer; }
+	</style>
+</head>
+<body>
+	<div class="container">
+		<h2>Оценка события "{{.EventTitle}}"</h2>
+		<p class="stars">{{.Stars}}</p>
+		<form method="POST" action="quick">
+			<input type="hidden" name="token" value="{{.Token}}">
+			<input type="hidden" name="rating" value="{{.Rating}}">
+			<button type="submit">Поставить оценку {{.Rating}}</button>
+		</form>
+	</div>
+</body>
+</html>
+`))
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SearchHandler struct {
+	logger *zap.Logger
+}
+
+func NewSearchHandler() *SearchHandler {
+	return &SearchHandler{
+		logger: utils.GetLogger(),
+	}
+}
+
+// suggestSources - откуда берутся подсказки: таблица, колонка и условие видимости
+var suggestSources = map[dto.SuggestionType]struct {
+	table  string
+	column string
+	where  string
+}{
+	dto.SuggestionTypeEvent:     {"events", "title", "deleted_at IS NULL AND status = '" + string(models.EventStatusActive) + "' AND visibility = '" + string(models.EventVisibilityPublic) + "'"},
+	dto.SuggestionTypeCategory:  {"categories", "name", "TRUE"},
+	dto.SuggestionTypeCommunity: {"micro_communities", "name", "TRUE"},
+	dto.SuggestionTypeInterest:  {"interests", "name", "TRUE"},
+}
+
+var suggestTypesOrder = []dto.SuggestionType{
+	dto.SuggestionTypeEvent,
+	dto.SuggestionTypeTag,
+	dto.SuggestionTypeCategory,
+	dto.SuggestionTypeCommunity,
+	dto.SuggestionTypeInterest,
+}
+
+// Suggest godoc
+// @Summary Подсказки для поиска
+// @Description Автодополнение по названиям активных событий, тегам, категориям, сообществам и интересам. Использует триграммное сходство (pg_trgm), поэтому находит варианты с опечатками. Результаты отсортированы по сходству.
+// @Tags Поиск
+// @Produce json
+// @Param q query string true "Поисковый запрос (1-100 символов)"
+// @Param types query []string false "Типы подсказок: event, tag, category, community, interest (по умолчанию все)"
+// @Param limit query int false "Максимум подсказок каждого типа (по умолчанию: 5, максимум: 10)"
+// @Success 200 {object} dto.SuggestResponse "Подсказки"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /search/suggest [get]
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if !utils.ValidateStringLength(q, 1, 100) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Поисковый запрос должен быть от 1 до 100 символов"})
+		return
+	}
+
+	limit := utils.DefaultSuggestLimit
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= utils.MaxSuggestLimit {
+		limit = l
+	}
+
+	types := suggestTypesOrder
+	if requested := c.QueryArray("types"); len(requested) > 0 {
+		types = nil
+		for _, t := range requested {
+			suggestionType := dto.SuggestionType(t)
+			if _, ok := suggestSources[suggestionType]; !ok && suggestionType != dto.SuggestionTypeTag {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный тип подсказки: " + t})
+				return
+			}
+			types = append(types, suggestionType)
+		}
+	}
+
+	suggestions := []dto.Suggestion{}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %v", utils.SuggestSimilarityThreshold)).Error; err != nil {
+			return err
+		}
+
+		for _, suggestionType := range types {
+			var items []dto.Suggestion
+			var err error
+			if suggestionType == dto.SuggestionTypeTag {
+				items, err = h.suggestTags(tx, q, limit)
+			} else {
+				items, err = h.suggestNames(tx, suggestionType, q, limit)
+			}
+			if err != nil {
+				return err
+			}
+			suggestions = append(suggestions, items...)
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("Ошибка получения подсказок", zap.String("q", q), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении подсказок"})
+		return
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+
+	c.JSON(http.StatusOK, dto.SuggestResponse{
+		Query:       q,
+		Suggestions: suggestions,
+	})
+}
+
+// suggestNames ищет по префиксу (ILIKE) или по сходству слов (<%), оба условия используют триграммный индекс
+func (h *SearchHandler) suggestNames(tx *gorm.DB, suggestionType dto.SuggestionType, q string, limit int) ([]dto.Suggestion, error) {
+	source := suggestSources[suggestionType]
+
+	var rows []struct {
+		ID    string
+		Text  string
+		Score float64
+	}
+	query := fmt.Sprintf(`SELECT id::text AS id, %[2]s AS text, word_similarity(@q, %[2]s) AS score
+		FROM %[1]s
+		WHERE %[3]s AND (%[2]s ILIKE @prefix OR @q <%% %[2]s)
+		ORDER BY score DESC, %[2]s
+		LIMIT @limit`, source.table, source.column, source.where)
+	if err := tx.Raw(query, map[string]interface{}{
+		"q":      q,
+		"prefix": escapeLike(q) + "%",
+		"limit":  limit,
+	}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Suggestion, len(rows))
+	for i, row := range rows {
+		result[i] = dto.Suggestion{Type: suggestionType, ID: row.ID, Text: row.Text, Score: row.Score}
+	}
+	return result, nil
+}
+
+// suggestTags сначала отбирает события по индексу на склеенных тегах, затем выбирает подходящие теги из массива
+func (h *SearchHandler) suggestTags(tx *gorm.DB, q string, limit int) ([]dto.Suggestion, error) {
+	var rows []struct {
+		Text  string
+		Score float64
+	}
+	if err := tx.Raw(`SELECT tag AS text, MAX(word_similarity(@q, tag)) AS score
+		FROM events, unnest(tags) AS tag
+		WHERE events.deleted_at IS NULL AND events.status = @status AND events.visibility = @visibility
+			AND (event_tags_to_text(tags) ILIKE @contains OR @q <% event_tags_to_text(tags))
+			AND (tag ILIKE @prefix OR @q <% tag)
+		GROUP BY tag
+		ORDER BY score DESC, COUNT(*) DESC
+		LIMIT @limit`, map[string]interface{}{
+		"q":          q,
+		"status":     string(models.EventStatusActive),
+		"visibility": string(models.EventVisibilityPublic),
+		"contains":   "%" + escapeLike(q) + "%",
+		"prefix":     escapeLike(q) + "%",
+		"limit":      limit,
+	}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Suggestion, len(rows))
+	for i, row := range rows {
+		result[i] = dto.Suggestion{Type: dto.SuggestionTypeTag, Text: row.Text, Score: row.Score}
+	}
+	return result, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
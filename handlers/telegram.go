@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"bekend/config"
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/services"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TelegramHandler struct {
+	telegramService *services.TelegramService
+	logger          *zap.Logger
+}
+
+func NewTelegramHandler() *TelegramHandler {
+	return &TelegramHandler{
+		telegramService: services.NewTelegramService(),
+		logger:          utils.GetLogger(),
+	}
+}
+
+// CreateLink godoc
+// @Summary Получить ссылку для подключения Telegram-бота
+// @Description Создает одноразовую deep-link ссылку вида https://t.me/<bot>?start=<token>. После перехода по ссылке и нажатия "Start" чат привязывается к аккаунту.
+// @Tags Пользователь
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TelegramLinkResponse "Ссылка для привязки"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 503 {object} map[string]string "Telegram-бот не настроен"
+// @Router /user/telegram/link [post]
+func (h *TelegramHandler) CreateLink(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	linkToken, link, err := h.telegramService.CreateLinkToken(userID.(uuid.UUID))
+	if err != nil {
+		if errors.Is(err, services.ErrTelegramNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Telegram-бот не настроен"})
+			return
+		}
+		h.logger.Error("Ошибка создания токена привязки Telegram", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании ссылки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TelegramLinkResponse{
+		Link:      link,
+		ExpiresAt: linkToken.ExpiresAt,
+	})
+}
+
+// Unlink godoc
+// @Summary Отключить Telegram-бота
+// @Description Отвязывает чат Telegram от аккаунта, уведомления в Telegram больше не отправляются
+// @Tags Пользователь
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "Telegram отключен"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /user/telegram/link [delete]
+func (h *TelegramHandler) Unlink(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Update("telegram_chat_id", nil).Error; err != nil {
+		h.logger.Error("Ошибка отвязки Telegram", zap.Any("userID", userID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отключении Telegram"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Telegram отключен"})
+}
+
+// Webhook godoc
+// @Summary Вебхук Telegram Bot API
+// @Description Принимает обновления от Telegram (команды /start, /stop и нажатия inline-кнопок). Заголовок X-Telegram-Bot-Api-Secret-Token должен совпадать с TELEGRAM_WEBHOOK_SECRET; без секрета обновления принимаются только фейковым ботом.
+// @Tags Telegram
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]bool "Обновление принято"
+// @Failure 403 {object} map[string]string "Неверный секрет"
+// @Router /telegram/webhook [post]
+func (h *TelegramHandler) Webhook(c *gin.Context) {
+	secret := config.AppConfig.TelegramWebhookSecret
+	header := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+	if (secret == "" && !config.AppConfig.FakeTelegramBot) || subtle.ConstantTimeCompare([]byte(header), []byte(secret)) != 1 {
+		h.logger.Warn("Вебхук Telegram с неверным секретом", zap.String("ip", c.ClientIP()))
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	var update dto.TelegramUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		// Telegram повторяет доставку при ответе не 2xx, поэтому некорректные обновления просто пропускаем
+		h.logger.Warn("Некорректное обновление Telegram", zap.Error(err))
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+		return
+	}
+
+	h.telegramService.HandleUpdate(&update)
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
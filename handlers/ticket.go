@@ -0,0 +1,652 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/services"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TicketHandler struct {
+	orderService *services.OrderService
+	logger       *zap.Logger
+}
+
+func NewTicketHandler() *TicketHandler {
+	return &TicketHandler{
+		orderService: services.NewOrderService(),
+		logger:       utils.GetLogger(),
+	}
+}
+
+// GetTicketTypes godoc
+// @Summary Билеты события
+// @Description Типы билетов с ценой в копейках, остатком и признаком открытой продажи
+// @Tags Билеты
+// @Produce json
+// @Param id path string true "UUID события"
+// @Param token query string false "Токен ссылки или приглашения для событий с ограниченной видимостью"
+// @Success 200 {array} dto.TicketTypeResponse "Типы билетов"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/ticket-types [get]
+func (h *TicketHandler) GetTicketTypes(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.Select(append(eventAccessColumns, "end_date")).Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	if !canViewEvent(c, &event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+
+	response, err := eventTicketTypes(&event)
+	if err != nil {
+		h.logger.Error("Ошибка получения билетов события", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении билетов"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateTicketType godoc
+// @Summary Добавить тип билета
+// @Description Доступно организатору и администратору. Цена в копейках, 0 - бесплатный билет. На событие с билетами записываются только через покупку билета. Нельзя для событий с одобрением заявок
+// @Tags Билеты
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param request body dto.CreateTicketTypeRequest true "Тип билета"
+// @Success 201 {object} dto.TicketTypeResponse "Тип билета создан"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/ticket-types [post]
+func (h *TicketHandler) CreateTicketType(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateTicketTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при создании типа билета", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if event.RequiresApproval {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "На событие с одобрением заявок нельзя продавать билеты"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.TicketType{}).Where("event_id = ?", event.ID).Count(&count)
+	if count >= utils.MaxTicketTypes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Максимальное количество типов билетов - %d", utils.MaxTicketTypes)})
+		return
+	}
+
+	ticketType := models.TicketType{
+		EventID:     event.ID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Price:       req.Price,
+		Quota:       req.Quota,
+		SalesStart:  req.SalesStart,
+		SalesEnd:    req.SalesEnd,
+	}
+	if errMsg := validateTicketType(&ticketType, event, 0); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	if err := database.DB.Create(&ticketType).Error; err != nil {
+		h.logger.Error("Ошибка создания типа билета в БД", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании типа билета"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ticketTypeToResponse(ticketType, event, 0, time.Now()))
+}
+
+// UpdateTicketType godoc
+// @Summary Обновить тип билета
+// @Description Доступно организатору и администратору. Новая цена действует для новых заказов. Квоту нельзя сделать меньше числа проданных и забронированных билетов
+// @Tags Билеты
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param ticketTypeId path string true "UUID типа билета"
+// @Param request body dto.UpdateTicketTypeRequest true "Изменяемые поля"
+// @Success 200 {object} dto.TicketTypeResponse "Тип билета обновлен"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Тип билета не найден"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/ticket-types/{ticketTypeId} [put]
+func (h *TicketHandler) UpdateTicketType(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	ticketType, ok := loadTicketType(c, event.ID)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateTicketTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при обновлении типа билета", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if req.Name != nil {
+		ticketType.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		ticketType.Description = *req.Description
+	}
+	if req.Price != nil {
+		ticketType.Price = *req.Price
+	}
+	if req.Quota != nil {
+		ticketType.Quota = req.Quota
+		if *req.Quota == 0 {
+			ticketType.Quota = nil
+		}
+	}
+	if req.SalesStart != nil {
+		ticketType.SalesStart = req.SalesStart
+	}
+	if req.SalesEnd != nil {
+		ticketType.SalesEnd = req.SalesEnd
+	}
+
+	taken, err := services.TakenTickets(database.DB, event.ID)
+	if err != nil {
+		h.logger.Error("Ошибка подсчета проданных билетов", zap.String("ticketTypeID", ticketType.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении типа билета"})
+		return
+	}
+	if errMsg := validateTicketType(ticketType, event, taken[ticketType.ID]); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	if err := database.DB.Save(ticketType).Error; err != nil {
+		h.logger.Error("Ошибка обновления типа билета", zap.String("ticketTypeID", ticketType.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении типа билета"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ticketTypeToResponse(*ticketType, event, taken[ticketType.ID], time.Now()))
+}
+
+// DeleteTicketType godoc
+// @Summary Удалить тип билета
+// @Description Доступно организатору и администратору. Тип билета с оплаченными или забронированными заказами удалить нельзя
+// @Tags Билеты
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param ticketTypeId path string true "UUID типа билета"
+// @Success 200 {object} map[string]string "Тип билета удален"
+// @Failure 400 {object} map[string]string "Есть заказы этого типа"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Тип билета не найден"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/ticket-types/{ticketTypeId} [delete]
+func (h *TicketHandler) DeleteTicketType(c *gin.Context) {
+	event, ok := loadOwnEvent(c)
+	if !ok {
+		return
+	}
+	ticketType, ok := loadTicketType(c, event.ID)
+	if !ok {
+		return
+	}
+
+	taken, err := services.TakenTickets(database.DB, event.ID)
+	if err != nil {
+		h.logger.Error("Ошибка подсчета проданных билетов", zap.String("ticketTypeID", ticketType.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении типа билета"})
+		return
+	}
+	if taken[ticketType.ID] > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Билеты этого типа уже проданы или забронированы, остановите продажу вместо удаления"})
+		return
+	}
+
+	if err := database.DB.Delete(ticketType).Error; err != nil {
+		h.logger.Error("Ошибка удаления типа билета", zap.String("ticketTypeID", ticketType.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении типа билета"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Тип билета удален"})
+}
+
+// GetEventOrders godoc
+// @Summary Заказы билетов события
+// @Description Доступно организатору и администратору. Новые заказы первыми
+// @Tags Билеты
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID события"
+// @Param status query string false "Фильтр по статусу: pending, paid, refunded, expired"
+// @Success 200 {array} dto.OrderResponse "Заказы"
+// @Failure 400 {object} map[string]string "Неверный формат ID или статуса"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Событие не найдено"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/{id}/orders [get]
+func (h *TicketHandler) GetEventOrders(c *gin.Context) {
+	eventID := c.Param("id")
+	if !utils.ValidateUUID(eventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID события"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	var event models.Event
+	if err := database.DB.Select("id", "title", "organizer_id").Where("id = ?", eventID).First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Событие не найдено"})
+		return
+	}
+	if event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	query := database.DB.Preload("TicketType").Preload("User").Where("event_id = ?", event.ID)
+	if status := c.Query("status"); status != "" {
+		if !models.IsValidOrderStatus(models.OrderStatus(status)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный статус заказа"})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []models.TicketOrder
+	if err := query.Order("created_at DESC").Find(&orders).Error; err != nil {
+		h.logger.Error("Ошибка получения заказов события", zap.String("eventID", eventID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заказов"})
+		return
+	}
+
+	response := make([]dto.OrderResponse, len(orders))
+	for i := range orders {
+		orders[i].Event = event
+		response[i] = orderToResponse(orders[i], true)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMyOrders godoc
+// @Summary Мои заказы билетов
+// @Description Заказы текущего пользователя, новые первыми
+// @Tags Билеты
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.OrderResponse "Заказы"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /orders [get]
+func (h *TicketHandler) GetMyOrders(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var orders []models.TicketOrder
+	if err := database.DB.Preload("TicketType").Preload("Event").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		h.logger.Error("Ошибка получения заказов пользователя", zap.Any("userID", userID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заказов"})
+		return
+	}
+
+	response := make([]dto.OrderResponse, len(orders))
+	for i := range orders {
+		response[i] = orderToResponse(orders[i], false)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOrder godoc
+// @Summary Заказ билета
+// @Description Доступно покупателю, организатору события и администратору. Статус неоплаченного заказа уточняется у платежного провайдера, поэтому страница возврата после оплаты может опрашивать этот метод
+// @Tags Билеты
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID заказа"
+// @Success 200 {object} dto.OrderResponse "Заказ"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 404 {object} map[string]string "Заказ не найден"
+// @Router /orders/{id} [get]
+func (h *TicketHandler) GetOrder(c *gin.Context) {
+	order, ok := loadOrder(c)
+	if !ok {
+		return
+	}
+
+	if err := h.orderService.RefreshOrder(order); err != nil {
+		// Статус уточнится по уведомлению провайдера или при следующем запросе
+		h.logger.Warn("Не удалось уточнить статус платежа", zap.String("orderID", order.ID.String()), zap.Error(err))
+	}
+
+	userID, _ := c.Get("userID")
+	c.JSON(http.StatusOK, orderToResponse(*order, order.UserID != userID.(uuid.UUID)))
+}
+
+// RefundOrder godoc
+// @Summary Вернуть оплату заказа
+// @Description Доступно организатору события и администратору. Деньги возвращаются покупателю через платежного провайдера, участие в событии отменяется
+// @Tags Билеты
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID заказа"
+// @Success 200 {object} dto.OrderResponse "Оплата возвращена"
+// @Failure 400 {object} map[string]string "Заказ не оплачен"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Заказ не найден"
+// @Failure 500 {object} map[string]string "Ошибка возврата у платежного провайдера"
+// @Router /orders/{id}/refund [post]
+func (h *TicketHandler) RefundOrder(c *gin.Context) {
+	order, ok := loadOrder(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if order.Event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return
+	}
+
+	err := h.orderService.Refund(order, "Возврат оформлен организатором.")
+	switch {
+	case errors.Is(err, services.ErrOrderNotPaid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Вернуть можно только оплаченный заказ"})
+		return
+	case err != nil:
+		h.logger.Error("Ошибка возврата оплаты", zap.String("orderID", order.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при возврате оплаты"})
+		return
+	}
+
+	c.JSON(http.StatusOK, orderToResponse(*order, true))
+}
+
+// PaymentWebhook godoc
+// @Summary Уведомление платежного провайдера
+// @Description Принимает уведомления о платежах. Статус платежа перепроверяется запросом к провайдеру, поэтому подделать оплату уведомлением нельзя. При ответе не 2xx провайдер повторяет доставку
+// @Tags Билеты
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]bool "Уведомление принято"
+// @Failure 500 {object} map[string]string "Уведомление не обработано, провайдер повторит доставку"
+// @Router /payments/webhook [post]
+func (h *TicketHandler) PaymentWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	err = h.orderService.ConfirmPayment(body)
+	switch {
+	case errors.Is(err, services.ErrPaymentNotFound) || errors.Is(err, services.ErrPaymentsNotConfigured):
+		// Повторная доставка не поможет, поэтому уведомление принимается
+		h.logger.Warn("Уведомление о неизвестном платеже", zap.String("ip", c.ClientIP()), zap.Error(err))
+	case err != nil:
+		h.logger.Error("Ошибка обработки уведомления о платеже", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обработки уведомления"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// buyTicket оформляет заказ билета вместо прямой записи на событие с билетами
+func (h *EventHandler) buyTicket(c *gin.Context, event *models.Event, ticketTypeID *uuid.UUID, answers []models.RegistrationAnswer, invitation *models.EventInvitation) {
+	userID, _ := c.Get("userID")
+
+	if ticketTypeID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Выберите тип билета"})
+		return
+	}
+	var ticketType models.TicketType
+	if err := database.DB.Where("id = ? AND event_id = ?", *ticketTypeID, event.ID).First(&ticketType).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Тип билета не найден"})
+		return
+	}
+	if !ticketOnSale(&ticketType, event, time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Продажа билетов этого типа закрыта"})
+		return
+	}
+
+	order, err := h.orderService.PlaceOrder(event, &ticketType, userID.(uuid.UUID), answers)
+	switch {
+	case errors.Is(err, services.ErrTicketsSoldOut):
+		c.JSON(http.StatusConflict, gin.H{"error": "Билеты этого типа закончились"})
+		return
+	case errors.Is(err, services.ErrNoSeatsLeft):
+		c.JSON(http.StatusConflict, gin.H{"error": "Достигнут максимальный лимит участников"})
+		return
+	case errors.Is(err, services.ErrPaymentsNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Оплата билетов временно недоступна"})
+		return
+	case err != nil:
+		h.logger.Error("Ошибка оформления заказа билета", zap.String("eventID", event.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при оформлении билета"})
+		return
+	}
+
+	if invitation != nil {
+		if err := acceptInvitation(database.DB, invitation, order.UserID); err != nil {
+			h.logger.Error("Ошибка принятия приглашения при покупке билета", zap.String("orderID", order.ID.String()), zap.Error(err))
+		}
+	}
+
+	order.Event = *event
+	if order.TicketTypeID == ticketType.ID {
+		order.TicketType = ticketType
+	} else {
+		database.DB.Where("id = ?", order.TicketTypeID).First(&order.TicketType)
+	}
+
+	message := "Место забронировано, оплатите билет"
+	if order.Status == models.OrderStatusPaid {
+		message = "Участие успешно подтверждено"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"order":   orderToResponse(*order, false),
+	})
+}
+
+// eventTicketTypes возвращает типы билетов события с остатками. Для события без билетов - пустой список
+func eventTicketTypes(event *models.Event) ([]dto.TicketTypeResponse, error) {
+	var ticketTypes []models.TicketType
+	if err := database.DB.Where("event_id = ?", event.ID).Order("price ASC, created_at ASC").Find(&ticketTypes).Error; err != nil {
+		return nil, err
+	}
+	response := make([]dto.TicketTypeResponse, len(ticketTypes))
+	if len(ticketTypes) == 0 {
+		return response, nil
+	}
+
+	taken, err := services.TakenTickets(database.DB, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i, ticketType := range ticketTypes {
+		response[i] = ticketTypeToResponse(ticketType, event, taken[ticketType.ID], now)
+	}
+	return response, nil
+}
+
+// validateTicketType проверяет тип билета перед сохранением; taken - уже проданные и забронированные билеты
+func validateTicketType(ticketType *models.TicketType, event *models.Event, taken int) string {
+	if !utils.ValidateStringLength(ticketType.Name, 1, utils.MaxTicketTypeNameLength) {
+		return fmt.Sprintf("Название билета должно быть от 1 до %d символов", utils.MaxTicketTypeNameLength)
+	}
+	if !utils.ValidateStringLength(ticketType.Description, 0, utils.MaxTicketTypeDescription) {
+		return fmt.Sprintf("Описание билета должно быть до %d символов", utils.MaxTicketTypeDescription)
+	}
+	if ticketType.Price < 0 || ticketType.Price > utils.MaxTicketPrice {
+		return fmt.Sprintf("Цена билета должна быть от 0 до %d копеек", utils.MaxTicketPrice)
+	}
+	if ticketType.Quota != nil {
+		if *ticketType.Quota <= 0 {
+			return "Количество билетов должно быть положительным"
+		}
+		if *ticketType.Quota < taken {
+			return fmt.Sprintf("Уже продано или забронировано %d билетов, количество не может быть меньше", taken)
+		}
+	}
+	if ticketType.SalesStart != nil && ticketType.SalesEnd != nil && !ticketType.SalesEnd.After(*ticketType.SalesStart) {
+		return "Окончание продаж должно быть позже начала"
+	}
+	if ticketType.SalesEnd != nil && ticketType.SalesEnd.After(event.EndDate) {
+		return "Продажа билетов должна закончиться до окончания события"
+	}
+	return ""
+}
+
+// ticketOnSale - открыта ли продажа билета: событие активно и сейчас идет окно продаж.
+// Остаток проверяется при бронировании
+func ticketOnSale(ticketType *models.TicketType, event *models.Event, now time.Time) bool {
+	if event.Status != models.EventStatusActive {
+		return false
+	}
+	if ticketType.SalesStart != nil && now.Before(*ticketType.SalesStart) {
+		return false
+	}
+	salesEnd := event.EndDate
+	if ticketType.SalesEnd != nil {
+		salesEnd = *ticketType.SalesEnd
+	}
+	return now.Before(salesEnd)
+}
+
+func ticketTypeToResponse(ticketType models.TicketType, event *models.Event, taken int, now time.Time) dto.TicketTypeResponse {
+	response := dto.TicketTypeResponse{
+		ID:          ticketType.ID.String(),
+		Name:        ticketType.Name,
+		Description: ticketType.Description,
+		Price:       ticketType.Price,
+		Currency:    utils.TicketCurrency,
+		Quota:       ticketType.Quota,
+		OnSale:      ticketOnSale(&ticketType, event, now),
+		SalesStart:  ticketType.SalesStart,
+		SalesEnd:    ticketType.SalesEnd,
+	}
+	if ticketType.Quota != nil {
+		available := max(*ticketType.Quota-taken, 0)
+		response.Available = &available
+		response.OnSale = response.OnSale && available > 0
+	}
+	return response
+}
+
+// loadTicketType загружает тип билета события из пути. При ошибке ответ уже отправлен
+func loadTicketType(c *gin.Context, eventID uuid.UUID) (*models.TicketType, bool) {
+	ticketTypeID := c.Param("ticketTypeId")
+	if !utils.ValidateUUID(ticketTypeID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID типа билета"})
+		return nil, false
+	}
+
+	var ticketType models.TicketType
+	if err := database.DB.Where("id = ? AND event_id = ?", ticketTypeID, eventID).First(&ticketType).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Тип билета не найден"})
+		return nil, false
+	}
+	return &ticketType, true
+}
+
+// loadOrder загружает заказ из пути, доступный покупателю, организатору события и администратору.
+// Чужой заказ выглядит как несуществующий. При ошибке ответ уже отправлен
+func loadOrder(c *gin.Context) (*models.TicketOrder, bool) {
+	orderID := c.Param("id")
+	if !utils.ValidateUUID(orderID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID заказа"})
+		return nil, false
+	}
+
+	var order models.TicketOrder
+	if err := database.DB.Preload("TicketType").Preload("Event").Preload("User").Where("id = ?", orderID).First(&order).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Заказ не найден"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("userID")
+	if order.UserID != userID.(uuid.UUID) && order.Event.OrganizerID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Заказ не найден"})
+		return nil, false
+	}
+	return &order, true
+}
+
+func orderToResponse(order models.TicketOrder, withUser bool) dto.OrderResponse {
+	response := dto.OrderResponse{
+		ID:             order.ID.String(),
+		EventID:        order.EventID.String(),
+		EventTitle:     order.Event.Title,
+		TicketTypeID:   order.TicketTypeID.String(),
+		TicketTypeName: order.TicketType.Name,
+		Amount:         order.Amount,
+		Currency:       utils.TicketCurrency,
+		Status:         string(order.Status),
+		ExpiresAt:      order.ExpiresAt,
+		PaidAt:         order.PaidAt,
+		RefundedAt:     order.RefundedAt,
+		CreatedAt:      order.CreatedAt,
+	}
+	if order.Status == models.OrderStatusPending {
+		response.ConfirmationURL = order.ConfirmationURL
+	}
+	if withUser {
+		response.User = &dto.UserInfo{
+			ID:       order.User.ID.String(),
+			FullName: order.User.FullName,
+			Email:    order.User.Email,
+			Role:     string(order.User.Role),
+		}
+	}
+	return response
+}
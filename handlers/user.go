@@ -55,7 +55,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := database.DB.Preload("City").Where("id = ?", userID).First(&user).Error; err != nil {
 		h.logger.Error("Пользователь не найден", zap.String("userID", userID.String()), zap.Error(err))
 		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
 		return
@@ -89,8 +89,10 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		"birthDate":  "",
 		"image":      user.AvatarURL,
 		"telegram":   user.Telegram,
+		"telegramLinked": user.TelegramChatID != nil,
 		"role":       string(user.Role),
 		"fullName":   user.FullName,
+		"city":       cityInfo(user.City),
 	})
 }
 
@@ -104,6 +106,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 // @Param fullName formData string false "ФИО пользователя (только русские буквы, 2-100 символов)"
 // @Param telegram formData string false "Telegram username (5-32 символа, латиница, цифры, подчеркивания)"
 // @Param avatar formData file false "Аватар пользователя (jpg, jpeg, png, gif, webp, до 10MB)"
+// @Param cityID formData string false "UUID города по умолчанию для ленты событий (пустое значение сбрасывает)"
 // @Success 200 {object} map[string]interface{} "Профиль обновлен"
 // @Failure 400 {object} map[string]string "Ошибка валидации"
 // @Failure 401 {object} map[string]string "Требуется авторизация"
@@ -165,6 +168,16 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		updated = true
 	}
 
+	// Город по умолчанию для ленты событий; пустое значение сбрасывает его
+	if cityIDValue, ok := c.GetPostForm("cityID"); ok {
+		cityID, valid := parseCityID(c, cityIDValue)
+		if !valid {
+			return
+		}
+		user.CityID = cityID
+		updated = true
+	}
+
 	fileHeader, err := c.FormFile("avatar")
 	if err == nil {
 		if fileHeader.Size > utils.MaxAvatarFileSize {
@@ -266,6 +279,12 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении профиля"})
 		return
 	}
+	if user.CityID != nil {
+		var city models.City
+		if err := database.DB.Where("id = ?", *user.CityID).First(&city).Error; err == nil {
+			user.City = &city
+		}
+	}
 
 	var userInterests []models.UserInterest
 	if err := database.DB.Preload("Interest").Where("user_id = ?", userID).Find(&userInterests).Error; err != nil {
@@ -291,8 +310,10 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 			"birthDate":  "",
 			"image":      user.AvatarURL,
 			"telegram":   user.Telegram,
+			"telegramLinked": user.TelegramChatID != nil,
 			"role":       string(user.Role),
 			"fullName":   user.FullName,
+			"city":       cityInfo(user.City),
 		},
 	})
 }
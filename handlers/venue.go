@@ -0,0 +1,582 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/services"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type VenueHandler struct {
+	geocoderService *services.GeocoderService
+	logger          *zap.Logger
+}
+
+func NewVenueHandler() *VenueHandler {
+	return &VenueHandler{
+		geocoderService: services.NewGeocoderService(),
+		logger:          utils.GetLogger(),
+	}
+}
+
+// GetVenues godoc
+// @Summary Получить список площадок
+// @Description Поиск площадок по названию и адресу с пагинацией
+// @Tags Площадки
+// @Produce json
+// @Param search query string false "Поиск по названию и адресу (1-100 символов)"
+// @Param cityID query string false "UUID города"
+// @Param page query int false "Номер страницы (по умолчанию: 1)"
+// @Param limit query int false "Количество элементов на странице (по умолчанию: 20, максимум: 100)"
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.VenueResponse} "Список площадок"
+// @Failure 400 {object} map[string]string "Ошибка валидации параметров"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /venues [get]
+func (h *VenueHandler) GetVenues(c *gin.Context) {
+	pageInt := 1
+	limitInt := 20
+	if p, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && p > 0 {
+		pageInt = p
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && l > 0 && l <= 100 {
+		limitInt = l
+	}
+
+	query := database.DB.Model(&models.Venue{})
+
+	if search := c.Query("search"); search != "" {
+		if !utils.ValidateStringLength(search, 1, 100) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Поисковый запрос должен быть от 1 до 100 символов"})
+			return
+		}
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where("name ILIKE ? OR address ILIKE ?", pattern, pattern)
+	}
+
+	cityID, ok := cityFilter(c, nil, false)
+	if !ok {
+		return
+	}
+	if cityID != nil {
+		query = query.Where("city_id = ?", *cityID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.logger.Error("Ошибка подсчета площадок", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении площадок"})
+		return
+	}
+
+	var venues []models.Venue
+	if err := query.Preload("CreatedBy").
+		Order("name ASC").
+		Offset((pageInt - 1) * limitInt).Limit(limitInt).
+		Find(&venues).Error; err != nil {
+		h.logger.Error("Ошибка получения площадок", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении площадок"})
+		return
+	}
+
+	result := make([]dto.VenueResponse, len(venues))
+	for i, venue := range venues {
+		result[i] = venueToResponse(venue)
+	}
+
+	c.JSON(http.StatusOK, dto.PaginationResponse{
+		Data: result,
+		Pagination: dto.Pagination{
+			Page:       pageInt,
+			Limit:      limitInt,
+			Total:      total,
+			TotalPages: int((total + int64(limitInt) - 1) / int64(limitInt)),
+		},
+	})
+}
+
+// GetVenue godoc
+// @Summary Получить площадку
+// @Tags Площадки
+// @Produce json
+// @Param id path string true "UUID площадки"
+// @Success 200 {object} dto.VenueResponse "Площадка"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 404 {object} map[string]string "Площадка не найдена"
+// @Router /venues/{id} [get]
+func (h *VenueHandler) GetVenue(c *gin.Context) {
+	venueID := c.Param("id")
+	if !utils.ValidateUUID(venueID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID площадки"})
+		return
+	}
+
+	var venue models.Venue
+	if err := database.DB.Preload("CreatedBy").Where("id = ?", venueID).First(&venue).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Площадка не найдена"})
+		return
+	}
+
+	c.JSON(http.StatusOK, venueToResponse(venue))
+}
+
+// CreateVenue godoc
+// @Summary Создать площадку
+// @Description Если координаты не указаны, они определяются по адресу геокодером
+// @Tags Площадки
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateVenueRequest true "Данные площадки"
+// @Success 201 {object} dto.VenueResponse "Площадка создана"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /venues [post]
+func (h *VenueHandler) CreateVenue(c *gin.Context) {
+	var req dto.CreateVenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при создании площадки", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	venue := models.Venue{
+		Name:              req.Name,
+		Description:       req.Description,
+		Address:           req.Address,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		Capacity:          req.Capacity,
+		AccessibilityInfo: req.AccessibilityInfo,
+		Photos:            models.StringArray(req.Photos),
+		CreatedByID:       userID.(uuid.UUID),
+	}
+	if venue.Photos == nil {
+		venue.Photos = models.StringArray{}
+	}
+	if req.CityID != nil {
+		cityID, ok := parseCityID(c, *req.CityID)
+		if !ok {
+			return
+		}
+		venue.CityID = cityID
+	}
+	if errMsg := validateVenue(&venue); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	if errMsg := h.locateVenue(c, &venue); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	if err := database.DB.Create(&venue).Error; err != nil {
+		h.logger.Error("Ошибка создания площадки в БД", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании площадки"})
+		return
+	}
+
+	database.DB.Where("id = ?", venue.CreatedByID).First(&venue.CreatedBy)
+	c.JSON(http.StatusCreated, venueToResponse(venue))
+}
+
+// UpdateVenue godoc
+// @Summary Обновить площадку
+// @Description Доступно создателю площадки и администратору. Новые адрес, координаты и город переносятся в активные события площадки
+// @Tags Площадки
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID площадки"
+// @Param request body dto.UpdateVenueRequest true "Данные для обновления"
+// @Success 200 {object} dto.VenueResponse "Площадка обновлена"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Площадка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /venues/{id} [put]
+func (h *VenueHandler) UpdateVenue(c *gin.Context) {
+	venue, ok := h.loadOwnVenue(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateVenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Неверные данные при обновлении площадки", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if req.Name != "" {
+		venue.Name = req.Name
+	}
+	if req.Description != nil {
+		venue.Description = *req.Description
+	}
+	if req.Capacity != nil {
+		venue.Capacity = req.Capacity
+	}
+	if req.AccessibilityInfo != nil {
+		venue.AccessibilityInfo = *req.AccessibilityInfo
+	}
+	if req.Photos != nil {
+		venue.Photos = models.StringArray(req.Photos)
+	}
+
+	// Новый адрес без координат определяется геокодером заново
+	addressChanged := req.Address != "" && req.Address != venue.Address
+	if addressChanged {
+		venue.Address = req.Address
+		if req.Latitude == nil || req.Longitude == nil {
+			venue.Latitude = nil
+			venue.Longitude = nil
+		}
+	}
+	if req.Latitude != nil && req.Longitude != nil {
+		venue.Latitude = req.Latitude
+		venue.Longitude = req.Longitude
+	}
+
+	if errMsg := validateVenue(venue); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	if req.CityID != nil {
+		cityID, ok := parseCityID(c, *req.CityID)
+		if !ok {
+			return
+		}
+		venue.CityID = cityID
+	}
+	locationChanged := addressChanged || req.Latitude != nil || req.Longitude != nil || req.CityID != nil
+	if addressChanged || req.Latitude != nil || req.Longitude != nil {
+		// Город новой точки определяется заново, если не указан явно
+		if req.CityID == nil {
+			venue.CityID = nil
+		}
+		if errMsg := h.locateVenue(c, venue); errMsg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+	}
+
+	if err := database.DB.Save(venue).Error; err != nil {
+		h.logger.Error("Ошибка обновления площадки", zap.String("venueID", venue.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении площадки"})
+		return
+	}
+	if locationChanged {
+		h.syncVenueEvents(venue)
+	}
+
+	c.JSON(http.StatusOK, venueToResponse(*venue))
+}
+
+// DeleteVenue godoc
+// @Summary Удалить площадку
+// @Description Доступно создателю площадки и администратору. События площадки сохраняют свой адрес
+// @Tags Площадки
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID площадки"
+// @Success 200 {object} map[string]string "Площадка удалена"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Failure 403 {object} map[string]string "Доступ запрещен"
+// @Failure 404 {object} map[string]string "Площадка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /venues/{id} [delete]
+func (h *VenueHandler) DeleteVenue(c *gin.Context) {
+	venue, ok := h.loadOwnVenue(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Event{}).Where("venue_id = ?", venue.ID).Update("venue_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(venue).Error
+	})
+	if err != nil {
+		h.logger.Error("Ошибка удаления площадки", zap.String("venueID", venue.ID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении площадки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Площадка удалена"})
+}
+
+// GetVenueEvents godoc
+// @Summary Афиша площадки
+// @Description Предстоящие активные события площадки по дате начала
+// @Tags Площадки
+// @Produce json
+// @Param id path string true "UUID площадки"
+// @Param page query int false "Номер страницы (по умолчанию: 1)"
+// @Param limit query int false "Количество элементов на странице (по умолчанию: 20, максимум: 100)"
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.EventResponse} "События площадки"
+// @Failure 400 {object} map[string]string "Неверный формат ID"
+// @Failure 404 {object} map[string]string "Площадка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /venues/{id}/events [get]
+func (h *EventHandler) GetVenueEvents(c *gin.Context) {
+	venueID := c.Param("id")
+	if !utils.ValidateUUID(venueID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID площадки"})
+		return
+	}
+
+	var venue models.Venue
+	if err := database.DB.Select("id").Where("id = ?", venueID).First(&venue).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Площадка не найдена"})
+		return
+	}
+
+	pageInt := 1
+	limitInt := 20
+	if p, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && p > 0 {
+		pageInt = p
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && l > 0 && l <= 100 {
+		limitInt = l
+	}
+
+	query := database.DB.Model(&models.Event{}).
+		Where("venue_id = ? AND status = ? AND visibility = ? AND end_date > ?", venue.ID, models.EventStatusActive, models.EventVisibilityPublic, time.Now())
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.logger.Error("Ошибка подсчета событий площадки", zap.String("venueID", venueID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении событий"})
+		return
+	}
+
+	var events []models.Event
+	if err := query.Preload("Organizer").Preload("Participants").Preload("Categories").
+		Order("start_date ASC").
+		Offset((pageInt - 1) * limitInt).Limit(limitInt).
+		Find(&events).Error; err != nil {
+		h.logger.Error("Ошибка получения событий площадки", zap.String("venueID", venueID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении событий"})
+		return
+	}
+
+	eventIDs := make([]uuid.UUID, len(events))
+	for i, event := range events {
+		eventIDs[i] = event.ID
+	}
+	isBookmarked := bookmarkFlags(c, eventIDs)
+
+	result := make([]dto.EventResponse, len(events))
+	for i, event := range events {
+		result[i] = h.eventToResponse(event)
+		result[i].IsBookmarked = isBookmarked(event.ID)
+	}
+
+	c.JSON(http.StatusOK, dto.PaginationResponse{
+		Data: result,
+		Pagination: dto.Pagination{
+			Page:       pageInt,
+			Limit:      limitInt,
+			Total:      total,
+			TotalPages: int((total + int64(limitInt) - 1) / int64(limitInt)),
+		},
+	})
+}
+
+// loadOwnVenue загружает площадку из пути и проверяет, что ее может менять текущий пользователь.
+// При ошибке ответ уже отправлен
+func (h *VenueHandler) loadOwnVenue(c *gin.Context) (*models.Venue, bool) {
+	venueID := c.Param("id")
+	if !utils.ValidateUUID(venueID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID площадки"})
+		return nil, false
+	}
+
+	var venue models.Venue
+	if err := database.DB.Preload("CreatedBy").Where("id = ?", venueID).First(&venue).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Площадка не найдена"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("userID")
+	if venue.CreatedByID != userID.(uuid.UUID) && c.GetString("role") != "Администратор" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ запрещен"})
+		return nil, false
+	}
+
+	return &venue, true
+}
+
+// locateVenue определяет координаты площадки по адресу, если они не указаны, и строит ссылки на карты.
+// Возвращает текст ошибки для клиента, если адрес не найден
+func (h *VenueHandler) locateVenue(c *gin.Context, venue *models.Venue) string {
+	if venue.Latitude == nil || venue.Longitude == nil {
+		venue.Latitude = nil
+		venue.Longitude = nil
+		result, err := h.geocoderService.Geocode(c.Request.Context(), venue.Address)
+		switch {
+		case err == nil:
+			venue.Latitude = &result.Latitude
+			venue.Longitude = &result.Longitude
+		case errors.Is(err, services.ErrAddressNotFound):
+			return "Адрес площадки не найден, укажите координаты вручную"
+		default:
+			// Без геокодера площадка сохраняется без координат
+			h.logger.Warn("Координаты площадки не определены", zap.String("address", venue.Address), zap.Error(err))
+		}
+	}
+
+	if venue.Latitude != nil && venue.Longitude != nil {
+		venue.YandexMapLink = utils.YandexMapLink(*venue.Latitude, *venue.Longitude)
+		venue.TwoGISMapLink = utils.TwoGISMapLink(*venue.Latitude, *venue.Longitude)
+		venue.OSMMapLink = utils.OSMMapLink(*venue.Latitude, *venue.Longitude)
+		services.AssignVenueCity(venue)
+	} else {
+		venue.YandexMapLink = ""
+		venue.TwoGISMapLink = ""
+		venue.OSMMapLink = ""
+	}
+	return ""
+}
+
+// validateVenue проверяет поля площадки и возвращает текст ошибки для клиента
+func validateVenue(venue *models.Venue) string {
+	if !utils.ValidateStringLength(venue.Name, 1, utils.MaxVenueNameLength) {
+		return fmt.Sprintf("Название площадки должно быть от 1 до %d символов", utils.MaxVenueNameLength)
+	}
+	if !utils.ValidateStringLength(venue.Address, 1, utils.MaxAddressLength) {
+		return fmt.Sprintf("Адрес должен быть от 1 до %d символов", utils.MaxAddressLength)
+	}
+	if !utils.ValidateStringLength(venue.Description, 0, utils.MaxFullDescriptionLength) {
+		return fmt.Sprintf("Описание площадки должно быть до %d символов", utils.MaxFullDescriptionLength)
+	}
+	if !utils.ValidateStringLength(venue.AccessibilityInfo, 0, utils.MaxAccessibilityInfoLength) {
+		return fmt.Sprintf("Информация о доступности должна быть до %d символов", utils.MaxAccessibilityInfoLength)
+	}
+	if venue.Capacity != nil && *venue.Capacity < 1 {
+		return "Вместимость площадки должна быть больше 0"
+	}
+	if venue.Latitude != nil && (*venue.Latitude < -90 || *venue.Latitude > 90) {
+		return "Широта должна быть от -90 до 90"
+	}
+	if venue.Longitude != nil && (*venue.Longitude < -180 || *venue.Longitude > 180) {
+		return "Долгота должна быть от -180 до 180"
+	}
+	if len(venue.Photos) > utils.MaxVenuePhotos {
+		return fmt.Sprintf("Максимальное количество фотографий площадки - %d", utils.MaxVenuePhotos)
+	}
+	for _, photo := range venue.Photos {
+		if !utils.ValidateStringLength(photo, 1, utils.MaxMapLinkLength) {
+			return fmt.Sprintf("Ссылка на фотографию должна быть от 1 до %d символов", utils.MaxMapLinkLength)
+		}
+	}
+	return ""
+}
+
+// applyVenue копирует в событие адрес, координаты и город площадки. Если лимит участников не задан,
+// им становится вместимость площадки
+func applyVenue(event *models.Event, venue *models.Venue) {
+	event.VenueID = &venue.ID
+	applyVenueLocation(event, venue)
+	if event.MaxParticipants == nil && venue.Capacity != nil {
+		capacity := *venue.Capacity
+		event.MaxParticipants = &capacity
+	}
+}
+
+func applyVenueLocation(event *models.Event, venue *models.Venue) {
+	event.Address = venue.Address
+	event.Latitude = venue.Latitude
+	event.Longitude = venue.Longitude
+	event.YandexMapLink = venue.YandexMapLink
+	event.CityID = venue.CityID
+}
+
+// syncVenueEvents переносит новое место площадки в ее активные события. Прошедшие и отмененные события
+// сохраняют адрес, по которому проходили. Адрес без координат встает в очередь геокодинга событий
+func (h *VenueHandler) syncVenueEvents(venue *models.Venue) {
+	var events []models.Event
+	if err := database.DB.Where("venue_id = ? AND status = ?", venue.ID, models.EventStatusActive).Find(&events).Error; err != nil {
+		h.logger.Error("Ошибка поиска событий площадки", zap.String("venueID", venue.ID.String()), zap.Error(err))
+		return
+	}
+
+	for _, event := range events {
+		applyVenueLocation(&event, venue)
+		services.PrepareLocation(&event)
+		if err := database.DB.Model(&event).
+			Select("address", "latitude", "longitude", "yandex_map_link", "two_gis_map_link", "osm_map_link", "city_id", "geocode_status").
+			Updates(&event).Error; err != nil {
+			h.logger.Error("Ошибка обновления места события",
+				zap.String("venueID", venue.ID.String()),
+				zap.String("eventID", event.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func venueInfo(venue *models.Venue) *dto.VenueInfo {
+	if venue == nil {
+		return nil
+	}
+	return &dto.VenueInfo{
+		ID:                venue.ID.String(),
+		Name:              venue.Name,
+		Capacity:          venue.Capacity,
+		AccessibilityInfo: venue.AccessibilityInfo,
+	}
+}
+
+func optionalIDString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func venueToResponse(venue models.Venue) dto.VenueResponse {
+	photos := []string(venue.Photos)
+	if photos == nil {
+		photos = []string{}
+	}
+	return dto.VenueResponse{
+		ID:                venue.ID.String(),
+		Name:              venue.Name,
+		Description:       venue.Description,
+		Address:           venue.Address,
+		Latitude:          venue.Latitude,
+		Longitude:         venue.Longitude,
+		YandexMapLink:     venue.YandexMapLink,
+		TwoGISMapLink:     venue.TwoGISMapLink,
+		OSMMapLink:        venue.OSMMapLink,
+		Capacity:          venue.Capacity,
+		AccessibilityInfo: venue.AccessibilityInfo,
+		Photos:            photos,
+		CityID:            optionalIDString(venue.CityID),
+		CreatedBy: dto.UserInfo{
+			ID:       venue.CreatedBy.ID.String(),
+			FullName: venue.CreatedBy.FullName,
+			Email:    venue.CreatedBy.Email,
+		},
+		CreatedAt: venue.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/services"
+	"bekend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	logger         *zap.Logger
+}
+
+func NewWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: services.NewWebhookService(),
+		logger:         utils.GetLogger(),
+	}
+}
+
+// GetWebhooks godoc
+// @Summary Получить свои подписки на вебхуки
+// @Tags Вебхуки
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.WebhookResponse "Список подписок"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var subscriptions []models.WebhookSubscription
+	if err := database.DB.Where("owner_id = ?", userID).Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		h.logger.Error("Ошибка получения подписок на вебхуки", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении подписок"})
+		return
+	}
+
+	result := make([]dto.WebhookResponse, len(subscriptions))
+	for i, s := range subscriptions {
+		result[i] = webhookToResponse(s)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateWebhook godoc
+// @Summary Создать подписку на вебхуки
+// @Description Организатор получает вебхуки по своим событиям, администратор - по всем. Секрет для проверки HMAC-подписи возвращается только в ответе на создание.
+// @Tags Вебхуки
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateWebhookRequest true "URL, типы событий и (необязательно) секрет"
+// @Success 201 {object} dto.WebhookResponse "Подписка создана"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Требуется авторизация"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if !utils.ValidateWebhookURL(req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL должен начинаться с http:// или https://, быть до 1000 символов и указывать на публичный адрес"})
+		return
+	}
+
+	if errMsg := validateWebhookEventTypes(req.EventTypes); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	if req.Secret != "" && !utils.ValidateStringLength(req.Secret, 16, 200) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Секрет должен быть от 16 до 200 символов"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.WebhookSubscription{}).Where("owner_id = ?", userID).Count(&count)
+	if count >= utils.MaxWebhooksPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Максимальное количество подписок - " + strconv.Itoa(utils.MaxWebhooksPerUser)})
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = utils.GenerateRandomString(40)
+	}
+
+	subscription := models.WebhookSubscription{
+		OwnerID:    userID.(uuid.UUID),
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: models.StringArray(req.EventTypes),
+		IsActive:   true,
+	}
+
+	if err := database.DB.Create(&subscription).Error; err != nil {
+		h.logger.Error("Ошибка создания подписки на вебхуки", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании подписки"})
+		return
+	}
+
+	response := webhookToResponse(subscription)
+	response.Secret = secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateWebhook godoc
+// @Summary Обновить подписку на вебхуки
+// @Tags Вебхуки
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID подписки"
+// @Param request body dto.UpdateWebhookRequest true "Данные для обновления"
+// @Success 200 {object} dto.WebhookResponse "Подписка обновлена"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	subscription, ok := h.loadOwnSubscription(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if req.URL != "" {
+		if !utils.ValidateWebhookURL(req.URL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL должен начинаться с http:// или https://, быть до 1000 символов и указывать на публичный адрес"})
+			return
+		}
+		subscription.URL = req.URL
+	}
+
+	if req.EventTypes != nil {
+		if errMsg := validateWebhookEventTypes(req.EventTypes); errMsg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		subscription.EventTypes = models.StringArray(req.EventTypes)
+	}
+
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+
+	if err := database.DB.Save(subscription).Error; err != nil {
+		h.logger.Error("Ошибка обновления подписки на вебхуки", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении подписки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookToResponse(*subscription))
+}
+
+// DeleteWebhook godoc
+// @Summary Удалить подписку на вебхуки
+// @Tags Вебхуки
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID подписки"
+// @Success 200 {object} map[string]string "Подписка удалена"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	subscription, ok := h.loadOwnSubscription(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Where("subscription_id = ?", subscription.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		h.logger.Error("Ошибка удаления журнала доставок вебхука", zap.Error(err))
+	}
+
+	if err := database.DB.Delete(subscription).Error; err != nil {
+		h.logger.Error("Ошибка удаления подписки на вебхуки", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении подписки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Подписка удалена"})
+}
+
+// PingWebhook godoc
+// @Summary Отправить тестовый вебхук
+// @Description Синхронно отправляет событие ping и возвращает результат доставки
+// @Tags Вебхуки
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID подписки"
+// @Success 200 {object} dto.WebhookDeliveryResponse "Результат доставки"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Router /webhooks/{id}/ping [post]
+func (h *WebhookHandler) PingWebhook(c *gin.Context) {
+	subscription, ok := h.loadOwnSubscription(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Ping(subscription)
+	if err != nil {
+		h.logger.Error("Ошибка отправки тестового вебхука", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке тестового вебхука"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookDeliveryToResponse(*delivery))
+}
+
+// GetDeliveries godoc
+// @Summary Журнал доставок вебхука
+// @Tags Вебхуки
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID подписки"
+// @Param status query string false "Фильтр по статусу: pending, success, failed"
+// @Param page query int false "Номер страницы (по умолчанию: 1)"
+// @Param limit query int false "Количество элементов на странице (по умолчанию: 20, максимум: 100)"
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.WebhookDeliveryResponse} "Журнал доставок"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	subscription, ok := h.loadOwnSubscription(c)
+	if !ok {
+		return
+	}
+
+	page := c.DefaultQuery("page", "1")
+	limit := c.DefaultQuery("limit", "20")
+	pageInt := 1
+	limitInt := 20
+
+	if p, err := strconv.Atoi(page); err == nil && p > 0 {
+		pageInt = p
+	}
+	if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
+		limitInt = l
+	}
+
+	offset := (pageInt - 1) * limitInt
+
+	query := database.DB.Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscription.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Offset(offset).Limit(limitInt).Order("created_at DESC").Find(&deliveries).Error; err != nil {
+		h.logger.Error("Ошибка получения журнала доставок", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении журнала доставок"})
+		return
+	}
+
+	result := make([]dto.WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = webhookDeliveryToResponse(d)
+	}
+
+	totalPages := int((total + int64(limitInt) - 1) / int64(limitInt))
+	c.JSON(http.StatusOK, dto.PaginationResponse{
+		Data: result,
+		Pagination: dto.Pagination{
+			Page:       pageInt,
+			Limit:      limitInt,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// ReplayDelivery godoc
+// @Summary Повторить доставку вебхука
+// @Description Создает новую доставку с тем же содержимым и сразу отправляет ее
+// @Tags Вебхуки
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID подписки"
+// @Param deliveryId path string true "UUID доставки"
+// @Success 200 {object} dto.WebhookDeliveryResponse "Результат повторной доставки"
+// @Failure 404 {object} map[string]string "Подписка или доставка не найдена"
+// @Router /webhooks/{id}/deliveries/{deliveryId}/replay [post]
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	subscription, ok := h.loadOwnSubscription(c)
+	if !ok {
+		return
+	}
+
+	deliveryID := c.Param("deliveryId")
+	if !utils.ValidateUUID(deliveryID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID доставки"})
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := database.DB.Where("id = ? AND subscription_id = ?", deliveryID, subscription.ID).First(&original).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Доставка не найдена"})
+		return
+	}
+
+	delivery, err := h.webhookService.Replay(&original, subscription)
+	if err != nil {
+		h.logger.Error("Ошибка повторной доставки вебхука", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при повторной доставке"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookDeliveryToResponse(*delivery))
+}
+
+func (h *WebhookHandler) loadOwnSubscription(c *gin.Context) (*models.WebhookSubscription, bool) {
+	subscriptionID := c.Param("id")
+	if !utils.ValidateUUID(subscriptionID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID подписки"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("userID")
+
+	var subscription models.WebhookSubscription
+	if err := database.DB.Where("id = ? AND owner_id = ?", subscriptionID, userID).First(&subscription).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Подписка не найдена"})
+		return nil, false
+	}
+
+	return &subscription, true
+}
+
+func validateWebhookEventTypes(eventTypes []string) string {
+	if len(eventTypes) == 0 {
+		return "Необходимо указать хотя бы один тип события"
+	}
+	for _, t := range eventTypes {
+		if !models.IsValidWebhookEventType(models.WebhookEventType(t)) {
+			return "Неизвестный тип события: " + t + ". Допустимые значения: participant.joined, participant.left, event.updated, event.cancelled, review.created"
+		}
+	}
+	return ""
+}
+
+func webhookToResponse(s models.WebhookSubscription) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:         s.ID.String(),
+		URL:        s.URL,
+		EventTypes: []string(s.EventTypes),
+		IsActive:   s.IsActive,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+func webhookDeliveryToResponse(d models.WebhookDelivery) dto.WebhookDeliveryResponse {
+	return dto.WebhookDeliveryResponse{
+		ID:             d.ID.String(),
+		EventType:      string(d.EventType),
+		Payload:        d.Payload,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		NextAttemptAt:  d.NextAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
@@ -17,6 +17,8 @@
 package main
 
 import (
+	_ "time/tzdata" // База часовых поясов встраивается в бинарник: в образе alpine ее нет
+
 	_ "bekend/docs"
 	"bekend/config"
 	"bekend/database"
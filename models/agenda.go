@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Speaker - спикер события. Спикеры заводятся организатором в рамках события и назначаются на сессии программы
+type Speaker struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"eventID"`
+	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
+	Bio       string         `gorm:"type:text" json:"bio"`
+	PhotoURL  string         `gorm:"type:text" json:"photoURL"`
+	Links     StringArray    `gorm:"type:text[]" json:"links"` // Сайт, соцсети и т.п.
+	Sessions  []EventSession `gorm:"many2many:event_session_speakers;" json:"-"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+func (s *Speaker) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// EventSession - сессия программы события (доклад, мастер-класс, перерыв). Время сессии должно укладываться
+// в даты события. Capacity ограничивает число участников, добавивших сессию в личную программу
+type EventSession struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID     uuid.UUID `gorm:"type:uuid;not null;index" json:"eventID"`
+	Title       string    `gorm:"type:varchar(200);not null" json:"title"`
+	Description string    `gorm:"type:text" json:"description"`
+	StartTime   time.Time `gorm:"not null;index" json:"startTime"`
+	EndTime     time.Time `gorm:"not null" json:"endTime"`
+	Room        string    `gorm:"type:varchar(100)" json:"room"`  // Зал или аудитория
+	Track       string    `gorm:"type:varchar(100)" json:"track"` // Направление (трек) программы
+	Capacity    *int      `json:"capacity"`
+	Speakers    []Speaker `gorm:"many2many:event_session_speakers;" json:"speakers"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (s *EventSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SessionBookmark - сессия в личной программе пользователя
+type SessionBookmark struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_session_bookmark_unique" json:"userID"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_session_bookmark_unique;index" json:"sessionID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (sb *SessionBookmark) BeforeCreate(tx *gorm.DB) error {
+	if sb.ID == uuid.Nil {
+		sb.ID = uuid.New()
+	}
+	return nil
+}
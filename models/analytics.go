@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ParticipationAction string
+
+const (
+	ParticipationJoined ParticipationAction = "joined"
+	ParticipationLeft   ParticipationAction = "left"
+)
+
+// EventParticipationLog - журнал записей и отмен участия для аналитики: event_participants хранит только текущий состав
+type EventParticipationLog struct {
+	ID        uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID   uuid.UUID           `gorm:"type:uuid;not null;index:idx_participation_log_event" json:"eventID"`
+	UserID    uuid.UUID           `gorm:"type:uuid;not null;index" json:"userID"`
+	Action    ParticipationAction `gorm:"type:varchar(20);not null" json:"action"`
+	CreatedAt time.Time           `gorm:"index:idx_participation_log_event" json:"createdAt"`
+}
+
+func (pl *EventParticipationLog) BeforeCreate(tx *gorm.DB) error {
+	if pl.ID == uuid.Nil {
+		pl.ID = uuid.New()
+	}
+	return nil
+}
+
+// migrateAnalytics переносит в журнал записи участников, добавленных до его появления или в обход API (скрипты наполнения).
+// Выполняется при каждом запуске и добавляет только недостающие записи
+func migrateAnalytics(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO event_participation_logs (id, event_id, user_id, action, created_at)
+		SELECT gen_random_uuid(), p.event_id, p.user_id, ?, p.created_at
+		FROM event_participants p
+		WHERE NOT EXISTS (
+			SELECT 1 FROM event_participation_logs l
+			WHERE l.event_id = p.event_id AND l.user_id = p.user_id AND l.action = ?
+		)`, ParticipationJoined, ParticipationJoined).Error
+}
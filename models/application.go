@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApplicationStatus - статус заявки на участие в событии с одобрением
+type ApplicationStatus string
+
+const (
+	ApplicationStatusPending  ApplicationStatus = "pending"  // На рассмотрении у организатора
+	ApplicationStatusApproved ApplicationStatus = "approved" // Одобрена, пользователь стал участником
+	ApplicationStatusRejected ApplicationStatus = "rejected" // Отклонена, повторно подать нельзя
+)
+
+// EventApplication - заявка на участие в событии с RequiresApproval. Места события занимают только
+// одобренные заявки: при одобрении создается EventParticipant
+type EventApplication struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID     uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_event_application_unique" json:"eventID"`
+	UserID      uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_event_application_unique;index" json:"userID"`
+	User        User              `gorm:"foreignKey:UserID" json:"user"`
+	Message     string            `gorm:"type:text" json:"message"` // Сообщение организатору от заявителя
+	Status      ApplicationStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Reason      string            `gorm:"type:text" json:"reason"` // Причина отказа, сообщается заявителю
+	DecidedByID *uuid.UUID        `gorm:"type:uuid" json:"decidedByID"`
+	DecidedAt   *time.Time        `json:"decidedAt"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+func (a *EventApplication) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventBookmark - событие в избранном пользователя. NotifyNearlyFull включает однократное напоминание,
+// когда на событии почти не осталось мест; NearlyFullNotifiedAt фиксирует, что напоминание отправлено
+type EventBookmark struct {
+	ID                   uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID               uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_event_bookmark_unique" json:"userID"`
+	EventID              uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_event_bookmark_unique;index" json:"eventID"`
+	NotifyNearlyFull     bool       `gorm:"not null;default:false" json:"notifyNearlyFull"`
+	NearlyFullNotifiedAt *time.Time `json:"nearlyFullNotifiedAt"`
+	User                 User       `gorm:"foreignKey:UserID" json:"user"`
+	Event                Event      `gorm:"foreignKey:EventID" json:"event"`
+	CreatedAt            time.Time  `json:"createdAt"`
+	UpdatedAt            time.Time  `json:"updatedAt"`
+}
+
+func (eb *EventBookmark) BeforeCreate(tx *gorm.DB) error {
+	if eb.ID == uuid.Nil {
+		eb.ID = uuid.New()
+	}
+	return nil
+}
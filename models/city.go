@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// City - город афиши. Центр и радиус используются, чтобы определить город события или площадки
+// по координатам, а часовой пояс - для отображения времени событий
+type City struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name      string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Timezone  string    `gorm:"type:varchar(64);not null" json:"timezone"` // IANA, например Europe/Moscow
+	Latitude  float64   `gorm:"type:decimal(10,8);not null" json:"latitude"`
+	Longitude float64   `gorm:"type:decimal(11,8);not null" json:"longitude"`
+	RadiusKm  float64   `gorm:"not null;default:50" json:"radiusKm"` // Радиус от центра, в пределах которого событие относится к городу
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// migrateTimezones выставляет событиям без часового пояса пояс их города
+func migrateTimezones(db *gorm.DB) error {
+	return db.Exec(`UPDATE events SET timezone = cities.timezone FROM cities
+		WHERE events.city_id = cities.id AND events.timezone = ''`).Error
+}
+
+func (c *City) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -14,6 +14,8 @@ type MicroCommunity struct {
 	AdminID         uuid.UUID `gorm:"type:uuid;not null" json:"adminID"`
 	AutoNotify      bool      `gorm:"default:true" json:"autoNotify"`
 	MembersCount    int       `gorm:"default:0" json:"membersCount"`
+	CityID          *uuid.UUID `gorm:"type:uuid;index" json:"cityID"`
+	City            *City     `gorm:"foreignKey:CityID" json:"city,omitempty"`
 	Admin           User      `gorm:"foreignKey:AdminID" json:"admin"`
 	Members         []CommunityMember `gorm:"foreignKey:CommunityID" json:"members"`
 	Interests       []Interest `gorm:"many2many:community_interests;" json:"interests"`
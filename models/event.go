@@ -16,6 +16,28 @@ const (
 	EventStatusActive   EventStatus = "Активное"
 	EventStatusPast     EventStatus = "Прошедшее"
 	EventStatusRejected EventStatus = "Отклоненное"
+	EventStatusCancelled EventStatus = "Отмененное"
+)
+
+// GeocodeStatus - состояние координат события: указаны организатором, определены по адресу или еще нет
+type GeocodeStatus string
+
+const (
+	GeocodeStatusNone     GeocodeStatus = ""         // Адрес не указан
+	GeocodeStatusPending  GeocodeStatus = "pending"  // Адрес ждет геокодинга
+	GeocodeStatusResolved GeocodeStatus = "resolved" // Координаты определены по адресу
+	GeocodeStatusManual   GeocodeStatus = "manual"   // Координаты указал организатор
+	GeocodeStatusFailed   GeocodeStatus = "failed"   // Адрес не удалось найти
+)
+
+// EventVisibility - кто видит событие и может на него записаться
+type EventVisibility string
+
+const (
+	EventVisibilityPublic     EventVisibility = "public"      // В списках, поиске и рекомендациях
+	EventVisibilityUnlisted   EventVisibility = "unlisted"    // Только по ссылке с токеном
+	EventVisibilityInviteOnly EventVisibility = "invite_only" // Только приглашенные
+	EventVisibilityCommunity  EventVisibility = "community"   // Только участники сообщества
 )
 
 type Event struct {
@@ -25,6 +47,7 @@ type Event struct {
 	FullDescription string    `gorm:"type:text;not null" json:"fullDescription"`
 	StartDate       time.Time `gorm:"not null" json:"startDate"`
 	EndDate         time.Time `gorm:"not null" json:"endDate"`
+	Timezone        string    `gorm:"type:varchar(64);not null;default:''" json:"timezone"` // IANA; пустой - часовой пояс города или платформы
 	ImageURL        string    `gorm:"type:text" json:"imageURL"`
 	PaymentInfo     string    `gorm:"type:text" json:"paymentInfo"`
 	MaxParticipants *int      `json:"maxParticipants"`
@@ -39,6 +62,31 @@ type Event struct {
 	Latitude        *float64  `gorm:"type:decimal(10,8)" json:"latitude"` // Широта
 	Longitude       *float64  `gorm:"type:decimal(11,8)" json:"longitude"` // Долгота
 	YandexMapLink   string    `gorm:"type:text" json:"yandexMapLink"` // Ссылка на Яндекс.Карты
+	TwoGISMapLink   string    `gorm:"column:two_gis_map_link;type:text" json:"twoGISMapLink"` // Ссылка на 2ГИС
+	OSMMapLink      string    `gorm:"column:osm_map_link;type:text" json:"osmMapLink"` // Ссылка на OpenStreetMap
+	VenueID         *uuid.UUID `gorm:"type:uuid;index" json:"venueID"` // Площадка, с которой скопированы адрес и координаты
+	Venue           *Venue    `gorm:"foreignKey:VenueID" json:"venue,omitempty"`
+	CityID          *uuid.UUID `gorm:"type:uuid;index" json:"cityID"` // Город, определяется по координатам, если не указан явно
+	City            *City     `gorm:"foreignKey:CityID" json:"city,omitempty"`
+	GeocodeStatus   GeocodeStatus `gorm:"type:varchar(20);not null;default:'';index" json:"geocodeStatus"` // Определены ли координаты адреса
+	ReminderMessage string    `gorm:"type:text" json:"reminderMessage"` // Дополнительный текст организатора в напоминаниях
+	// Видимость: непубличные события не попадают в списки, поиск и рассылки сообществам
+	Visibility      EventVisibility `gorm:"type:varchar(20);not null;default:'public';index" json:"visibility"`
+	ShareToken      string    `gorm:"type:varchar(64);index" json:"-"` // Токен ссылки на событие с видимостью unlisted
+	CommunityID     *uuid.UUID `gorm:"type:uuid;index" json:"communityID"` // Сообщество, участникам которого доступно событие
+	Community       *MicroCommunity `gorm:"foreignKey:CommunityID" json:"community,omitempty"`
+	// Запись по заявкам: участником становится только одобренный организатором пользователь
+	RequiresApproval bool     `gorm:"not null;default:false" json:"requiresApproval"`
+	// Отмена события
+	CancellationReason string     `gorm:"type:text" json:"cancellationReason"`
+	CancelledAt        *time.Time `json:"cancelledAt"`
+	RescheduledTo      *time.Time `json:"rescheduledTo"` // Новая дата, если событие перенесено
+	// Заполняются только при полнотекстовом поиске (колонка search_vector создается в migrateSearch)
+	SearchRank      float64   `gorm:"->;-:migration" json:"-"`
+	TitleHighlight  string    `gorm:"->;-:migration" json:"-"`
+	SearchSnippet   string    `gorm:"->;-:migration" json:"-"`
+	// Заполняется только при геопоиске
+	DistanceKm      float64   `gorm:"->;-:migration" json:"-"`
 	CreatedAt       time.Time `json:"createdAt"`
 	UpdatedAt       time.Time `json:"updatedAt"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
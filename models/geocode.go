@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GeocodeKind string
+
+const (
+	GeocodeForward GeocodeKind = "forward" // Адрес -> координаты
+	GeocodeReverse GeocodeKind = "reverse" // Координаты -> адрес
+)
+
+// GeocodeCache - сохраненный ответ геокодера. QueryKey - нормализованный адрес для прямого геокодинга
+// или координаты, округленные до 5 знаков (около метра), для обратного
+type GeocodeCache struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Kind      GeocodeKind `gorm:"type:varchar(20);not null;uniqueIndex:idx_geocode_cache_query" json:"kind"`
+	QueryKey  string      `gorm:"type:varchar(500);not null;uniqueIndex:idx_geocode_cache_query" json:"queryKey"`
+	Latitude  float64     `gorm:"not null" json:"latitude"`
+	Longitude float64     `gorm:"not null" json:"longitude"`
+	Address   string      `gorm:"type:text;not null" json:"address"`
+	Provider  string      `gorm:"type:varchar(50);not null" json:"provider"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `gorm:"index" json:"updatedAt"`
+}
+
+// migrateGeocoding выставляет статус геокодинга событиям, созданным до его появления:
+// события с координатами считаются размеченными вручную, с адресом без координат - ждут геокодинга
+func migrateGeocoding(db *gorm.DB) error {
+	if err := db.Exec(`UPDATE events SET geocode_status = ?
+		WHERE geocode_status = '' AND latitude IS NOT NULL AND longitude IS NOT NULL`, GeocodeStatusManual).Error; err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE events SET geocode_status = ?
+		WHERE geocode_status = '' AND address <> '' AND (latitude IS NULL OR longitude IS NULL)`, GeocodeStatusPending).Error
+}
+
+func (gc *GeocodeCache) BeforeCreate(tx *gorm.DB) error {
+	if gc.ID == uuid.Nil {
+		gc.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventInvitation - приглашение на событие по email. Приглашение открывает доступ к событию с видимостью
+// invite_only: по аккаунту с этим email или по токену из письма. UserID заполняется, если адресат уже
+// зарегистрирован или принял приглашение
+type EventInvitation struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_event_invitation_email" json:"eventID"`
+	Email       string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_event_invitation_email" json:"email"`
+	UserID      *uuid.UUID `gorm:"type:uuid;index" json:"userID"`
+	Token       string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	InvitedByID uuid.UUID  `gorm:"type:uuid;not null" json:"invitedByID"`
+	AcceptedAt  *time.Time `json:"acceptedAt"` // Когда приглашенный записался на событие
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func (ei *EventInvitation) BeforeCreate(tx *gorm.DB) error {
+	if ei.ID == uuid.Nil {
+		ei.ID = uuid.New()
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package models
 import "gorm.io/gorm"
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
+		&City{},
 		&User{},
+		&Venue{},
 		&Event{},
 		&EventParticipant{},
 		&EventReview{},
@@ -20,7 +22,39 @@ func AutoMigrate(db *gorm.DB) error {
 		&CommunityInterest{},
 		&Category{},
 		&EventCategory{},
-	)
+		&TelegramLinkToken{},
+		&WebhookSubscription{},
+		&WebhookDelivery{},
+		&EventReminder{},
+		&SimilarEvent{},
+		&EventView{},
+		&EventTrendingScore{},
+		&EventParticipationLog{},
+		&EventBookmark{},
+		&GeocodeCache{},
+		&Speaker{},
+		&EventSession{},
+		&SessionBookmark{},
+		&RegistrationQuestion{},
+		&RegistrationAnswer{},
+		&EventInvitation{},
+		&EventApplication{},
+		&TicketType{},
+		&TicketOrder{},
+	); err != nil {
+		return err
+	}
+
+	if err := migrateSearch(db); err != nil {
+		return err
+	}
+	if err := migrateAnalytics(db); err != nil {
+		return err
+	}
+	if err := migrateGeocoding(db); err != nil {
+		return err
+	}
+	return migrateTimezones(db)
 }
 
 func IsValidUserRole(role UserRole) bool {
@@ -32,6 +66,24 @@ func IsValidUserStatus(status UserStatus) bool {
 }
 
 func IsValidEventStatus(status EventStatus) bool {
-	return status == EventStatusActive || status == EventStatusPast || status == EventStatusRejected
+	return status == EventStatusActive || status == EventStatusPast || status == EventStatusRejected || status == EventStatusCancelled
+}
+
+func IsValidApplicationStatus(status ApplicationStatus) bool {
+	return status == ApplicationStatusPending || status == ApplicationStatusApproved || status == ApplicationStatusRejected
+}
+
+func IsValidOrderStatus(status OrderStatus) bool {
+	return status == OrderStatusPending || status == OrderStatusPaid || status == OrderStatusRefunded || status == OrderStatusExpired
+}
+
+func IsValidEventVisibility(visibility EventVisibility) bool {
+	return visibility == EventVisibilityPublic || visibility == EventVisibilityUnlisted ||
+		visibility == EventVisibilityInviteOnly || visibility == EventVisibilityCommunity
+}
+
+func IsValidQuestionType(questionType QuestionType) bool {
+	return questionType == QuestionTypeText || questionType == QuestionTypeSingleChoice ||
+		questionType == QuestionTypeMultiChoice || questionType == QuestionTypeCheckbox
 }
 
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QuestionType - тип вопроса анкеты регистрации на событие
+type QuestionType string
+
+const (
+	QuestionTypeText         QuestionType = "text"          // Свободный ответ
+	QuestionTypeSingleChoice QuestionType = "single_choice" // Один вариант из Options
+	QuestionTypeMultiChoice  QuestionType = "multi_choice"  // Несколько вариантов из Options
+	QuestionTypeCheckbox     QuestionType = "checkbox"      // Отметка (например, согласие с правилами)
+)
+
+// RegistrationQuestion - вопрос анкеты, которую участник заполняет при записи на событие
+type RegistrationQuestion struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID   uuid.UUID    `gorm:"type:uuid;not null;index" json:"eventID"`
+	Position  int          `gorm:"not null;default:0" json:"position"`
+	Type      QuestionType `gorm:"type:varchar(20);not null" json:"type"`
+	Label     string       `gorm:"type:varchar(200);not null" json:"label"`
+	Options   StringArray  `gorm:"type:text[]" json:"options"`
+	Required  bool         `gorm:"not null;default:false" json:"required"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+func (q *RegistrationQuestion) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
+
+// RegistrationAnswer - ответ участника на вопрос анкеты. Values содержит текст ответа, выбранные варианты
+// или "true" для отмеченного флажка. Ответы из заявки на событие с одобрением хранятся с ApplicationID,
+// а из заказа билета - с OrderID, и получают ParticipantID, когда заявку одобряют или заказ оплачивают
+type RegistrationAnswer struct {
+	ID            uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ParticipantID *uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_registration_answer_unique" json:"participantID"`
+	ApplicationID *uuid.UUID  `gorm:"type:uuid;index" json:"applicationID"`
+	OrderID       *uuid.UUID  `gorm:"type:uuid;index" json:"orderID"`
+	QuestionID    uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex:idx_registration_answer_unique;index" json:"questionID"`
+	Values        StringArray `gorm:"type:text[]" json:"values"`
+	CreatedAt     time.Time   `json:"createdAt"`
+}
+
+func (a *RegistrationAnswer) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
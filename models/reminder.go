@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventReminder - журнал отправленных напоминаний: одно напоминание на участника для каждого интервала
+type EventReminder struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_event_reminder_unique" json:"eventID"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_event_reminder_unique" json:"userID"`
+	OffsetMinutes int       `gorm:"not null;uniqueIndex:idx_event_reminder_unique" json:"offsetMinutes"` // За сколько минут до начала
+	SentAt        time.Time `gorm:"not null" json:"sentAt"`
+}
+
+func (er *EventReminder) BeforeCreate(tx *gorm.DB) error {
+	if er.ID == uuid.Nil {
+		er.ID = uuid.New()
+	}
+	return nil
+}
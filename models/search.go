@@ -0,0 +1,47 @@
+package models
+
+import "gorm.io/gorm"
+
+// EventSearchConfig - конфигурация полнотекстового поиска PostgreSQL для событий
+const EventSearchConfig = "russian"
+
+// searchMigrations - объекты БД, которые не описываются моделями GORM: генерируемые колонки, функции и индексы поиска.
+// Все выражения идемпотентны и выполняются при каждом запуске после AutoMigrate.
+var searchMigrations = []string{
+	// array_to_string не IMMUTABLE и не может использоваться в генерируемой колонке напрямую
+	`CREATE OR REPLACE FUNCTION event_tags_to_text(tags text[]) RETURNS text
+		LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$ SELECT coalesce(array_to_string(tags, ' '), '') $$`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('russian', event_tags_to_text(tags)), 'B') ||
+		setweight(to_tsvector('russian', coalesce(short_description, '')), 'C') ||
+		setweight(to_tsvector('russian', coalesce(full_description, '')), 'D')
+	) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector)`,
+	// Триграммные индексы для подсказок с опечатками (/api/search/suggest)
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE INDEX IF NOT EXISTS idx_events_title_trgm ON events USING GIN (title gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_events_tags_trgm ON events USING GIN (event_tags_to_text(tags) gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING GIN (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_micro_communities_name_trgm ON micro_communities USING GIN (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_interests_name_trgm ON interests USING GIN (name gin_trgm_ops)`,
+	// Геопоиск: радиус через earthdistance (GiST по ll_to_earth), прямоугольник карты через B-tree по координатам
+	`CREATE EXTENSION IF NOT EXISTS cube`,
+	`CREATE EXTENSION IF NOT EXISTS earthdistance`,
+	`CREATE INDEX IF NOT EXISTS idx_events_location ON events USING GIST (ll_to_earth(latitude::float8, longitude::float8))
+		WHERE latitude IS NOT NULL AND longitude IS NOT NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_events_lat_lon ON events (latitude, longitude)
+		WHERE latitude IS NOT NULL AND longitude IS NOT NULL`,
+}
+
+// EventLocationExpr - выражение, совпадающее с индексом idx_events_location
+const EventLocationExpr = "ll_to_earth(events.latitude::float8, events.longitude::float8)"
+
+func migrateSearch(db *gorm.DB) error {
+	for _, statement := range searchMigrations {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
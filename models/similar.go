@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SimilarEvent - предрассчитанная похожесть событий для блока "похожие события".
+// Таблица целиком пересчитывается CronService, на каждое событие хранится ограниченное число лучших вариантов
+type SimilarEvent struct {
+	EventID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"eventID"`
+	SimilarEventID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"similarEventID"`
+	Score            float64   `gorm:"not null" json:"score"`
+	SharedCategories int       `gorm:"not null;default:0" json:"sharedCategories"`
+	SharedTags       int       `gorm:"not null;default:0" json:"sharedTags"`
+	CoParticipants   int       `gorm:"not null;default:0" json:"coParticipants"` // Пользователи, записанные на оба события
+	DistanceKm       *float64  `json:"distanceKm"`                               // Если у обоих событий есть координаты
+	ComputedAt       time.Time `gorm:"not null" json:"computedAt"`
+	SimilarEvent     Event     `gorm:"foreignKey:SimilarEventID" json:"similarEvent"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TicketType - тип билета на событие. Цена хранится в копейках, нулевая цена - бесплатный билет без оплаты.
+// Quota ограничивает число проданных и забронированных билетов этого типа
+type TicketType struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"eventID"`
+	Name        string     `gorm:"type:varchar(100);not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	Price       int64      `gorm:"not null;default:0" json:"price"` // В копейках
+	Quota       *int       `json:"quota"`
+	SalesStart  *time.Time `json:"salesStart"` // Начало продаж; без значения - сразу
+	SalesEnd    *time.Time `json:"salesEnd"`   // Конец продаж; без значения - до окончания события
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (t *TicketType) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// OrderStatus - статус заказа билета
+type OrderStatus string
+
+const (
+	OrderStatusPending  OrderStatus = "pending"  // Место забронировано до ExpiresAt, ожидается оплата
+	OrderStatusPaid     OrderStatus = "paid"     // Оплачен, покупатель стал участником события
+	OrderStatusRefunded OrderStatus = "refunded" // Деньги возвращены, участие отменено
+	OrderStatusExpired  OrderStatus = "expired"  // Не оплачен вовремя, бронь снята
+)
+
+// TicketOrder - заказ билета. Один заказ - один билет для покупателя: после оплаты он становится участником
+// события. Неоплаченный заказ держит место до ExpiresAt
+type TicketOrder struct {
+	ID              uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID         uuid.UUID   `gorm:"type:uuid;not null;index" json:"eventID"`
+	Event           Event       `gorm:"foreignKey:EventID" json:"event"`
+	TicketTypeID    uuid.UUID   `gorm:"type:uuid;not null;index" json:"ticketTypeID"`
+	TicketType      TicketType  `gorm:"foreignKey:TicketTypeID" json:"ticketType"`
+	UserID          uuid.UUID   `gorm:"type:uuid;not null;index" json:"userID"`
+	User            User        `gorm:"foreignKey:UserID" json:"user"`
+	Amount          int64       `gorm:"not null" json:"amount"` // В копейках, цена типа билета на момент заказа
+	Status          OrderStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Provider        string      `gorm:"type:varchar(20)" json:"provider"`         // Платежный провайдер: yookassa, fake
+	PaymentID       string      `gorm:"type:varchar(100);index" json:"paymentID"` // Идентификатор платежа у провайдера
+	ConfirmationURL string      `gorm:"type:text" json:"confirmationURL"`         // Страница оплаты
+	ExpiresAt       time.Time   `gorm:"not null;index" json:"expiresAt"`
+	PaidAt          *time.Time  `json:"paidAt"`
+	RefundedAt      *time.Time  `json:"refundedAt"`
+	CreatedAt       time.Time   `json:"createdAt"`
+	UpdatedAt       time.Time   `json:"updatedAt"`
+}
+
+func (o *TicketOrder) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventView - просмотр страницы события, не более одного на зрителя в день.
+// ViewerKey - ID пользователя или хеш анонимного посетителя
+type EventView struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EventID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_event_view_unique;index" json:"eventID"`
+	ViewerKey string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_event_view_unique" json:"-"`
+	ViewedOn  time.Time `gorm:"type:date;not null;uniqueIndex:idx_event_view_unique" json:"viewedOn"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
+
+func (ev *EventView) BeforeCreate(tx *gorm.DB) error {
+	if ev.ID == uuid.Nil {
+		ev.ID = uuid.New()
+	}
+	return nil
+}
+
+// EventTrendingScore - рейтинг "в тренде" для активных событий, таблица целиком пересчитывается CronService.
+// Имена колонок не пересекаются с events, чтобы таблицу можно было присоединять к запросам каталога
+type EventTrendingScore struct {
+	EventID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"eventID"`
+	Score           float64   `gorm:"not null;index" json:"score"`
+	RecentJoins     int       `gorm:"not null;default:0" json:"recentJoins"`
+	RecentViews     int       `gorm:"not null;default:0" json:"recentViews"`
+	OrganizerRating *float64  `json:"organizerRating"` // Средняя оценка прошлых событий организатора
+	ComputedAt      time.Time `gorm:"not null" json:"computedAt"`
+	Event           Event     `gorm:"foreignKey:EventID" json:"event"`
+}
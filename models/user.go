@@ -28,7 +28,10 @@ type User struct {
 	Password  string    `gorm:"" json:"-"` // Может быть пустым для OAuth пользователей
 	YandexID  *string   `gorm:"uniqueIndex" json:"-"` // ID пользователя в Яндекс (NULL для email пользователей)
 	Telegram  string    `gorm:"type:varchar(100)" json:"telegram"` // Telegram username
+	TelegramChatID *int64 `gorm:"uniqueIndex" json:"-"` // ID чата с ботом (NULL, если бот не привязан)
 	AvatarURL string    `gorm:"type:text" json:"avatarURL"` // URL аватарки пользователя
+	CityID    *uuid.UUID `gorm:"type:uuid;index" json:"cityID"` // Город по умолчанию для ленты событий
+	City      *City     `gorm:"foreignKey:CityID" json:"city,omitempty"`
 	Role      UserRole  `gorm:"type:varchar(50);default:'Пользователь'" json:"role"`
 	Status    UserStatus `gorm:"type:varchar(50);default:'Активен'" json:"status"`
 	EmailVerified bool   `gorm:"default:false" json:"emailVerified"`
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Venue - площадка, которую организаторы выбирают при создании событий вместо ввода адреса.
+// Событие хранит копию адреса и координат площадки, поэтому правка площадки не меняет прошедшие события
+type Venue struct {
+	ID                uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name              string         `gorm:"type:varchar(200);not null;index" json:"name"`
+	Description       string         `gorm:"type:text" json:"description"`
+	Address           string         `gorm:"type:text;not null" json:"address"`
+	Latitude          *float64       `gorm:"type:decimal(10,8)" json:"latitude"`
+	Longitude         *float64       `gorm:"type:decimal(11,8)" json:"longitude"`
+	YandexMapLink     string         `gorm:"type:text" json:"yandexMapLink"`
+	TwoGISMapLink     string         `gorm:"column:two_gis_map_link;type:text" json:"twoGISMapLink"`
+	OSMMapLink        string         `gorm:"column:osm_map_link;type:text" json:"osmMapLink"`
+	CityID            *uuid.UUID     `gorm:"type:uuid;index" json:"cityID"`
+	City              *City          `gorm:"foreignKey:CityID" json:"city,omitempty"`
+	Capacity          *int           `json:"capacity"`                           // Вместимость, по умолчанию становится лимитом участников события
+	AccessibilityInfo string         `gorm:"type:text" json:"accessibilityInfo"` // Доступная среда: пандусы, лифты, парковка и т.п.
+	Photos            StringArray    `gorm:"type:text[]" json:"photos"`
+	CreatedByID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"createdByID"`
+	CreatedBy         User           `gorm:"foreignKey:CreatedByID" json:"createdBy"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (v *Venue) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookEventParticipantJoined WebhookEventType = "participant.joined"
+	WebhookEventParticipantLeft   WebhookEventType = "participant.left"
+	WebhookEventEventUpdated      WebhookEventType = "event.updated"
+	WebhookEventEventCancelled    WebhookEventType = "event.cancelled"
+	WebhookEventReviewCreated     WebhookEventType = "review.created"
+	WebhookEventPing              WebhookEventType = "ping"
+)
+
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventParticipantJoined,
+	WebhookEventParticipantLeft,
+	WebhookEventEventUpdated,
+	WebhookEventEventCancelled,
+	WebhookEventReviewCreated,
+}
+
+// WebhookSubscription - подписка организатора (или администратора - на все события) на исходящие вебхуки
+type WebhookSubscription struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OwnerID    uuid.UUID   `gorm:"type:uuid;not null;index" json:"ownerID"`
+	URL        string      `gorm:"type:text;not null" json:"url"`
+	Secret     string      `gorm:"not null" json:"-"` // Ключ для HMAC-подписи
+	EventTypes StringArray `gorm:"type:text[]" json:"eventTypes"`
+	IsActive   bool        `gorm:"default:true" json:"isActive"`
+	Owner      User        `gorm:"foreignKey:OwnerID" json:"owner"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+}
+
+func (ws *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if ws.ID == uuid.Nil {
+		ws.ID = uuid.New()
+	}
+	return nil
+}
+
+// HasEventType проверяет, подписана ли подписка на данный тип события
+func (ws *WebhookSubscription) HasEventType(eventType WebhookEventType) bool {
+	for _, t := range ws.EventTypes {
+		if WebhookEventType(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSuccess WebhookDeliveryStatus = "success"
+	WebhookDeliveryStatusFailed  WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery - запись журнала доставки вебхука
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SubscriptionID uuid.UUID             `gorm:"type:uuid;not null;index" json:"subscriptionID"`
+	EventType      WebhookEventType      `gorm:"type:varchar(50);not null" json:"eventType"`
+	Payload        string                `gorm:"type:text;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	Attempts       int                   `gorm:"default:0" json:"attempts"`
+	ResponseStatus int                   `json:"responseStatus"`
+	LastError      string                `gorm:"type:text" json:"lastError"`
+	NextAttemptAt  *time.Time            `gorm:"index" json:"nextAttemptAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	UpdatedAt      time.Time             `json:"updatedAt"`
+}
+
+func (wd *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if wd.ID == uuid.Nil {
+		wd.ID = uuid.New()
+	}
+	return nil
+}
+
+func IsValidWebhookEventType(eventType WebhookEventType) bool {
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -78,6 +78,7 @@ func SetupRoutes() *gin.Engine {
 	eventHandler := handlers.NewEventHandler()
 	adminHandler := handlers.NewAdminHandler()
 	uploadHandler := handlers.NewUploadHandler()
+	telegramHandler := handlers.NewTelegramHandler()
 
 	healthHandler := handlers.NewHealthHandler()
 	r.GET("/health", healthHandler.HealthCheck)
@@ -113,29 +114,102 @@ func SetupRoutes() *gin.Engine {
 		{
 			user.GET("/profile", userHandler.GetProfile)
 			user.PUT("/profile", userHandler.UpdateProfile)
+			user.POST("/telegram/link", telegramHandler.CreateLink)
+			user.DELETE("/telegram/link", telegramHandler.Unlink)
+			user.GET("/bookmarks", eventHandler.GetBookmarks)
 		}
 
+		api.POST("/telegram/webhook", telegramHandler.Webhook)
+
+		searchHandler := handlers.NewSearchHandler()
+		api.GET("/search/suggest", searchHandler.Suggest)
+
 		events := api.Group("/events")
 		{
 			events.GET("", middleware.OptionalAuthMiddleware(), eventHandler.GetEvents)
+			events.GET("/map", eventHandler.GetEventsMap)
+			events.GET("/recommended", middleware.AuthMiddleware(), eventHandler.GetRecommendedEvents)
+			events.GET("/trending", middleware.OptionalAuthMiddleware(), eventHandler.GetTrendingEvents)
 			events.GET("/:id", middleware.OptionalAuthMiddleware(), eventHandler.GetEvent)
+			events.GET("/:id/similar", middleware.OptionalAuthMiddleware(), eventHandler.GetSimilarEvents)
 			events.POST("", middleware.AuthMiddleware(), eventHandler.CreateEvent)
 			events.PUT("/:id", middleware.AuthMiddleware(), eventHandler.UpdateEvent)
 			events.DELETE("/:id", middleware.AuthMiddleware(), eventHandler.DeleteEvent)
+			events.POST("/:id/cancel", middleware.AuthMiddleware(), eventHandler.CancelEvent)
 			events.POST("/:id/join", middleware.AuthMiddleware(), eventHandler.JoinEvent)
 			events.DELETE("/:id/leave", middleware.AuthMiddleware(), eventHandler.LeaveEvent)
+			events.POST("/:id/bookmark", middleware.AuthMiddleware(), eventHandler.BookmarkEvent)
+			events.DELETE("/:id/bookmark", middleware.AuthMiddleware(), eventHandler.RemoveBookmark)
 			events.GET("/:id/export", middleware.AuthMiddleware(), eventHandler.ExportParticipants)
+			events.GET("/:id/registration-form", middleware.OptionalAuthMiddleware(), eventHandler.GetRegistrationForm)
+			events.PUT("/:id/registration-form", middleware.AuthMiddleware(), eventHandler.UpdateRegistrationForm)
+			events.POST("/:id/share-link", middleware.AuthMiddleware(), eventHandler.RegenerateShareLink)
+			events.GET("/:id/invitations", middleware.AuthMiddleware(), eventHandler.GetInvitations)
+			events.POST("/:id/invitations", middleware.AuthMiddleware(), eventHandler.CreateInvitations)
+			events.DELETE("/:id/invitations/:invitationId", middleware.AuthMiddleware(), eventHandler.DeleteInvitation)
+			events.GET("/:id/applications", middleware.AuthMiddleware(), eventHandler.GetApplications)
+			events.POST("/:id/applications/:applicationId/approve", middleware.AuthMiddleware(), eventHandler.ApproveApplication)
+			events.POST("/:id/applications/:applicationId/reject", middleware.AuthMiddleware(), eventHandler.RejectApplication)
+		}
+
+		analyticsHandler := handlers.NewAnalyticsHandler()
+		analytics := api.Group("/events/:id/analytics")
+		analytics.Use(middleware.AuthMiddleware())
+		{
+			analytics.GET("", analyticsHandler.GetEventAnalytics)
+			analytics.GET("/export", analyticsHandler.ExportEventAnalytics)
+		}
+
+		agendaHandler := handlers.NewAgendaHandler()
+		api.GET("/events/:id/agenda", middleware.OptionalAuthMiddleware(), agendaHandler.GetAgenda)
+		sessions := api.Group("/events/:id/sessions")
+		sessions.Use(middleware.AuthMiddleware())
+		{
+			sessions.POST("", agendaHandler.CreateSession)
+			sessions.PUT("/:sessionId", agendaHandler.UpdateSession)
+			sessions.DELETE("/:sessionId", agendaHandler.DeleteSession)
+			sessions.POST("/:sessionId/bookmark", agendaHandler.AddSessionToAgenda)
+			sessions.DELETE("/:sessionId/bookmark", agendaHandler.RemoveSessionFromAgenda)
+		}
+		speakers := api.Group("/events/:id/speakers")
+		speakers.Use(middleware.AuthMiddleware())
+		{
+			speakers.POST("", agendaHandler.CreateSpeaker)
+			speakers.PUT("/:speakerId", agendaHandler.UpdateSpeaker)
+			speakers.DELETE("/:speakerId", agendaHandler.DeleteSpeaker)
 		}
 
+		ticketHandler := handlers.NewTicketHandler()
+		api.GET("/events/:id/ticket-types", middleware.OptionalAuthMiddleware(), ticketHandler.GetTicketTypes)
+		api.GET("/events/:id/orders", middleware.AuthMiddleware(), ticketHandler.GetEventOrders)
+		ticketTypes := api.Group("/events/:id/ticket-types")
+		ticketTypes.Use(middleware.AuthMiddleware())
+		{
+			ticketTypes.POST("", ticketHandler.CreateTicketType)
+			ticketTypes.PUT("/:ticketTypeId", ticketHandler.UpdateTicketType)
+			ticketTypes.DELETE("/:ticketTypeId", ticketHandler.DeleteTicketType)
+		}
+		orders := api.Group("/orders")
+		orders.Use(middleware.AuthMiddleware())
+		{
+			orders.GET("", ticketHandler.GetMyOrders)
+			orders.GET("/:id", ticketHandler.GetOrder)
+			orders.POST("/:id/refund", ticketHandler.RefundOrder)
+		}
+		api.POST("/payments/webhook", ticketHandler.PaymentWebhook)
+
 		reviewHandler := handlers.NewReviewHandler()
 		reviews := api.Group("/events/:id/reviews")
 		reviews.Use(middleware.AuthMiddleware())
 		{
 			reviews.GET("", reviewHandler.GetEventReviews)
+			reviews.GET("/summary", reviewHandler.GetFeedbackSummary)
 			reviews.POST("", reviewHandler.CreateReview)
 			reviews.PUT("/:reviewId", reviewHandler.UpdateReview)
 			reviews.DELETE("/:reviewId", reviewHandler.DeleteReview)
 		}
+		api.GET("/reviews/quick", middleware.RateLimitMiddleware("30-M"), reviewHandler.QuickReviewPage)
+		api.POST("/reviews/quick", middleware.RateLimitMiddleware("30-M"), reviewHandler.QuickReview)
 
 		geocoderHandler := handlers.NewGeocoderHandler()
 		geocoder := api.Group("/geocoder")
@@ -171,6 +245,30 @@ func SetupRoutes() *gin.Engine {
 			matching.POST("/requests/:id/reject", matchingHandler.RejectMatchRequest)
 		}
 
+		webhookHandler := handlers.NewWebhookHandler()
+		webhooks := api.Group("/webhooks")
+		webhooks.Use(middleware.AuthMiddleware())
+		{
+			webhooks.GET("", webhookHandler.GetWebhooks)
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.POST("/:id/ping", middleware.RateLimitMiddleware("10-M"), webhookHandler.PingWebhook)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+			webhooks.POST("/:id/deliveries/:deliveryId/replay", middleware.RateLimitMiddleware("30-M"), webhookHandler.ReplayDelivery)
+		}
+
+		venueHandler := handlers.NewVenueHandler()
+		venues := api.Group("/venues")
+		{
+			venues.GET("", venueHandler.GetVenues)
+			venues.GET("/:id", venueHandler.GetVenue)
+			venues.GET("/:id/events", middleware.OptionalAuthMiddleware(), eventHandler.GetVenueEvents)
+			venues.POST("", middleware.AuthMiddleware(), venueHandler.CreateVenue)
+			venues.PUT("/:id", middleware.AuthMiddleware(), venueHandler.UpdateVenue)
+			venues.DELETE("/:id", middleware.AuthMiddleware(), venueHandler.DeleteVenue)
+		}
+
 		communityHandler := handlers.NewCommunityHandler()
 		communities := api.Group("/communities")
 		{
@@ -211,6 +309,15 @@ func SetupRoutes() *gin.Engine {
 			adminCategories.PUT("/:id", categoryHandler.UpdateCategory)
 			adminCategories.DELETE("/:id", categoryHandler.DeleteCategory)
 		}
+
+		cityHandler := handlers.NewCityHandler()
+		adminCities := admin.Group("/cities")
+		{
+			adminCities.GET("", cityHandler.GetCities)
+			adminCities.POST("", cityHandler.CreateCity)
+			adminCities.PUT("/:id", cityHandler.UpdateCity)
+			adminCities.DELETE("/:id", cityHandler.DeleteCity)
+		}
 	}
 
 	categoryHandler := handlers.NewCategoryHandler()
@@ -218,6 +325,12 @@ func SetupRoutes() *gin.Engine {
 	{
 		categories.GET("", categoryHandler.GetCategories)
 	}
+
+	cityHandler := handlers.NewCityHandler()
+	cities := api.Group("/cities")
+	{
+		cities.GET("", cityHandler.GetCities)
+	}
 	}
 
 	return r
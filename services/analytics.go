@@ -0,0 +1,174 @@
+package services
+
+import (
+	"time"
+
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AnalyticsService struct {
+	reviewService *ReviewService
+	logger        *zap.Logger
+}
+
+func NewAnalyticsService() *AnalyticsService {
+	return &AnalyticsService{
+		reviewService: NewReviewService(),
+		logger:        utils.GetLogger(),
+	}
+}
+
+type EventAnalytics struct {
+	Views             int64
+	UniqueViewers     int64
+	Joins             int64
+	Leaves            int64
+	ParticipantsCount int64
+	ConversionRate    float64 // Доля авторизованных зрителей, записавшихся на событие
+	CancellationRate  float64 // Доля отмен от всех записей
+	Daily             []EventAnalyticsDay
+	Reviews           *ReviewFeedbackSummary
+}
+
+type EventAnalyticsDay struct {
+	Date         time.Time
+	Views        int64
+	Joins        int64
+	Leaves       int64
+	Participants int64 // Участников на конец дня
+}
+
+// RecordParticipation записывает в журнал запись на событие или отмену участия
+func (as *AnalyticsService) RecordParticipation(eventID, userID uuid.UUID, action models.ParticipationAction) {
+	entry := models.EventParticipationLog{
+		EventID: eventID,
+		UserID:  userID,
+		Action:  action,
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		as.logger.Error("Ошибка записи в журнал участия",
+			zap.String("eventID", eventID.String()),
+			zap.String("userID", userID.String()),
+			zap.String("action", string(action)),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetEventAnalytics собирает статистику события для организатора: просмотры, записи и отмены по дням, конверсию и отзывы
+func (as *AnalyticsService) GetEventAnalytics(event *models.Event) (*EventAnalytics, error) {
+	analytics := &EventAnalytics{}
+
+	var viewStats struct {
+		Views         int64
+		UniqueViewers int64
+		JoinedViewers int64
+		UserViewers   int64
+	}
+	if err := database.DB.Raw(`
+		SELECT COUNT(*) AS views,
+			COUNT(DISTINCT viewer_key) AS unique_viewers,
+			COUNT(DISTINCT viewer_key) FILTER (WHERE viewer_key LIKE 'user:%') AS user_viewers,
+			COUNT(DISTINCT viewer_key) FILTER (WHERE viewer_key IN (
+				SELECT 'user:' || user_id::text FROM event_participation_logs WHERE event_id = @eventID AND action = @joined
+			)) AS joined_viewers
+		FROM event_views
+		WHERE event_id = @eventID`,
+		map[string]interface{}{"eventID": event.ID, "joined": models.ParticipationJoined},
+	).Scan(&viewStats).Error; err != nil {
+		return nil, err
+	}
+	analytics.Views = viewStats.Views
+	analytics.UniqueViewers = viewStats.UniqueViewers
+	if viewStats.UserViewers > 0 {
+		analytics.ConversionRate = float64(viewStats.JoinedViewers) / float64(viewStats.UserViewers)
+	}
+
+	if err := database.DB.Model(&models.EventParticipant{}).Where("event_id = ?", event.ID).Count(&analytics.ParticipantsCount).Error; err != nil {
+		return nil, err
+	}
+
+	var dailyViews []struct {
+		Day   time.Time
+		Count int64
+	}
+	if err := database.DB.Model(&models.EventView{}).
+		Select("viewed_on AS day, COUNT(*) AS count").
+		Where("event_id = ?", event.ID).
+		Group("viewed_on").
+		Scan(&dailyViews).Error; err != nil {
+		return nil, err
+	}
+
+	var dailyActions []struct {
+		Day    time.Time
+		Action models.ParticipationAction
+		Count  int64
+	}
+	if err := database.DB.Model(&models.EventParticipationLog{}).
+		Select("(created_at AT TIME ZONE ?)::date AS day, action, COUNT(*) AS count", utils.EventLocation(event.Timezone).String()).
+		Where("event_id = ?", event.ID).
+		Group("day, action").
+		Scan(&dailyActions).Error; err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]*EventAnalyticsDay)
+	dayFor := func(t time.Time) *EventAnalyticsDay {
+		key := t.Format("2006-01-02")
+		if days[key] == nil {
+			days[key] = &EventAnalyticsDay{Date: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+		}
+		return days[key]
+	}
+	for _, row := range dailyViews {
+		dayFor(row.Day).Views = row.Count
+	}
+	for _, row := range dailyActions {
+		day := dayFor(row.Day)
+		switch row.Action {
+		case models.ParticipationJoined:
+			day.Joins = row.Count
+			analytics.Joins += row.Count
+		case models.ParticipationLeft:
+			day.Leaves = row.Count
+			analytics.Leaves += row.Count
+		}
+	}
+	if analytics.Joins > 0 {
+		analytics.CancellationRate = float64(analytics.Leaves) / float64(analytics.Joins)
+	}
+
+	// Кривая строится без пропусков: от первого дня с активностью до последнего
+	if len(days) > 0 {
+		var first, last time.Time
+		for _, day := range days {
+			if first.IsZero() || day.Date.Before(first) {
+				first = day.Date
+			}
+			if day.Date.After(last) {
+				last = day.Date
+			}
+		}
+		var participants int64
+		for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+			day := dayFor(date)
+			participants += day.Joins - day.Leaves
+			day.Participants = participants
+			analytics.Daily = append(analytics.Daily, *day)
+		}
+	}
+
+	reviews, err := as.reviewService.GetFeedbackSummary(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	analytics.Reviews = reviews
+
+	return analytics, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type BookmarkService struct {
+	emailService    *EmailService
+	telegramService *TelegramService
+	logger          *zap.Logger
+}
+
+func NewBookmarkService() *BookmarkService {
+	return &BookmarkService{
+		emailService:    NewEmailService(),
+		telegramService: NewTelegramService(),
+		logger:          utils.GetLogger(),
+	}
+}
+
+// NotifyNearlyFull напоминает о событиях из избранного, на которых занято не меньше BookmarkNearlyFullRatio мест.
+// Места занимают участники и неоплаченные брони билетов. Напоминание отправляется один раз и только тем,
+// кто включил его и еще не записался; если письмо не ушло, оно повторится при следующем запуске
+func (bs *BookmarkService) NotifyNearlyFull() {
+	now := time.Now()
+
+	var bookmarks []models.EventBookmark
+	if err := database.DB.Preload("User").Preload("Event").
+		Joins("JOIN events ON events.id = event_bookmarks.event_id").
+		Where("event_bookmarks.notify_nearly_full AND event_bookmarks.nearly_full_notified_at IS NULL").
+		Where("events.deleted_at IS NULL AND events.status = ? AND events.start_date > ? AND events.max_participants IS NOT NULL",
+			models.EventStatusActive, now).
+		Where(EventTakenSeatsSQL+" >= CEIL(events.max_participants * ?::numeric) AND "+EventTakenSeatsSQL+" < events.max_participants",
+			utils.BookmarkNearlyFullRatio).
+		Where("NOT EXISTS (SELECT 1 FROM event_participants WHERE event_participants.event_id = events.id AND event_participants.user_id = event_bookmarks.user_id)").
+		Find(&bookmarks).Error; err != nil {
+		bs.logger.Error("Ошибка поиска закладок для напоминаний о местах", zap.Error(err))
+		return
+	}
+
+	takenSeats := make(map[uuid.UUID]int)
+	sent := 0
+	for _, bookmark := range bookmarks {
+		event := bookmark.Event
+		taken, ok := takenSeats[event.ID]
+		if !ok {
+			if err := database.DB.Model(&models.Event{}).Select(EventTakenSeatsSQL).Where("id = ?", event.ID).Scan(&taken).Error; err != nil {
+				bs.logger.Error("Ошибка подсчета занятых мест", zap.String("eventID", event.ID.String()), zap.Error(err))
+				continue
+			}
+			takenSeats[event.ID] = taken
+		}
+		seatsLeft := *event.MaxParticipants - taken
+		message := fmt.Sprintf("Событие из вашего избранного почти заполнено: %s %d %s. Успейте записаться!",
+			utils.PluralRu(seatsLeft, "остался", "осталось", "осталось"), seatsLeft, utils.PluralRu(seatsLeft, "место", "места", "мест"))
+
+		// Напоминание отмечается только после успешной отправки письма
+		if err := bs.emailService.SendEventNotification(bookmark.User.Email, event.Title, message); err != nil {
+			bs.logger.Error("Ошибка отправки напоминания о местах",
+				zap.String("email", bookmark.User.Email),
+				zap.String("eventID", event.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		bs.telegramService.SendEventReminder(&bookmark.User, &event, message)
+		sent++
+
+		if err := database.DB.Model(&bookmark).Update("nearly_full_notified_at", now).Error; err != nil {
+			bs.logger.Error("Ошибка отметки напоминания о местах",
+				zap.String("bookmarkID", bookmark.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if sent > 0 {
+		bs.logger.Info("Отправлены напоминания о заполняющихся событиях из избранного", zap.Int("count", sent))
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// cityDistanceExpr - расстояние в метрах от центра города до точки (@lat, @lon)
+const cityDistanceExpr = "earth_distance(ll_to_earth(latitude::float8, longitude::float8), ll_to_earth(@lat, @lon))"
+
+// FindCityByCoordinates возвращает ближайший город, в радиус которого попадает точка, или nil
+func FindCityByCoordinates(latitude, longitude float64) *uuid.UUID {
+	var cityIDs []uuid.UUID
+	err := database.DB.Raw(`SELECT id FROM cities
+		WHERE `+cityDistanceExpr+` <= radius_km * 1000
+		ORDER BY `+cityDistanceExpr+`
+		LIMIT 1`, map[string]interface{}{"lat": latitude, "lon": longitude}).
+		Scan(&cityIDs).Error
+	if err != nil {
+		utils.GetLogger().Warn("Ошибка определения города по координатам",
+			zap.Float64("latitude", latitude),
+			zap.Float64("longitude", longitude),
+			zap.Error(err),
+		)
+		return nil
+	}
+	if len(cityIDs) == 0 {
+		return nil
+	}
+	return &cityIDs[0]
+}
+
+// AssignEventCity выставляет город события по координатам, если организатор не указал его явно
+func AssignEventCity(event *models.Event) {
+	if event.CityID != nil || event.Latitude == nil || event.Longitude == nil {
+		return
+	}
+	event.CityID = FindCityByCoordinates(*event.Latitude, *event.Longitude)
+}
+
+// AssignEventTimezone выставляет событию часовой пояс его города, если организатор не указал пояс явно.
+// Событие без пояса и без города отображается в часовом поясе платформы
+func AssignEventTimezone(event *models.Event) {
+	if event.Timezone != "" || event.CityID == nil {
+		return
+	}
+	var city models.City
+	if err := database.DB.Select("timezone").Where("id = ?", *event.CityID).First(&city).Error; err == nil {
+		event.Timezone = city.Timezone
+	}
+}
+
+// AssignVenueCity выставляет город площадки по координатам, если он не указан явно
+func AssignVenueCity(venue *models.Venue) {
+	if venue.CityID != nil || venue.Latitude == nil || venue.Longitude == nil {
+		return
+	}
+	venue.CityID = FindCityByCoordinates(*venue.Latitude, *venue.Longitude)
+}
+
+// AssignCityToExisting привязывает к городу события и площадки без города, координаты которых
+// попадают в его радиус. Вызывается при создании города и изменении его центра или радиуса
+func AssignCityToExisting(city *models.City) {
+	logger := utils.GetLogger()
+	for _, table := range []string{"events", "venues"} {
+		result := database.DB.Exec(`UPDATE `+table+` SET city_id = ?
+			WHERE city_id IS NULL AND latitude IS NOT NULL AND longitude IS NOT NULL
+			AND earth_distance(ll_to_earth(latitude::float8, longitude::float8), ll_to_earth(?, ?)) <= ? * 1000`,
+			city.ID, city.Latitude, city.Longitude, city.RadiusKm)
+		if result.Error != nil {
+			logger.Error("Ошибка привязки записей к городу",
+				zap.String("cityID", city.ID.String()),
+				zap.String("table", table),
+				zap.Error(result.Error),
+			)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			logger.Info("Записи привязаны к городу",
+				zap.String("cityID", city.ID.String()),
+				zap.String("table", table),
+				zap.Int64("count", result.RowsAffected),
+			)
+		}
+	}
+}
@@ -21,7 +21,18 @@ func NewCommunityService() *CommunityService {
 	}
 }
 
+// NotifyCommunitiesAboutEvent рассылает публичное событие сообществам с подходящими интересами.
+// О событии только для участников сообщества узнает лишь это сообщество, остальные непубличные события не рассылаются
 func (cs *CommunityService) NotifyCommunitiesAboutEvent(event *models.Event) {
+	switch event.Visibility {
+	case models.EventVisibilityPublic, "":
+	case models.EventVisibilityCommunity:
+		cs.notifyEventCommunity(event)
+		return
+	default:
+		return
+	}
+
 	var communities []models.MicroCommunity
 	query := database.DB.Preload("Members.User").Preload("Interests").Where("auto_notify = ?", true)
 
@@ -46,6 +57,20 @@ func (cs *CommunityService) NotifyCommunitiesAboutEvent(event *models.Event) {
 	}
 }
 
+func (cs *CommunityService) notifyEventCommunity(event *models.Event) {
+	if event.CommunityID == nil {
+		return
+	}
+	var community models.MicroCommunity
+	if err := database.DB.Preload("Members.User").Where("id = ?", *event.CommunityID).First(&community).Error; err != nil {
+		cs.logger.Error("Ошибка получения сообщества события", zap.String("eventID", event.ID.String()), zap.Error(err))
+		return
+	}
+	if community.AutoNotify {
+		cs.notifyCommunityMembers(community, event)
+	}
+}
+
 func (cs *CommunityService) checkEventMatchesCommunityInterests(event *models.Event, community models.MicroCommunity) bool {
 	if len(community.Interests) == 0 {
 		return false
@@ -112,6 +137,7 @@ func (cs *CommunityService) checkEventMatchesCommunityInterests(event *models.Ev
 
 func (cs *CommunityService) notifyCommunityMembers(community models.MicroCommunity, event *models.Event) {
 	emailService := NewEmailService()
+	telegramService := NewTelegramService()
 
 	for _, member := range community.Members {
 		if member.User.ID == uuid.Nil {
@@ -135,6 +161,8 @@ func (cs *CommunityService) notifyCommunityMembers(community models.MicroCommuni
 					zap.Error(err),
 				)
 			}
+
+			telegramService.SendCommunityEventNotification(&user, comm.Name, e)
 		}(member, event, community)
 	}
 }
@@ -3,34 +3,65 @@ package services
 import (
 	"time"
 
+	"bekend/config"
 	"bekend/database"
 	"bekend/models"
 	"bekend/utils"
 
+	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
 type CronService struct {
-	emailService *EmailService
-	logger       *zap.Logger
+	emailService          *EmailService
+	telegramService       *TelegramService
+	webhookService        *WebhookService
+	reviewService         *ReviewService
+	similarService        *SimilarEventService
+	trendingService       *TrendingService
+	bookmarkService       *BookmarkService
+	eventGeocodingService *EventGeocodingService
+	orderService          *OrderService
+	logger                *zap.Logger
 }
 
 func NewCronService() *CronService {
 	return &CronService{
-		emailService: NewEmailService(),
-		logger:       utils.GetLogger(),
+		emailService:          NewEmailService(),
+		telegramService:       NewTelegramService(),
+		webhookService:        NewWebhookService(),
+		reviewService:         NewReviewService(),
+		similarService:        NewSimilarEventService(),
+		trendingService:       NewTrendingService(),
+		bookmarkService:       NewBookmarkService(),
+		eventGeocodingService: NewEventGeocodingService(),
+		orderService:          NewOrderService(),
+		logger:                utils.GetLogger(),
 	}
 }
 
 func (cs *CronService) Start() {
-	c := cron.New()
+	// Расписание задается в часовом поясе платформы, чтобы @hourly и @daily не зависели от настроек сервера
+	c := cron.New(cron.WithLocation(utils.DefaultLocation()))
 
 	c.AddFunc("@hourly", cs.UpdateEventStatuses)
-	c.AddFunc("@daily", cs.SendEventReminders)
+	c.AddFunc("@every "+reminderCheckInterval.String(), cs.SendEventReminders)
+	c.AddFunc("@every 1m", cs.webhookService.RetryPending)
+	c.AddFunc("@every 30m", cs.similarService.Refresh)
+	c.AddFunc("@every 15m", cs.trendingService.Refresh)
+	c.AddFunc("@every 10m", cs.bookmarkService.NotifyNearlyFull)
+	c.AddFunc("@every 10m", cs.eventGeocodingService.RetryPending)
+	c.AddFunc("@every 1m", cs.orderService.ExpireReservations)
 
 	c.Start()
 	cs.logger.Info("Cron jobs started")
+
+	// Предрассчитанные таблицы заполняются сразу, чтобы блоки не были пустыми до первого запуска по расписанию
+	go func() {
+		cs.similarService.Refresh()
+		cs.trendingService.Refresh()
+	}()
 }
 
 func (cs *CronService) UpdateEventStatuses() {
@@ -49,7 +80,9 @@ func (cs *CronService) UpdateEventStatuses() {
 				zap.String("eventID", event.ID.String()),
 				zap.Error(err),
 			)
+			continue
 		}
+		cs.reviewService.SendReviewInvitations(&event)
 	}
 
 	var activeEvents []models.Event
@@ -65,32 +98,122 @@ func (cs *CronService) UpdateEventStatuses() {
 	cs.logger.Info("Обновлены статусы событий", zap.Int("count", len(events)))
 }
 
+// SendEventReminders отправляет напоминания по интервалам из EVENT_REMINDER_OFFSETS.
+// Для каждого участника выбирается ближайший наступивший интервал, отправленные напоминания фиксируются в event_reminders,
+// поэтому повторный запуск не дублирует уведомления, а пропущенные более ранние интервалы не отправляются задним числом.
+// Если письмо не ушло, напоминание не фиксируется и отправляется повторно при следующем запуске, пока интервал актуален.
 func (cs *CronService) SendEventReminders() {
+	offsets := config.AppConfig.EventReminderOffsets
+	if len(offsets) == 0 {
+		return
+	}
+
 	now := time.Now()
-	reminderTime := now.Add(24 * time.Hour)
 
 	var events []models.Event
-	if err := database.DB.Preload("Participants.User").Where("status = ? AND start_date BETWEEN ? AND ?", models.EventStatusActive, now, reminderTime).Find(&events).Error; err != nil {
+	if err := database.DB.Preload("Participants.User").Where("status = ? AND start_date BETWEEN ? AND ?", models.EventStatusActive, now, now.Add(offsets[0])).Find(&events).Error; err != nil {
 		cs.logger.Error("Ошибка поиска событий для напоминаний", zap.Error(err))
 		return
 	}
 
+	sent := 0
 	for _, event := range events {
+		untilStart := event.StartDate.Sub(now)
+		offset := currentReminderOffset(offsets, untilStart)
+		offsetMinutes := int(offset / time.Minute)
+
+		message := "Напоминание: событие начнется через " + utils.FormatDurationRu(reminderLeadTime(offset, untilStart))
+		if event.ReminderMessage != "" {
+			message += "\n\n" + event.ReminderMessage
+		}
+
 		for _, participant := range event.Participants {
+			var alreadySent int64
+			if err := database.DB.Model(&models.EventReminder{}).
+				Where("event_id = ? AND user_id = ? AND offset_minutes = ?", event.ID, participant.UserID, offsetMinutes).
+				Count(&alreadySent).Error; err != nil {
+				cs.logger.Error("Ошибка проверки журнала напоминаний",
+					zap.String("eventID", event.ID.String()),
+					zap.String("userID", participant.UserID.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			if alreadySent > 0 {
+				continue
+			}
+
+			// Напоминание фиксируется только после успешной отправки письма: при ошибке оно уйдет при следующем запуске
 			if err := cs.emailService.SendEventNotification(
 				participant.User.Email,
 				event.Title,
-				"Напоминание: событие начнется через 24 часа",
+				message,
 			); err != nil {
 				cs.logger.Error("Ошибка отправки напоминания",
 					zap.String("email", participant.User.Email),
 					zap.String("eventID", event.ID.String()),
 					zap.Error(err),
 				)
+				continue
+			}
+			cs.telegramService.SendEventReminder(&participant.User, &event, message)
+			sent++
+
+			reminder := models.EventReminder{
+				EventID:       event.ID,
+				UserID:        participant.UserID,
+				OffsetMinutes: offsetMinutes,
+				SentAt:        time.Now(),
+			}
+			if err := database.DB.Create(&reminder).Error; err != nil {
+				cs.logger.Error("Ошибка записи напоминания",
+					zap.String("eventID", event.ID.String()),
+					zap.String("userID", participant.UserID.String()),
+					zap.Error(err),
+				)
 			}
 		}
 	}
 
-	cs.logger.Info("Отправлены напоминания о событиях", zap.Int("count", len(events)))
+	cs.logger.Info("Отправлены напоминания о событиях", zap.Int("events", len(events)), zap.Int("sent", sent))
+}
+
+// ResetEventReminders очищает журнал напоминаний события после переноса даты начала:
+// интервалы отсчитываются от новой даты, и уже сработавшие напоминания отправятся снова
+func ResetEventReminders(eventID uuid.UUID) error {
+	return database.DB.Where("event_id = ?", eventID).Delete(&models.EventReminder{}).Error
+}
+
+// reminderCheckInterval - период запуска SendEventReminders
+const reminderCheckInterval = 10 * time.Minute
+
+// reminderLeadTime возвращает время до начала, которое указывается в напоминании.
+// Если интервал наступил с последнего запуска, пишется сам интервал ("через 1 день"), иначе (участник записался позже или
+// событие создано незадолго до начала) - фактический остаток, округленный до дней, часов или минут
+func reminderLeadTime(offset, untilStart time.Duration) time.Duration {
+	if offset-untilStart <= reminderCheckInterval {
+		return offset
+	}
+	switch {
+	case untilStart >= 24*time.Hour:
+		return untilStart.Round(24 * time.Hour)
+	case untilStart >= time.Hour:
+		return untilStart.Round(time.Hour)
+	case untilStart >= time.Minute:
+		return untilStart.Round(time.Minute)
+	default:
+		return time.Minute
+	}
 }
 
+// currentReminderOffset возвращает наименьший интервал, который уже наступил для события, до начала которого осталось untilStart.
+// offsets отсортированы по убыванию.
+func currentReminderOffset(offsets []time.Duration, untilStart time.Duration) time.Duration {
+	current := offsets[0]
+	for _, offset := range offsets {
+		if untilStart <= offset {
+			current = offset
+		}
+	}
+	return current
+}
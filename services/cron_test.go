@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bekend/config"
+	"bekend/database"
+	"bekend/models"
+
+	"gopkg.in/gomail.v2"
+)
+
+// fakeSMTPServer - минимальный SMTP-сервер, принимающий любые письма и запоминающий получателей
+type fakeSMTPServer struct {
+	listener   net.Listener
+	mu         sync.Mutex
+	recipients []string
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Ошибка запуска SMTP-сервера: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "RCPT TO:"):
+			address := strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<> ")
+			s.mu.Lock()
+			s.recipients = append(s.recipients, address)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+			}
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// sentTo возвращает число писем, принятых для адреса
+func (s *fakeSMTPServer) sentTo(address string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, recipient := range s.recipients {
+		if recipient == address {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *fakeSMTPServer) emailService() *EmailService {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return &EmailService{dialer: gomail.NewDialer(addr.IP.String(), addr.Port, "", "")}
+}
+
+func TestReminderResentAfterReschedule(t *testing.T) {
+	requireTestDB(t)
+
+	offsets := config.AppConfig.EventReminderOffsets
+	config.AppConfig.EventReminderOffsets = []time.Duration{168 * time.Hour, 24 * time.Hour, 2 * time.Hour}
+	t.Cleanup(func() { config.AppConfig.EventReminderOffsets = offsets })
+
+	smtp := startFakeSMTPServer(t)
+	cs := NewCronService()
+	cs.emailService = smtp.emailService()
+
+	organizer := createTestUser(t, 0)
+	participant := createTestUser(t, 0)
+	event := createTestEvent(t, organizer.ID, 0)
+	if err := database.DB.Model(&event).Update("start_date", time.Now().Add(20*time.Hour)).Error; err != nil {
+		t.Fatalf("Ошибка переноса события: %v", err)
+	}
+	if err := database.DB.Create(&models.EventParticipant{EventID: event.ID, UserID: participant.ID}).Error; err != nil {
+		t.Fatalf("Ошибка записи участника: %v", err)
+	}
+
+	cs.SendEventReminders()
+	if got := smtp.sentTo(participant.Email); got != 1 {
+		t.Fatalf("ожидалось напоминание за сутки, отправлено писем: %d", got)
+	}
+
+	// Повторный запуск не дублирует напоминание
+	cs.SendEventReminders()
+	if got := smtp.sentTo(participant.Email); got != 1 {
+		t.Fatalf("повторный запуск не должен отправлять письмо, отправлено: %d", got)
+	}
+
+	// После переноса интервалы отсчитываются от новой даты, и напоминание за сутки уходит снова
+	if err := database.DB.Model(&event).Update("start_date", time.Now().Add(22*time.Hour)).Error; err != nil {
+		t.Fatalf("Ошибка переноса события: %v", err)
+	}
+	if err := ResetEventReminders(event.ID); err != nil {
+		t.Fatalf("ResetEventReminders: %v", err)
+	}
+	cs.SendEventReminders()
+	if got := smtp.sentTo(participant.Email); got != 2 {
+		t.Errorf("после переноса ожидалось повторное напоминание, отправлено писем: %d", got)
+	}
+}
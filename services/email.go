@@ -201,7 +201,7 @@ func (es *EmailService) SendLoginNotification(email, fullName, ipAddress string)
 
 	t := template.Must(template.New("loginNotification").Parse(tmpl))
 	var bodyBuffer bytes.Buffer
-	loginTime := time.Now().Format("02.01.2006 в 15:04")
+	loginTime := time.Now().In(utils.DefaultLocation()).Format("02.01.2006 в 15:04 MST")
 	
 	data := map[string]string{
 		"FullName":  fullName,
@@ -356,7 +356,7 @@ func (es *EmailService) SendCommunityEventNotification(email, fullName, communit
 	}
 
 	var bodyBuffer bytes.Buffer
-	startDate := event.StartDate.Format("02.01.2006 в 15:04")
+	startDate := event.StartDate.In(utils.EventLocation(event.Timezone)).Format("02.01.2006 в 15:04 MST")
 	if err := t.Execute(&bodyBuffer, map[string]string{
 		"FullName":      fullName,
 		"CommunityName": communityName,
@@ -370,3 +370,96 @@ func (es *EmailService) SendCommunityEventNotification(email, fullName, communit
 	return es.SendEmail(email, fmt.Sprintf("Новое событие в сообществе '%s': %s", communityName, event.Title), bodyBuffer.String())
 }
 
+func (es *EmailService) SendEventInvitation(email, inviterName string, event *models.Event, link string) error {
+	tmpl := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<style>
+			body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.event-box { background-color: #f4f4f4; border-left: 4px solid #007bff; padding: 15px; margin: 20px 0; }
+			.button { display: inline-block; padding: 12px 24px; background-color: #007bff; color: white; text-decoration: none; border-radius: 5px; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<h2>Приглашение на событие</h2>
+			<p>{{.InviterName}} приглашает вас на событие:</p>
+			<div class="event-box">
+				<h3>{{.EventTitle}}</h3>
+				<p><strong>Дата начала:</strong> {{.StartDate}}</p>
+				{{if .ShortDescription}}<p>{{.ShortDescription}}</p>{{end}}
+			</div>
+			<p><a href="{{.Link}}" class="button">Открыть событие</a></p>
+			<p>Событие доступно только по приглашению, не пересылайте эту ссылку.</p>
+		</div>
+	</body>
+	</html>
+	`
+
+	t, err := template.New("eventInvitation").Parse(tmpl)
+	if err != nil {
+		return err
+	}
+
+	var bodyBuffer bytes.Buffer
+	if err := t.Execute(&bodyBuffer, map[string]string{
+		"InviterName":      inviterName,
+		"EventTitle":       event.Title,
+		"StartDate":        utils.FormatEventTime(event.StartDate, event.Timezone),
+		"ShortDescription": event.ShortDescription,
+		"Link":             link,
+	}); err != nil {
+		return err
+	}
+
+	return es.SendEmail(email, fmt.Sprintf("Приглашение на событие: %s", event.Title), bodyBuffer.String())
+}
+
+func (es *EmailService) SendReviewInvitation(email, fullName, eventTitle string, ratingLinks []string) error {
+	tmpl := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<style>
+			body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.rating { text-align: center; margin: 20px 0; }
+			.star { display: inline-block; padding: 10px 14px; margin: 0 4px; background: #ffc107; color: #333; text-decoration: none; border-radius: 5px; font-weight: bold; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<h2>Как прошло событие "{{.EventTitle}}"?</h2>
+			<p>Здравствуйте, {{.FullName}}!</p>
+			<p>Спасибо, что были с нами. Оцените событие одним нажатием - это поможет организатору стать лучше:</p>
+			<div class="rating">
+				{{range $i, $link := .RatingLinks}}<a href="{{$link}}" class="star">{{inc $i}} ★</a>{{end}}
+			</div>
+			<p>Ссылки действительны 30 дней. Оставить комментарий можно на странице события.</p>
+		</div>
+	</body>
+	</html>
+	`
+
+	t, err := template.New("reviewInvitation").Funcs(template.FuncMap{
+		"inc": func(i int) int { return i + 1 },
+	}).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+
+	var bodyBuffer bytes.Buffer
+	if err := t.Execute(&bodyBuffer, map[string]interface{}{
+		"FullName":    fullName,
+		"EventTitle":  eventTitle,
+		"RatingLinks": ratingLinks,
+	}); err != nil {
+		return err
+	}
+
+	return es.SendEmail(email, fmt.Sprintf("Оцените событие: %s", eventTitle), bodyBuffer.String())
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// EventGeocodingService определяет координаты событий по адресу, если организатор их не указал
+type EventGeocodingService struct {
+	geocoderService *GeocoderService
+	emailService    *EmailService
+	logger          *zap.Logger
+}
+
+func NewEventGeocodingService() *EventGeocodingService {
+	return &EventGeocodingService{
+		geocoderService: NewGeocoderService(),
+		emailService:    NewEmailService(),
+		logger:          utils.GetLogger(),
+	}
+}
+
+// PrepareLocation выставляет статус геокодинга и ссылки на карты перед сохранением события.
+// Координаты организатора сохраняются как есть, адрес без координат ставится в очередь на геокодинг
+func PrepareLocation(event *models.Event) {
+	switch {
+	case event.Latitude != nil && event.Longitude != nil:
+		event.GeocodeStatus = models.GeocodeStatusManual
+		setMapLinks(event, *event.Latitude, *event.Longitude)
+	case event.Address != "":
+		event.Latitude = nil
+		event.Longitude = nil
+		event.TwoGISMapLink = ""
+		event.OSMMapLink = ""
+		event.GeocodeStatus = models.GeocodeStatusPending
+	default:
+		event.TwoGISMapLink = ""
+		event.OSMMapLink = ""
+		event.GeocodeStatus = models.GeocodeStatusNone
+	}
+}
+
+// Ссылку на Яндекс.Карты, указанную организатором, не перезаписываем
+func setMapLinks(event *models.Event, latitude, longitude float64) {
+	if event.YandexMapLink == "" {
+		event.YandexMapLink = utils.YandexMapLink(latitude, longitude)
+	}
+	event.TwoGISMapLink = utils.TwoGISMapLink(latitude, longitude)
+	event.OSMMapLink = utils.OSMMapLink(latitude, longitude)
+}
+
+// GeocodeEvent определяет координаты события в статусе pending. Если адрес не найден, событие помечается
+// как failed и организатор получает письмо; при недоступности геокодеров событие остается в очереди
+func (egs *EventGeocodingService) GeocodeEvent(eventID uuid.UUID) {
+	var event models.Event
+	if err := database.DB.Preload("Organizer").Where("id = ?", eventID).First(&event).Error; err != nil {
+		egs.logger.Warn("Событие для геокодинга не найдено", zap.String("eventID", eventID.String()), zap.Error(err))
+		return
+	}
+	if event.GeocodeStatus != models.GeocodeStatusPending || event.Address == "" {
+		return
+	}
+
+	result, err := egs.geocoderService.Geocode(context.Background(), event.Address)
+	switch {
+	case err == nil:
+		latitude, longitude := result.Latitude, result.Longitude
+		event.Latitude = &latitude
+		event.Longitude = &longitude
+		setMapLinks(&event, latitude, longitude)
+		AssignEventCity(&event)
+		AssignEventTimezone(&event)
+		event.GeocodeStatus = models.GeocodeStatusResolved
+	case errors.Is(err, ErrAddressNotFound):
+		event.GeocodeStatus = models.GeocodeStatusFailed
+	default:
+		// Повторим позже в RetryPending; updated_at сдвигается, чтобы не повторять сразу
+		if !errors.Is(err, ErrGeocoderNotConfigured) {
+			database.DB.Model(&models.Event{}).Where("id = ? AND geocode_status = ?", event.ID, models.GeocodeStatusPending).
+				Update("updated_at", time.Now())
+		}
+		egs.logger.Warn("Геокодинг события отложен", zap.String("eventID", event.ID.String()), zap.Error(err))
+		return
+	}
+
+	// Адрес мог измениться, пока шел запрос к геокодеру: тогда результат уже неактуален
+	update := database.DB.Model(&models.Event{}).
+		Where("id = ? AND address = ? AND geocode_status = ?", event.ID, event.Address, models.GeocodeStatusPending).
+		Updates(map[string]interface{}{
+			"latitude":         event.Latitude,
+			"longitude":        event.Longitude,
+			"yandex_map_link":  event.YandexMapLink,
+			"two_gis_map_link": event.TwoGISMapLink,
+			"osm_map_link":     event.OSMMapLink,
+			"city_id":          event.CityID,
+			"timezone":         event.Timezone,
+			"geocode_status":   event.GeocodeStatus,
+		})
+	if update.Error != nil {
+		egs.logger.Error("Ошибка сохранения координат события", zap.String("eventID", event.ID.String()), zap.Error(update.Error))
+		return
+	}
+	if update.RowsAffected == 0 {
+		return
+	}
+
+	if event.GeocodeStatus == models.GeocodeStatusFailed {
+		egs.logger.Info("Адрес события не найден геокодером",
+			zap.String("eventID", event.ID.String()),
+			zap.String("address", event.Address),
+		)
+		message := fmt.Sprintf("Не удалось определить координаты адреса \"%s\", поэтому событие не отображается на карте и в поиске рядом. "+
+			"Уточните адрес или укажите точку на карте при редактировании события.", event.Address)
+		if err := egs.emailService.SendEventNotification(event.Organizer.Email, event.Title, message); err != nil {
+			egs.logger.Error("Ошибка отправки уведомления о ненайденном адресе",
+				zap.String("eventID", event.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// RetryPending повторяет геокодинг событий, которые остались в очереди (например, после перезапуска сервера
+// или временной недоступности геокодеров)
+func (egs *EventGeocodingService) RetryPending() {
+	if !egs.geocoderService.IsEnabled() {
+		return
+	}
+
+	var eventIDs []uuid.UUID
+	if err := database.DB.Model(&models.Event{}).
+		Where("geocode_status = ? AND updated_at < ?", models.GeocodeStatusPending, time.Now().Add(-utils.EventGeocodeRetryDelay)).
+		Order("updated_at").
+		Limit(utils.EventGeocodeBatchSize).
+		Pluck("id", &eventIDs).Error; err != nil {
+		egs.logger.Error("Ошибка поиска событий для геокодинга", zap.Error(err))
+		return
+	}
+
+	for _, eventID := range eventIDs {
+		egs.GeocodeEvent(eventID)
+	}
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bekend/config"
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	yandexGeocoderURL = "https://geocode-maps.yandex.ru/1.x/"
+
+	// Политика публичного сервера Nominatim: не чаще одного запроса в секунду и обязательный User-Agent
+	nominatimMinInterval = time.Second
+	nominatimUserAgent   = "bekend-events/1.0"
+)
+
+var (
+	ErrGeocoderNotConfigured = errors.New("геокодер не настроен")
+	ErrAddressNotFound       = errors.New("адрес не найден")
+)
+
+type GeocodeResult struct {
+	Latitude  float64
+	Longitude float64
+	Address   string
+	Provider  string
+}
+
+// Geocoder - провайдер геокодинга. Если ничего не найдено, методы возвращают ErrAddressNotFound
+type Geocoder interface {
+	Name() string
+	Geocode(ctx context.Context, address string) (*GeocodeResult, error)
+	Reverse(ctx context.Context, latitude, longitude float64) (*GeocodeResult, error)
+}
+
+type yandexGeocoder struct {
+	apiKey string
+	client *http.Client
+}
+
+func NewYandexGeocoder(apiKey string) Geocoder {
+	return &yandexGeocoder{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: utils.GeocoderRequestTimeout},
+	}
+}
+
+type yandexGeocoderResponse struct {
+	Response struct {
+		GeoObjectCollection struct {
+			FeatureMember []struct {
+				GeoObject struct {
+					Point struct {
+						Pos string `json:"pos"`
+					} `json:"Point"`
+					MetaDataProperty struct {
+						GeocoderMetaData struct {
+							Text string `json:"text"`
+						} `json:"GeocoderMetaData"`
+					} `json:"metaDataProperty"`
+				} `json:"GeoObject"`
+			} `json:"featureMember"`
+		} `json:"GeoObjectCollection"`
+	} `json:"response"`
+}
+
+func (yg *yandexGeocoder) Name() string {
+	return "yandex"
+}
+
+func (yg *yandexGeocoder) Geocode(ctx context.Context, address string) (*GeocodeResult, error) {
+	return yg.request(ctx, address)
+}
+
+func (yg *yandexGeocoder) Reverse(ctx context.Context, latitude, longitude float64) (*GeocodeResult, error) {
+	// Яндекс принимает координаты в порядке "долгота,широта"
+	result, err := yg.request(ctx, fmt.Sprintf("%f,%f", longitude, latitude))
+	if err != nil {
+		return nil, err
+	}
+	result.Latitude = latitude
+	result.Longitude = longitude
+	return result, nil
+}
+
+func (yg *yandexGeocoder) request(ctx context.Context, geocode string) (*GeocodeResult, error) {
+	params := url.Values{}
+	params.Set("apikey", yg.apiKey)
+	params.Set("geocode", geocode)
+	params.Set("format", "json")
+	params.Set("results", "1")
+
+	var resp yandexGeocoderResponse
+	if err := getJSON(ctx, yg.client, yandexGeocoderURL+"?"+params.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	members := resp.Response.GeoObjectCollection.FeatureMember
+	if len(members) == 0 {
+		return nil, ErrAddressNotFound
+	}
+	geoObject := members[0].GeoObject
+
+	// Формат координат: "долгота широта"
+	var longitude, latitude float64
+	if _, err := fmt.Sscanf(geoObject.Point.Pos, "%f %f", &longitude, &latitude); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга координат: %w", err)
+	}
+
+	return &GeocodeResult{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Address:   geoObject.MetaDataProperty.GeocoderMetaData.Text,
+		Provider:  yg.Name(),
+	}, nil
+}
+
+type nominatimGeocoder struct {
+	baseURL string
+	client  *http.Client
+	limiter *intervalLimiter
+}
+
+// nominatimLimiter общий для всех экземпляров nominatimGeocoder: сервисы создают геокодер каждый раз заново,
+// а ограничение публичного сервера действует на все приложение
+var nominatimLimiter = &intervalLimiter{interval: nominatimMinInterval}
+
+func NewNominatimGeocoder(baseURL string) Geocoder {
+	return &nominatimGeocoder{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: utils.GeocoderRequestTimeout},
+		limiter: nominatimLimiter,
+	}
+}
+
+// intervalLimiter пропускает запросы не чаще одного за interval
+type intervalLimiter struct {
+	interval time.Duration
+
+	mu          sync.Mutex
+	lastRequest time.Time
+}
+
+// Wait ждет, пока с предыдущего запроса пройдет interval, или отмены ctx
+func (il *intervalLimiter) Wait(ctx context.Context) error {
+	il.mu.Lock()
+	defer il.mu.Unlock()
+
+	if wait := il.interval - time.Since(il.lastRequest); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	il.lastRequest = time.Now()
+	return nil
+}
+
+type nominatimPlace struct {
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	DisplayName string `json:"display_name"`
+	Error       string `json:"error"`
+}
+
+func (ng *nominatimGeocoder) Name() string {
+	return "nominatim"
+}
+
+func (ng *nominatimGeocoder) Geocode(ctx context.Context, address string) (*GeocodeResult, error) {
+	params := url.Values{}
+	params.Set("q", address)
+	params.Set("format", "jsonv2")
+	params.Set("limit", "1")
+
+	var places []nominatimPlace
+	if err := ng.request(ctx, "/search", params, &places); err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, ErrAddressNotFound
+	}
+	return ng.toResult(places[0])
+}
+
+func (ng *nominatimGeocoder) Reverse(ctx context.Context, latitude, longitude float64) (*GeocodeResult, error) {
+	params := url.Values{}
+	params.Set("lat", strconv.FormatFloat(latitude, 'f', -1, 64))
+	params.Set("lon", strconv.FormatFloat(longitude, 'f', -1, 64))
+	params.Set("format", "jsonv2")
+
+	var place nominatimPlace
+	if err := ng.request(ctx, "/reverse", params, &place); err != nil {
+		return nil, err
+	}
+	if place.Error != "" || place.DisplayName == "" {
+		return nil, ErrAddressNotFound
+	}
+
+	result, err := ng.toResult(place)
+	if err != nil {
+		return nil, err
+	}
+	result.Latitude = latitude
+	result.Longitude = longitude
+	return result, nil
+}
+
+func (ng *nominatimGeocoder) request(ctx context.Context, path string, params url.Values, target interface{}) error {
+	if err := ng.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	params.Set("accept-language", "ru")
+	headers := map[string]string{"User-Agent": nominatimUserAgent}
+	return getJSON(ctx, ng.client, ng.baseURL+path+"?"+params.Encode(), headers, target)
+}
+
+func (ng *nominatimGeocoder) toResult(place nominatimPlace) (*GeocodeResult, error) {
+	latitude, err := strconv.ParseFloat(place.Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка парсинга координат: %w", err)
+	}
+	longitude, err := strconv.ParseFloat(place.Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка парсинга координат: %w", err)
+	}
+	return &GeocodeResult{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Address:   place.DisplayName,
+		Provider:  ng.Name(),
+	}, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, requestURL string, headers map[string]string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("геокодер вернул статус %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("ошибка парсинга ответа геокодера: %w", err)
+	}
+	return nil
+}
+
+var fakeGeocoder = NewFakeGeocoder()
+
+// GeocoderService опрашивает провайдеров по порядку, пока один из них не найдет адрес,
+// и сохраняет найденные результаты в таблицу geocode_caches
+type GeocoderService struct {
+	providers []Geocoder
+	logger    *zap.Logger
+}
+
+func NewGeocoderService() *GeocoderService {
+	if config.AppConfig.FakeGeocoder {
+		return NewGeocoderServiceWithProviders(fakeGeocoder)
+	}
+
+	var providers []Geocoder
+	for _, name := range config.AppConfig.GeocoderProviders {
+		switch name {
+		case "yandex":
+			if config.AppConfig.YandexGeocoderAPIKey != "" {
+				providers = append(providers, NewYandexGeocoder(config.AppConfig.YandexGeocoderAPIKey))
+			}
+		case "nominatim":
+			if config.AppConfig.NominatimURL != "" {
+				providers = append(providers, NewNominatimGeocoder(config.AppConfig.NominatimURL))
+			}
+		default:
+			utils.GetLogger().Warn("Неизвестный провайдер геокодинга", zap.String("provider", name))
+		}
+	}
+	return NewGeocoderServiceWithProviders(providers...)
+}
+
+// NewGeocoderServiceWithProviders создает сервис с заданными провайдерами (например, FakeGeocoder)
+func NewGeocoderServiceWithProviders(providers ...Geocoder) *GeocoderService {
+	return &GeocoderService{
+		providers: providers,
+		logger:    utils.GetLogger(),
+	}
+}
+
+func (gs *GeocoderService) IsEnabled() bool {
+	return len(gs.providers) > 0
+}
+
+// Geocode находит координаты адреса
+func (gs *GeocoderService) Geocode(ctx context.Context, address string) (*GeocodeResult, error) {
+	key := strings.Join(strings.Fields(strings.ToLower(address)), " ")
+	if key == "" {
+		return nil, ErrAddressNotFound
+	}
+
+	return gs.lookup(ctx, models.GeocodeForward, key, func(ctx context.Context, provider Geocoder) (*GeocodeResult, error) {
+		return provider.Geocode(ctx, address)
+	})
+}
+
+// Reverse находит адрес по координатам
+func (gs *GeocoderService) Reverse(ctx context.Context, latitude, longitude float64) (*GeocodeResult, error) {
+	key := fmt.Sprintf("%.5f,%.5f", latitude, longitude)
+
+	return gs.lookup(ctx, models.GeocodeReverse, key, func(ctx context.Context, provider Geocoder) (*GeocodeResult, error) {
+		return provider.Reverse(ctx, latitude, longitude)
+	})
+}
+
+func (gs *GeocoderService) lookup(ctx context.Context, kind models.GeocodeKind, key string, call func(context.Context, Geocoder) (*GeocodeResult, error)) (*GeocodeResult, error) {
+	if !gs.IsEnabled() {
+		return nil, ErrGeocoderNotConfigured
+	}
+
+	var cached models.GeocodeCache
+	if err := database.DB.Where("kind = ? AND query_key = ? AND updated_at > ?", kind, key, time.Now().Add(-utils.GeocodeCacheTTL)).
+		First(&cached).Error; err == nil {
+		return &GeocodeResult{
+			Latitude:  cached.Latitude,
+			Longitude: cached.Longitude,
+			Address:   cached.Address,
+			Provider:  cached.Provider,
+		}, nil
+	}
+
+	// Провайдер, не нашедший адрес, не считается сбоем: следующий может знать его лучше
+	var lastErr error
+	for _, provider := range gs.providers {
+		providerCtx, cancel := context.WithTimeout(ctx, utils.GeocoderRequestTimeout)
+		result, err := call(providerCtx, provider)
+		cancel()
+
+		if err == nil {
+			gs.store(kind, key, result)
+			return result, nil
+		}
+		if !errors.Is(err, ErrAddressNotFound) {
+			gs.logger.Warn("Ошибка провайдера геокодинга",
+				zap.String("provider", provider.Name()),
+				zap.String("kind", string(kind)),
+				zap.String("query", key),
+				zap.Error(err),
+			)
+			lastErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, ErrAddressNotFound
+}
+
+func (gs *GeocoderService) store(kind models.GeocodeKind, key string, result *GeocodeResult) {
+	entry := models.GeocodeCache{
+		Kind:      kind,
+		QueryKey:  key,
+		Latitude:  result.Latitude,
+		Longitude: result.Longitude,
+		Address:   result.Address,
+		Provider:  result.Provider,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "query_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"latitude", "longitude", "address", "provider", "updated_at"}),
+	}).Create(&entry).Error; err != nil {
+		gs.logger.Warn("Ошибка сохранения результата геокодинга в кеш", zap.String("query", key), zap.Error(err))
+	}
+}
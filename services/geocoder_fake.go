@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+// FakeGeocoder - локальная реализация Geocoder без сетевых запросов.
+// Используется в режиме FAKE_GEOCODER и в тестах: адреса из Places возвращаются как есть, остальные
+// получают детерминированные координаты в пределах Москвы. Адреса из NotFound не находятся, а Err
+// имитирует недоступность провайдера
+type FakeGeocoder struct {
+	mu       sync.Mutex
+	Places   map[string]GeocodeResult
+	NotFound map[string]bool
+	Err      error
+	Calls    []string
+}
+
+func NewFakeGeocoder() *FakeGeocoder {
+	return &FakeGeocoder{
+		Places:   make(map[string]GeocodeResult),
+		NotFound: make(map[string]bool),
+	}
+}
+
+func (f *FakeGeocoder) Name() string {
+	return "fake"
+}
+
+func (f *FakeGeocoder) Geocode(ctx context.Context, address string) (*GeocodeResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Calls = append(f.Calls, "geocode:"+address)
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NotFound[address] {
+		return nil, ErrAddressNotFound
+	}
+	if place, ok := f.Places[address]; ok {
+		place.Provider = f.Name()
+		return &place, nil
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(address)))
+	sum := h.Sum32()
+	return &GeocodeResult{
+		Latitude:  55.55 + float64(sum%40000)/100000,
+		Longitude: 37.35 + float64((sum/40000)%50000)/100000,
+		Address:   address,
+		Provider:  f.Name(),
+	}, nil
+}
+
+func (f *FakeGeocoder) Reverse(ctx context.Context, latitude, longitude float64) (*GeocodeResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Calls = append(f.Calls, fmt.Sprintf("reverse:%.5f,%.5f", latitude, longitude))
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	for address, place := range f.Places {
+		if fmt.Sprintf("%.5f,%.5f", place.Latitude, place.Longitude) == fmt.Sprintf("%.5f,%.5f", latitude, longitude) {
+			if place.Address == "" {
+				place.Address = address
+			}
+			place.Provider = f.Name()
+			return &place, nil
+		}
+	}
+
+	return &GeocodeResult{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Address:   fmt.Sprintf("Точка %.5f, %.5f", latitude, longitude),
+		Provider:  f.Name(),
+	}, nil
+}
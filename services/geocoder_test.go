@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestIntervalLimiterSpacesRequests(t *testing.T) {
+	limiter := &intervalLimiter{interval: 50 * time.Millisecond}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 запроса прошли за %v, ожидалось не меньше 100ms", elapsed)
+	}
+}
+
+func TestIntervalLimiterStopsOnCancel(t *testing.T) {
+	limiter := &intervalLimiter{interval: time.Hour}
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("первый запрос не должен ждать: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ожидалась отмена по контексту, получено %v", err)
+	}
+}
+
+func TestNominatimGeocodersShareLimiter(t *testing.T) {
+	first := NewNominatimGeocoder("https://nominatim.example.com").(*nominatimGeocoder)
+	second := NewNominatimGeocoder("https://nominatim.example.com/").(*nominatimGeocoder)
+	if first.limiter != nominatimLimiter || second.limiter != nominatimLimiter {
+		t.Error("все экземпляры Nominatim должны использовать общий nominatimLimiter")
+	}
+}
+
+func TestGeocoderServiceWithoutProviders(t *testing.T) {
+	gs := NewGeocoderServiceWithProviders()
+	if _, err := gs.Geocode(context.Background(), "Москва"); !errors.Is(err, ErrGeocoderNotConfigured) {
+		t.Errorf("ожидалась ErrGeocoderNotConfigured, получено %v", err)
+	}
+}
+
+func TestGeocoderServiceFallsBackToNextProvider(t *testing.T) {
+	requireTestDB(t)
+
+	address := "Тестовая улица, " + uuid.NewString()
+	unavailable := NewFakeGeocoder()
+	unavailable.Err = errors.New("провайдер недоступен")
+	notFound := NewFakeGeocoder()
+	notFound.NotFound[address] = true
+	found := NewFakeGeocoder()
+	found.Places[address] = GeocodeResult{Latitude: 55.75, Longitude: 37.61, Address: "Москва, Тестовая улица"}
+
+	gs := NewGeocoderServiceWithProviders(unavailable, notFound, found)
+	result, err := gs.Geocode(context.Background(), address)
+	if err != nil {
+		t.Fatalf("Geocode: %v", err)
+	}
+	if result.Latitude != 55.75 || result.Longitude != 37.61 {
+		t.Errorf("координаты %v,%v, ожидались 55.75,37.61", result.Latitude, result.Longitude)
+	}
+	if len(unavailable.Calls) != 1 || len(notFound.Calls) != 1 || len(found.Calls) != 1 {
+		t.Errorf("каждый провайдер должен быть опрошен один раз: %v, %v, %v", unavailable.Calls, notFound.Calls, found.Calls)
+	}
+
+	// Повторный запрос (в другом регистре и с лишними пробелами) берется из кеша без обращения к провайдерам
+	cached, err := gs.Geocode(context.Background(), "  ТЕСТОВАЯ  улица, "+address[len("Тестовая улица, "):])
+	if err != nil {
+		t.Fatalf("Geocode из кеша: %v", err)
+	}
+	if cached.Latitude != result.Latitude || cached.Longitude != result.Longitude || cached.Provider != result.Provider {
+		t.Errorf("из кеша получено %+v, ожидалось %+v", cached, result)
+	}
+	if len(found.Calls) != 1 {
+		t.Errorf("при попадании в кеш провайдеры не опрашиваются, вызовы: %v", found.Calls)
+	}
+}
+
+func TestGeocoderServiceReportsFailures(t *testing.T) {
+	requireTestDB(t)
+
+	address := "Несуществующая улица, " + uuid.NewString()
+	notFound := NewFakeGeocoder()
+	notFound.NotFound[address] = true
+
+	gs := NewGeocoderServiceWithProviders(notFound)
+	if _, err := gs.Geocode(context.Background(), address); !errors.Is(err, ErrAddressNotFound) {
+		t.Errorf("ожидалась ErrAddressNotFound, получено %v", err)
+	}
+
+	// Сбой провайдера важнее "не найдено": вызывающий код оставляет адрес в очереди, а не помечает его ненайденным
+	providerErr := errors.New("провайдер недоступен")
+	unavailable := NewFakeGeocoder()
+	unavailable.Err = providerErr
+	gs = NewGeocoderServiceWithProviders(notFound, unavailable)
+	if _, err := gs.Geocode(context.Background(), address); !errors.Is(err, providerErr) {
+		t.Errorf("ожидалась ошибка провайдера, получено %v", err)
+	}
+}
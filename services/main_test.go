@@ -0,0 +1,101 @@
+package services
+
+import (
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"bekend/config"
+	"bekend/database"
+	"bekend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// testDBAvailable - задана ли тестовая база. Тесты, которым нужна PostgreSQL, подключаются к базе из TEST_DATABASE_URL
+// (например, host=localhost user=postgres password=postgres dbname=bekend_test sslmode=disable), без нее они пропускаются
+var testDBAvailable bool
+
+func TestMain(m *testing.M) {
+	config.AppConfig = &config.Config{
+		// Письма в тестах не отправляются: SMTP-сервер по этому адресу недоступен
+		EmailHost:       "127.0.0.1",
+		EmailPort:       1,
+		EmailFrom:       "noreply@example.com",
+		FrontendURL:     "http://localhost:5173",
+		PublicAPIURL:    "http://localhost:8081",
+		JWTSecret:       "test-secret",
+		DefaultTimezone: "Europe/Moscow",
+	}
+
+	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+		})
+		if err != nil {
+			log.Fatalf("Ошибка подключения к тестовой базе: %v", err)
+		}
+		if err := models.AutoMigrate(db); err != nil {
+			log.Fatalf("Ошибка миграции тестовой базы: %v", err)
+		}
+		database.DB = db
+		testDBAvailable = true
+	}
+
+	os.Exit(m.Run())
+}
+
+func requireTestDB(t *testing.T) {
+	t.Helper()
+	if !testDBAvailable {
+		t.Skip("TEST_DATABASE_URL не задан")
+	}
+}
+
+// createTestUser создает пользователя с уникальным email; chatID - привязанный чат Telegram (0 - без чата)
+func createTestUser(t *testing.T, chatID int64) models.User {
+	t.Helper()
+	user := models.User{
+		FullName: "Тестовый пользователь",
+		Email:    uuid.NewString() + "@example.com",
+		Status:   models.UserStatusActive,
+	}
+	if chatID != 0 {
+		user.TelegramChatID = &chatID
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatalf("Ошибка создания пользователя: %v", err)
+	}
+	return user
+}
+
+// createTestEvent создает активное событие через неделю; maxParticipants 0 - без лимита участников
+func createTestEvent(t *testing.T, organizerID uuid.UUID, maxParticipants int) models.Event {
+	t.Helper()
+	start := time.Now().Add(7 * 24 * time.Hour)
+	event := models.Event{
+		Title:           "Тестовое событие",
+		FullDescription: "Описание",
+		StartDate:       start,
+		EndDate:         start.Add(2 * time.Hour),
+		Status:          models.EventStatusActive,
+		OrganizerID:     organizerID,
+	}
+	if maxParticipants > 0 {
+		event.MaxParticipants = &maxParticipants
+	}
+	if err := database.DB.Create(&event).Error; err != nil {
+		t.Fatalf("Ошибка создания события: %v", err)
+	}
+	return event
+}
+
+// randomChatID возвращает ID чата Telegram, который не пересечется с данными прошлых запусков
+func randomChatID() int64 {
+	return rand.Int63n(1<<40) + 1
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrMatchRequestNotFound  = errors.New("запрос не найден")
+	ErrMatchRequestProcessed = errors.New("запрос уже обработан")
+)
+
+type MatchingService struct {
+	logger *zap.Logger
+}
+
+func NewMatchingService() *MatchingService {
+	return &MatchingService{
+		logger: utils.GetLogger(),
+	}
+}
+
+// AcceptMatchRequest принимает входящий запрос пользователя userID и отмечает, что оба участника нашли компанию
+func (ms *MatchingService) AcceptMatchRequest(requestID, userID uuid.UUID) (*models.MatchRequest, error) {
+	request, err := ms.findPendingRequest(requestID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Status = models.MatchRequestStatusAccepted
+	if err := database.DB.Save(request).Error; err != nil {
+		return nil, err
+	}
+
+	var fromMatching models.EventMatching
+	database.DB.Where("user_id = ? AND event_id = ?", request.FromUserID, request.EventID).First(&fromMatching)
+	fromMatching.Status = models.MatchStatusFound
+	database.DB.Save(&fromMatching)
+
+	var toMatching models.EventMatching
+	database.DB.Where("user_id = ? AND event_id = ?", request.ToUserID, request.EventID).First(&toMatching)
+	toMatching.Status = models.MatchStatusFound
+	database.DB.Save(&toMatching)
+
+	return request, nil
+}
+
+// RejectMatchRequest отклоняет входящий запрос пользователя userID
+func (ms *MatchingService) RejectMatchRequest(requestID, userID uuid.UUID) (*models.MatchRequest, error) {
+	request, err := ms.findPendingRequest(requestID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Status = models.MatchRequestStatusRejected
+	if err := database.DB.Save(request).Error; err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// CloseEventMatching прекращает поиск компании для события: ожидающие запросы отклоняются, участники больше не ищут компанию
+func (ms *MatchingService) CloseEventMatching(eventID uuid.UUID) error {
+	if err := database.DB.Model(&models.MatchRequest{}).
+		Where("event_id = ? AND status = ?", eventID, models.MatchRequestStatusPending).
+		Update("status", models.MatchRequestStatusRejected).Error; err != nil {
+		return err
+	}
+
+	return database.DB.Model(&models.EventMatching{}).
+		Where("event_id = ? AND status = ?", eventID, models.MatchStatusLooking).
+		Update("status", models.MatchStatusGoingAlone).Error
+}
+
+func (ms *MatchingService) findPendingRequest(requestID, userID uuid.UUID) (*models.MatchRequest, error) {
+	var request models.MatchRequest
+	if err := database.DB.Where("id = ? AND to_user_id = ?", requestID, userID).First(&request).Error; err != nil {
+		return nil, ErrMatchRequestNotFound
+	}
+
+	if request.Status != models.MatchRequestStatusPending {
+		return nil, ErrMatchRequestProcessed
+	}
+
+	return &request, nil
+}
@@ -0,0 +1,547 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"bekend/config"
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTicketsSoldOut = errors.New("билеты этого типа закончились")
+	ErrNoSeatsLeft    = errors.New("достигнут максимальный лимит участников")
+	ErrOrderNotPaid   = errors.New("заказ не оплачен")
+)
+
+// EventTakenSeatsSQL - SQL-выражение с числом занятых мест события (строки таблицы events).
+// Места занимают участники и неоплаченные брони, как при бронировании (reserveSeat)
+const EventTakenSeatsSQL = "((SELECT COUNT(*) FROM event_participants WHERE event_participants.event_id = events.id) + " +
+	"(SELECT COUNT(*) FROM ticket_orders WHERE ticket_orders.event_id = events.id AND ticket_orders.status = 'pending' AND ticket_orders.expires_at > now()))"
+
+var fakePaymentProvider = NewFakePaymentProvider(true)
+
+// OrderService продает билеты: бронирует место на время оплаты, создает платеж у провайдера
+// и записывает покупателя участником после подтверждения оплаты
+type OrderService struct {
+	provider         PaymentProvider
+	emailService     *EmailService
+	webhookService   *WebhookService
+	analyticsService *AnalyticsService
+	logger           *zap.Logger
+}
+
+func NewOrderService() *OrderService {
+	var provider PaymentProvider
+	if config.AppConfig.FakePayments {
+		provider = fakePaymentProvider
+	} else if config.AppConfig.YooKassaShopID != "" {
+		provider = NewYooKassaProvider(yookassaAPIURL, config.AppConfig.YooKassaShopID, config.AppConfig.YooKassaSecretKey)
+	}
+	return NewOrderServiceWithProvider(provider)
+}
+
+// NewOrderServiceWithProvider создает сервис с заданным платежным провайдером (например, FakePaymentProvider)
+func NewOrderServiceWithProvider(provider PaymentProvider) *OrderService {
+	return &OrderService{
+		provider:         provider,
+		emailService:     NewEmailService(),
+		webhookService:   NewWebhookService(),
+		analyticsService: NewAnalyticsService(),
+		logger:           utils.GetLogger(),
+	}
+}
+
+// TakenTickets возвращает, сколько билетов каждого типа события продано или забронировано
+func TakenTickets(db *gorm.DB, eventID uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		TicketTypeID uuid.UUID
+		Count        int
+	}
+	err := db.Model(&models.TicketOrder{}).
+		Select("ticket_type_id, COUNT(*) AS count").
+		Where("event_id = ?", eventID).
+		Scopes(activeOrders).
+		Group("ticket_type_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		result[row.TicketTypeID] = row.Count
+	}
+	return result, nil
+}
+
+// activeOrders - заказы, которые занимают место: оплаченные и забронированные
+func activeOrders(db *gorm.DB) *gorm.DB {
+	return db.Where("(status = ? OR (status = ? AND expires_at > ?))", models.OrderStatusPaid, models.OrderStatusPending, time.Now())
+}
+
+// PlaceOrder бронирует место и создает платеж. Бесплатный билет оформляется сразу, для платного возвращается
+// заказ со ссылкой на оплату; место держится до ExpiresAt. Ответы на анкету переходят участнику после оплаты.
+// Если у пользователя уже есть неоплаченный заказ на событие с тем же типом билета и теми же ответами, он возвращается
+// вместо нового; иначе его бронь снимается и оформляется новый заказ
+func (os *OrderService) PlaceOrder(event *models.Event, ticketType *models.TicketType, userID uuid.UUID, answers []models.RegistrationAnswer) (*models.TicketOrder, error) {
+	if ticketType.Price > 0 && os.provider == nil {
+		return nil, ErrPaymentsNotConfigured
+	}
+
+	replacedOrderID := uuid.Nil
+	var existing models.TicketOrder
+	err := database.DB.Where("event_id = ? AND user_id = ?", event.ID, userID).
+		Where("status = ? AND expires_at > ?", models.OrderStatusPending, time.Now()).
+		First(&existing).Error
+	if err == nil {
+		var existingAnswers []models.RegistrationAnswer
+		if err := database.DB.Where("order_id = ?", existing.ID).Find(&existingAnswers).Error; err != nil {
+			return nil, err
+		}
+		if existing.TicketTypeID == ticketType.ID && sameRegistrationAnswers(existingAnswers, answers) {
+			return &existing, nil
+		}
+		replacedOrderID = existing.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	order := models.TicketOrder{
+		EventID:      event.ID,
+		TicketTypeID: ticketType.ID,
+		UserID:       userID,
+		Amount:       ticketType.Price,
+		Status:       models.OrderStatusPending,
+		ExpiresAt:    time.Now().Add(utils.OrderReservationTTL),
+	}
+	if ticketType.Price > 0 {
+		order.Provider = os.provider.Name()
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Место прежней брони учитывается как свободное и снимается вместе с ней
+		if err := os.reserveSeat(tx, event.ID, ticketType.ID, replacedOrderID); err != nil {
+			return err
+		}
+		if replacedOrderID != uuid.Nil {
+			if err := tx.Model(&models.TicketOrder{}).Where("id = ? AND status = ?", replacedOrderID, models.OrderStatusPending).
+				Update("status", models.OrderStatusExpired).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		if len(answers) > 0 {
+			for i := range answers {
+				answers[i].OrderID = &order.ID
+			}
+			if err := tx.Create(&answers).Error; err != nil {
+				return err
+			}
+		}
+		if order.Amount == 0 {
+			return os.markPaid(tx, &order)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status == models.OrderStatusPaid {
+		os.onOrderPaid(&order)
+		return &order, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.PaymentRequestTimeout)
+	defer cancel()
+	payment, err := os.provider.CreatePayment(ctx, PaymentRequest{
+		OrderID:     order.ID,
+		Amount:      order.Amount,
+		Currency:    utils.TicketCurrency,
+		Description: fmt.Sprintf("%s: %s", event.Title, ticketType.Name),
+		ReturnURL:   fmt.Sprintf("%s/orders/%s", config.AppConfig.FrontendURL, order.ID),
+	})
+	if err != nil {
+		// Без платежа бронь бесполезна: место сразу освобождается
+		database.DB.Model(&order).Update("status", models.OrderStatusExpired)
+		return nil, fmt.Errorf("ошибка создания платежа: %w", err)
+	}
+
+	order.PaymentID = payment.ID
+	order.ConfirmationURL = payment.ConfirmationURL
+	if err := database.DB.Model(&order).Updates(map[string]interface{}{
+		"payment_id":       order.PaymentID,
+		"confirmation_url": order.ConfirmationURL,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	if payment.Status != PaymentStatusPending {
+		if err := os.applyPayment(&order, payment); err != nil {
+			os.logger.Error("Ошибка применения платежа", zap.String("orderID", order.ID.String()), zap.Error(err))
+		}
+	}
+	return &order, nil
+}
+
+// sameRegistrationAnswers сравнивает ответы на анкету без учета порядка вопросов
+func sameRegistrationAnswers(a, b []models.RegistrationAnswer) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	values := make(map[uuid.UUID][]string, len(a))
+	for _, answer := range a {
+		values[answer.QuestionID] = answer.Values
+	}
+	for _, answer := range b {
+		existing, ok := values[answer.QuestionID]
+		if !ok || !slices.Equal(existing, answer.Values) {
+			return false
+		}
+	}
+	return true
+}
+
+// reserveSeat проверяет, что у типа билета и у события есть свободное место. Событие блокируется до конца
+// транзакции, чтобы параллельные заказы не превысили лимиты. exceptOrderID не учитывается при подсчете
+func (os *OrderService) reserveSeat(tx *gorm.DB, eventID, ticketTypeID, exceptOrderID uuid.UUID) error {
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "max_participants").Where("id = ?", eventID).First(&event).Error; err != nil {
+		return err
+	}
+
+	var ticketType models.TicketType
+	if err := tx.Select("id", "quota").Where("id = ?", ticketTypeID).First(&ticketType).Error; err != nil {
+		return err
+	}
+	if ticketType.Quota != nil {
+		var taken int64
+		if err := tx.Model(&models.TicketOrder{}).Where("ticket_type_id = ? AND id <> ?", ticketTypeID, exceptOrderID).
+			Scopes(activeOrders).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken >= int64(*ticketType.Quota) {
+			return ErrTicketsSoldOut
+		}
+	}
+
+	// Места события занимают участники и неоплаченные брони
+	if event.MaxParticipants != nil {
+		var participants, reserved int64
+		if err := tx.Model(&models.EventParticipant{}).Where("event_id = ?", eventID).Count(&participants).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TicketOrder{}).
+			Where("event_id = ? AND id <> ? AND status = ? AND expires_at > ?", eventID, exceptOrderID, models.OrderStatusPending, time.Now()).
+			Count(&reserved).Error; err != nil {
+			return err
+		}
+		if participants+reserved >= int64(*event.MaxParticipants) {
+			return ErrNoSeatsLeft
+		}
+	}
+	return nil
+}
+
+// markPaid отмечает заказ оплаченным и записывает покупателя участником события
+func (os *OrderService) markPaid(tx *gorm.DB, order *models.TicketOrder) error {
+	participant := models.EventParticipant{EventID: order.EventID, UserID: order.UserID}
+	if err := tx.Where("event_id = ? AND user_id = ?", order.EventID, order.UserID).FirstOrCreate(&participant).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.RegistrationAnswer{}).Where("order_id = ?", order.ID).Update("participant_id", participant.ID).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	order.Status = models.OrderStatusPaid
+	order.PaidAt = &now
+	return tx.Model(order).Updates(map[string]interface{}{
+		"status":  order.Status,
+		"paid_at": order.PaidAt,
+	}).Error
+}
+
+// ConfirmPayment обрабатывает уведомление провайдера: статус платежа перепроверяется запросом к провайдеру
+func (os *OrderService) ConfirmPayment(body []byte) error {
+	if os.provider == nil {
+		return ErrPaymentsNotConfigured
+	}
+	paymentID, err := os.provider.ParseWebhook(body)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.PaymentRequestTimeout)
+	defer cancel()
+	payment, err := os.provider.GetPayment(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	// Уведомление может прийти раньше, чем сохранится PaymentID, поэтому заказ ищется по метаданным платежа
+	if !utils.ValidateUUID(payment.OrderID) {
+		return ErrPaymentNotFound
+	}
+	var order models.TicketOrder
+	if err := database.DB.Where("id = ? AND provider = ?", payment.OrderID, os.provider.Name()).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPaymentNotFound
+		}
+		return err
+	}
+	if order.PaymentID != "" && order.PaymentID != payment.ID {
+		return ErrPaymentNotFound
+	}
+	order.PaymentID = payment.ID
+	return os.applyPayment(&order, payment)
+}
+
+// RefreshOrder запрашивает у провайдера статус платежа неоплаченного заказа, если уведомление еще не пришло
+func (os *OrderService) RefreshOrder(order *models.TicketOrder) error {
+	if order.Status != models.OrderStatusPending || order.PaymentID == "" || os.provider == nil || order.Provider != os.provider.Name() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.PaymentRequestTimeout)
+	defer cancel()
+	payment, err := os.provider.GetPayment(ctx, order.PaymentID)
+	if err != nil {
+		return err
+	}
+	return os.applyPayment(order, payment)
+}
+
+// applyPayment переводит заказ в статус по платежу. Оплата после истечения брони принимается, если место
+// еще есть, иначе деньги сразу возвращаются
+func (os *OrderService) applyPayment(order *models.TicketOrder, payment *Payment) error {
+	switch payment.Status {
+	case PaymentStatusCanceled:
+		result := database.DB.Model(&models.TicketOrder{}).Where("id = ? AND status = ?", order.ID, models.OrderStatusPending).
+			Update("status", models.OrderStatusExpired)
+		if result.RowsAffected > 0 {
+			order.Status = models.OrderStatusExpired
+		}
+		return result.Error
+	case PaymentStatusSucceeded:
+	default:
+		return nil
+	}
+
+	if payment.Amount != order.Amount {
+		return fmt.Errorf("сумма платежа %d не совпадает с суммой заказа %d", payment.Amount, order.Amount)
+	}
+
+	var paidNow, late bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.TicketOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", order.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		switch {
+		case locked.Status == models.OrderStatusPending && locked.ExpiresAt.After(time.Now()):
+		case locked.Status == models.OrderStatusPending || locked.Status == models.OrderStatusExpired:
+			if err := os.reserveSeat(tx, locked.EventID, locked.TicketTypeID, locked.ID); err != nil {
+				return err
+			}
+			late = true
+		default:
+			// Оплаченный или возвращенный заказ: повторное уведомление
+			copyOrderState(order, &locked)
+			return nil
+		}
+		if err := tx.Model(&locked).Update("payment_id", payment.ID).Error; err != nil {
+			return err
+		}
+		if err := os.markPaid(tx, &locked); err != nil {
+			return err
+		}
+		copyOrderState(order, &locked)
+		paidNow = true
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, ErrTicketsSoldOut) || errors.Is(err, ErrNoSeatsLeft):
+		os.logger.Warn("Оплата после истечения брони, мест нет: возврат", zap.String("orderID", order.ID.String()))
+		return os.refundPayment(order, "бронь истекла до оплаты, а свободных мест не осталось")
+	case err != nil:
+		return err
+	}
+
+	if paidNow {
+		if late {
+			os.logger.Info("Принята оплата после истечения брони", zap.String("orderID", order.ID.String()))
+		}
+		os.onOrderPaid(order)
+	}
+	return nil
+}
+
+// copyOrderState переносит статус заказа, не затрагивая загруженные связи
+func copyOrderState(dst, src *models.TicketOrder) {
+	dst.Status = src.Status
+	dst.PaymentID = src.PaymentID
+	dst.PaidAt = src.PaidAt
+	dst.RefundedAt = src.RefundedAt
+}
+
+// Refund возвращает деньги за оплаченный заказ и отменяет участие покупателя
+func (os *OrderService) Refund(order *models.TicketOrder, reason string) error {
+	if order.Status != models.OrderStatusPaid {
+		return ErrOrderNotPaid
+	}
+	if order.Amount > 0 {
+		if os.provider == nil || order.Provider != os.provider.Name() {
+			return ErrPaymentsNotConfigured
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), utils.PaymentRequestTimeout)
+		defer cancel()
+		if err := os.provider.Refund(ctx, order.PaymentID, order.Amount, utils.TicketCurrency); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var participant models.EventParticipant
+		err := tx.Where("event_id = ? AND user_id = ?", order.EventID, order.UserID).First(&participant).Error
+		if err == nil {
+			if err := tx.Where("participant_id = ?", participant.ID).Delete(&models.RegistrationAnswer{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ? AND session_id IN (?)", order.UserID, tx.Model(&models.EventSession{}).Select("id").Where("event_id = ?", order.EventID)).
+				Delete(&models.SessionBookmark{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("event_id = ? AND user_id = ?", order.EventID, order.UserID).Delete(&models.EventReminder{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&participant).Error; err != nil {
+				return err
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		order.Status = models.OrderStatusRefunded
+		order.RefundedAt = &now
+		return tx.Model(order).Updates(map[string]interface{}{
+			"status":      order.Status,
+			"refunded_at": order.RefundedAt,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	os.analyticsService.RecordParticipation(order.EventID, order.UserID, models.ParticipationLeft)
+	os.notifyBuyer(order, "Оплата билета возвращена, участие в событии отменено. "+reason)
+	return nil
+}
+
+// refundPayment возвращает деньги за платеж, который не удалось принять, и отмечает заказ возвращенным
+func (os *OrderService) refundPayment(order *models.TicketOrder, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.PaymentRequestTimeout)
+	defer cancel()
+	if err := os.provider.Refund(ctx, order.PaymentID, order.Amount, utils.TicketCurrency); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	order.Status = models.OrderStatusRefunded
+	order.RefundedAt = &now
+	if err := database.DB.Model(order).Updates(map[string]interface{}{
+		"status":      order.Status,
+		"payment_id":  order.PaymentID,
+		"refunded_at": order.RefundedAt,
+	}).Error; err != nil {
+		return err
+	}
+
+	os.notifyBuyer(order, "Оплата билета возвращена: "+reason)
+	return nil
+}
+
+// CloseEventOrders снимает брони и возвращает деньги за оплаченные заказы отмененного события
+func (os *OrderService) CloseEventOrders(eventID uuid.UUID) {
+	if err := database.DB.Model(&models.TicketOrder{}).Where("event_id = ? AND status = ?", eventID, models.OrderStatusPending).
+		Update("status", models.OrderStatusExpired).Error; err != nil {
+		os.logger.Error("Ошибка снятия броней отмененного события", zap.String("eventID", eventID.String()), zap.Error(err))
+	}
+
+	var orders []models.TicketOrder
+	if err := database.DB.Where("event_id = ? AND status = ?", eventID, models.OrderStatusPaid).Find(&orders).Error; err != nil {
+		os.logger.Error("Ошибка получения заказов отмененного события", zap.String("eventID", eventID.String()), zap.Error(err))
+		return
+	}
+	for i := range orders {
+		if err := os.Refund(&orders[i], "Событие отменено."); err != nil {
+			os.logger.Error("Ошибка возврата оплаты за отмененное событие", zap.String("orderID", orders[i].ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// ExpireReservations снимает брони с неоплаченных заказов, срок которых истек. Если оплата все же придет,
+// заказ будет принят при наличии мест (см. applyPayment)
+func (os *OrderService) ExpireReservations() {
+	result := database.DB.Model(&models.TicketOrder{}).
+		Where("status = ? AND expires_at <= ?", models.OrderStatusPending, time.Now()).
+		Update("status", models.OrderStatusExpired)
+	if result.Error != nil {
+		os.logger.Error("Ошибка снятия истекших броней", zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		os.logger.Info("Сняты истекшие брони билетов", zap.Int64("count", result.RowsAffected))
+	}
+}
+
+func (os *OrderService) onOrderPaid(order *models.TicketOrder) {
+	os.analyticsService.RecordParticipation(order.EventID, order.UserID, models.ParticipationJoined)
+
+	var event models.Event
+	if err := database.DB.Where("id = ?", order.EventID).First(&event).Error; err != nil {
+		os.logger.Error("Ошибка получения события оплаченного заказа", zap.String("orderID", order.ID.String()), zap.Error(err))
+		return
+	}
+	var user models.User
+	if err := database.DB.Where("id = ?", order.UserID).First(&user).Error; err != nil {
+		os.logger.Error("Ошибка получения покупателя", zap.String("orderID", order.ID.String()), zap.Error(err))
+		return
+	}
+
+	go os.webhookService.Dispatch(models.WebhookEventParticipantJoined, &event, map[string]interface{}{
+		"participant": WebhookUserData{
+			ID:       user.ID.String(),
+			FullName: user.FullName,
+		},
+	})
+	go os.emailService.SendEventNotification(user.Email, event.Title,
+		"Билет оформлен, вы участник события. Начало: "+utils.FormatEventTime(event.StartDate, event.Timezone))
+}
+
+func (os *OrderService) notifyBuyer(order *models.TicketOrder, message string) {
+	var event models.Event
+	var user models.User
+	if err := database.DB.Select("id", "title").Where("id = ?", order.EventID).First(&event).Error; err != nil {
+		return
+	}
+	if err := database.DB.Select("id", "email").Where("id = ?", order.UserID).First(&user).Error; err != nil {
+		return
+	}
+	go os.emailService.SendEventNotification(user.Email, event.Title, message)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"bekend/database"
+	"bekend/models"
+
+	"github.com/google/uuid"
+)
+
+func createTestTicketType(t *testing.T, eventID uuid.UUID, price int64) models.TicketType {
+	t.Helper()
+	ticketType := models.TicketType{EventID: eventID, Name: "Входной билет", Price: price}
+	if err := database.DB.Create(&ticketType).Error; err != nil {
+		t.Fatalf("Ошибка создания типа билета: %v", err)
+	}
+	return ticketType
+}
+
+// expireTestOrder переносит срок брони в прошлое и снимает ее, как это делает cron-задача
+func expireTestOrder(t *testing.T, orderService *OrderService, order *models.TicketOrder) {
+	t.Helper()
+	if err := database.DB.Model(order).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("Ошибка переноса срока брони: %v", err)
+	}
+	orderService.ExpireReservations()
+	assertOrderStatus(t, order.ID, models.OrderStatusExpired)
+}
+
+func assertOrderStatus(t *testing.T, orderID uuid.UUID, want models.OrderStatus) {
+	t.Helper()
+	var order models.TicketOrder
+	if err := database.DB.Where("id = ?", orderID).First(&order).Error; err != nil {
+		t.Fatalf("Ошибка получения заказа: %v", err)
+	}
+	if order.Status != want {
+		t.Errorf("статус заказа %q, ожидался %q", order.Status, want)
+	}
+}
+
+func isTestParticipant(t *testing.T, eventID, userID uuid.UUID) bool {
+	t.Helper()
+	var count int64
+	database.DB.Model(&models.EventParticipant{}).Where("event_id = ? AND user_id = ?", eventID, userID).Count(&count)
+	return count > 0
+}
+
+func paymentNotification(paymentID string) []byte {
+	return []byte(fmt.Sprintf(`{"paymentID": %q}`, paymentID))
+}
+
+func TestPlaceOrderRequiresPaymentProvider(t *testing.T) {
+	orderService := NewOrderServiceWithProvider(nil)
+	_, err := orderService.PlaceOrder(&models.Event{ID: uuid.New()}, &models.TicketType{ID: uuid.New(), Price: 100000}, uuid.New(), nil)
+	if !errors.Is(err, ErrPaymentsNotConfigured) {
+		t.Errorf("ожидалась ErrPaymentsNotConfigured, получено %v", err)
+	}
+
+	if err := orderService.ConfirmPayment(paymentNotification("p1")); !errors.Is(err, ErrPaymentsNotConfigured) {
+		t.Errorf("уведомление без провайдера: ожидалась ErrPaymentsNotConfigured, получено %v", err)
+	}
+}
+
+func TestConfirmPaymentUnknownPayment(t *testing.T) {
+	orderService := NewOrderServiceWithProvider(NewFakePaymentProvider(false))
+	if err := orderService.ConfirmPayment(paymentNotification("unknown")); !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("ожидалась ErrPaymentNotFound, получено %v", err)
+	}
+	if err := orderService.ConfirmPayment([]byte(`{}`)); !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("уведомление без paymentID: ожидалась ErrPaymentNotFound, получено %v", err)
+	}
+}
+
+func TestReservationHoldsSeatUntilExpiry(t *testing.T) {
+	requireTestDB(t)
+
+	provider := NewFakePaymentProvider(false)
+	orderService := NewOrderServiceWithProvider(provider)
+	organizer := createTestUser(t, 0)
+	first := createTestUser(t, 0)
+	second := createTestUser(t, 0)
+	event := createTestEvent(t, organizer.ID, 1)
+	ticketType := createTestTicketType(t, event.ID, 150000)
+
+	order, err := orderService.PlaceOrder(&event, &ticketType, first.ID, nil)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if order.Status != models.OrderStatusPending || order.PaymentID == "" {
+		t.Fatalf("ожидался неоплаченный заказ с платежом, получено %+v", order)
+	}
+
+	// Повторный заказ того же билета с теми же ответами возвращает действующую бронь
+	again, err := orderService.PlaceOrder(&event, &ticketType, first.ID, nil)
+	if err != nil || again.ID != order.ID {
+		t.Errorf("повторный заказ должен вернуть бронь %s, получено %v, %v", order.ID, again, err)
+	}
+
+	// Единственное место занято бронью
+	if _, err := orderService.PlaceOrder(&event, &ticketType, second.ID, nil); !errors.Is(err, ErrNoSeatsLeft) {
+		t.Fatalf("ожидалась ErrNoSeatsLeft, получено %v", err)
+	}
+
+	expireTestOrder(t, orderService, order)
+
+	secondOrder, err := orderService.PlaceOrder(&event, &ticketType, second.ID, nil)
+	if err != nil {
+		t.Fatalf("после истечения брони место должно освободиться: %v", err)
+	}
+
+	provider.Succeed(secondOrder.PaymentID)
+	if err := orderService.ConfirmPayment(paymentNotification(secondOrder.PaymentID)); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+	assertOrderStatus(t, secondOrder.ID, models.OrderStatusPaid)
+	if !isTestParticipant(t, event.ID, second.ID) {
+		t.Error("после оплаты покупатель должен стать участником")
+	}
+}
+
+func TestLatePaymentRefundedWhenSeatTaken(t *testing.T) {
+	requireTestDB(t)
+
+	provider := NewFakePaymentProvider(false)
+	orderService := NewOrderServiceWithProvider(provider)
+	organizer := createTestUser(t, 0)
+	late := createTestUser(t, 0)
+	other := createTestUser(t, 0)
+	event := createTestEvent(t, organizer.ID, 1)
+	ticketType := createTestTicketType(t, event.ID, 150000)
+
+	lateOrder, err := orderService.PlaceOrder(&event, &ticketType, late.ID, nil)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	expireTestOrder(t, orderService, lateOrder)
+
+	// Пока бронь была снята, место купил другой пользователь
+	otherOrder, err := orderService.PlaceOrder(&event, &ticketType, other.ID, nil)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	provider.Succeed(otherOrder.PaymentID)
+	if err := orderService.ConfirmPayment(paymentNotification(otherOrder.PaymentID)); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+
+	// Оплата по истекшей брони приходит, когда мест уже нет: деньги возвращаются
+	provider.Succeed(lateOrder.PaymentID)
+	if err := orderService.ConfirmPayment(paymentNotification(lateOrder.PaymentID)); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+	assertOrderStatus(t, lateOrder.ID, models.OrderStatusRefunded)
+	if len(provider.Refunds) != 1 || provider.Refunds[0] != lateOrder.PaymentID {
+		t.Errorf("ожидался возврат платежа %s, возвраты: %v", lateOrder.PaymentID, provider.Refunds)
+	}
+	if isTestParticipant(t, event.ID, late.ID) {
+		t.Error("покупатель с возвращенной оплатой не должен стать участником")
+	}
+
+	// Повторное уведомление не возвращает деньги второй раз
+	if err := orderService.ConfirmPayment(paymentNotification(lateOrder.PaymentID)); err != nil {
+		t.Fatalf("повторный ConfirmPayment: %v", err)
+	}
+	if len(provider.Refunds) != 1 {
+		t.Errorf("повторное уведомление не должно создавать возврат, возвраты: %v", provider.Refunds)
+	}
+}
+
+func TestLatePaymentAcceptedWhenSeatFree(t *testing.T) {
+	requireTestDB(t)
+
+	provider := NewFakePaymentProvider(false)
+	orderService := NewOrderServiceWithProvider(provider)
+	organizer := createTestUser(t, 0)
+	buyer := createTestUser(t, 0)
+	event := createTestEvent(t, organizer.ID, 1)
+	ticketType := createTestTicketType(t, event.ID, 150000)
+
+	order, err := orderService.PlaceOrder(&event, &ticketType, buyer.ID, nil)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	expireTestOrder(t, orderService, order)
+
+	provider.Succeed(order.PaymentID)
+	if err := orderService.ConfirmPayment(paymentNotification(order.PaymentID)); err != nil {
+		t.Fatalf("ConfirmPayment: %v", err)
+	}
+	assertOrderStatus(t, order.ID, models.OrderStatusPaid)
+	if len(provider.Refunds) != 0 {
+		t.Errorf("при свободном месте оплата принимается без возврата, возвраты: %v", provider.Refunds)
+	}
+	if !isTestParticipant(t, event.ID, buyer.ID) {
+		t.Error("после оплаты покупатель должен стать участником")
+	}
+}
+
+func TestPlaceOrderReplacesPendingOrderForOtherTicketType(t *testing.T) {
+	requireTestDB(t)
+
+	orderService := NewOrderServiceWithProvider(NewFakePaymentProvider(false))
+	organizer := createTestUser(t, 0)
+	buyer := createTestUser(t, 0)
+	event := createTestEvent(t, organizer.ID, 1)
+	standard := createTestTicketType(t, event.ID, 150000)
+	vip := createTestTicketType(t, event.ID, 500000)
+
+	order, err := orderService.PlaceOrder(&event, &standard, buyer.ID, nil)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+
+	// Другой тип билета: прежняя бронь снимается, а ее место (единственное на событии) переходит новому заказу
+	replacement, err := orderService.PlaceOrder(&event, &vip, buyer.ID, nil)
+	if err != nil {
+		t.Fatalf("заказ другого типа билета: %v", err)
+	}
+	if replacement.ID == order.ID || replacement.TicketTypeID != vip.ID || replacement.Amount != vip.Price {
+		t.Errorf("ожидался новый заказ на билет %s за %d, получено %+v", vip.ID, vip.Price, replacement)
+	}
+	assertOrderStatus(t, order.ID, models.OrderStatusExpired)
+	assertOrderStatus(t, replacement.ID, models.OrderStatusPending)
+}
+
+func TestSameRegistrationAnswers(t *testing.T) {
+	company, size := uuid.New(), uuid.New()
+	answers := []models.RegistrationAnswer{
+		{QuestionID: company, Values: models.StringArray{"ООО Ромашка"}},
+		{QuestionID: size, Values: models.StringArray{"M"}},
+	}
+
+	reordered := []models.RegistrationAnswer{answers[1], answers[0]}
+	if !sameRegistrationAnswers(answers, reordered) {
+		t.Error("порядок ответов не должен учитываться")
+	}
+	changed := []models.RegistrationAnswer{answers[0], {QuestionID: size, Values: models.StringArray{"L"}}}
+	if sameRegistrationAnswers(answers, changed) {
+		t.Error("измененный ответ должен считаться отличием")
+	}
+	if sameRegistrationAnswers(answers, answers[:1]) {
+		t.Error("пропущенный ответ должен считаться отличием")
+	}
+	if !sameRegistrationAnswers(nil, nil) {
+		t.Error("заказы без анкеты совпадают")
+	}
+}
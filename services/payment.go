@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bekend/utils"
+
+	"github.com/google/uuid"
+)
+
+const yookassaAPIURL = "https://api.yookassa.ru/v3"
+
+var (
+	ErrPaymentsNotConfigured = errors.New("оплата не настроена")
+	ErrPaymentNotFound       = errors.New("платеж не найден")
+)
+
+// PaymentStatus - статус платежа у провайдера
+type PaymentStatus string
+
+const (
+	PaymentStatusPending   PaymentStatus = "pending"   // Ожидает оплаты
+	PaymentStatusSucceeded PaymentStatus = "succeeded" // Оплачен
+	PaymentStatusCanceled  PaymentStatus = "canceled"  // Отменен или истек
+)
+
+// PaymentRequest - данные для создания платежа. Amount указывается в копейках
+type PaymentRequest struct {
+	OrderID     uuid.UUID
+	Amount      int64
+	Currency    string
+	Description string
+	ReturnURL   string // Куда провайдер вернет покупателя после оплаты
+}
+
+type Payment struct {
+	ID              string
+	Status          PaymentStatus
+	Amount          int64
+	OrderID         string // Идентификатор заказа из метаданных платежа
+	ConfirmationURL string
+}
+
+// PaymentProvider - платежный провайдер. Уведомлениям провайдера не доверяем: ParseWebhook только извлекает
+// идентификатор платежа, а его статус подтверждается запросом GetPayment
+type PaymentProvider interface {
+	Name() string
+	CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error)
+	GetPayment(ctx context.Context, paymentID string) (*Payment, error)
+	Refund(ctx context.Context, paymentID string, amount int64, currency string) error
+	ParseWebhook(body []byte) (string, error)
+}
+
+type yookassaProvider struct {
+	baseURL   string
+	shopID    string
+	secretKey string
+	client    *http.Client
+}
+
+func NewYooKassaProvider(baseURL, shopID, secretKey string) PaymentProvider {
+	return &yookassaProvider{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		shopID:    shopID,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: utils.PaymentRequestTimeout},
+	}
+}
+
+type yookassaAmount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type yookassaPayment struct {
+	ID           string            `json:"id"`
+	Status       string            `json:"status"`
+	Amount       yookassaAmount    `json:"amount"`
+	Metadata     map[string]string `json:"metadata"`
+	Confirmation struct {
+		ConfirmationURL string `json:"confirmation_url"`
+	} `json:"confirmation"`
+}
+
+type yookassaNotification struct {
+	Type   string          `json:"type"`
+	Event  string          `json:"event"`
+	Object yookassaPayment `json:"object"`
+}
+
+func (yp *yookassaProvider) Name() string {
+	return "yookassa"
+}
+
+func (yp *yookassaProvider) CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error) {
+	body := map[string]interface{}{
+		"amount":  yookassaAmount{Value: formatKopecks(req.Amount), Currency: req.Currency},
+		"capture": true,
+		"confirmation": map[string]string{
+			"type":       "redirect",
+			"return_url": req.ReturnURL,
+		},
+		"description": req.Description,
+		"metadata":    map[string]string{"order_id": req.OrderID.String()},
+	}
+
+	var resp yookassaPayment
+	// Ключ идемпотентности - заказ: повторный запрос после сетевой ошибки не создаст второй платеж
+	if err := yp.do(ctx, http.MethodPost, "/payments", req.OrderID.String(), body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.toPayment()
+}
+
+func (yp *yookassaProvider) GetPayment(ctx context.Context, paymentID string) (*Payment, error) {
+	var resp yookassaPayment
+	if err := yp.do(ctx, http.MethodGet, "/payments/"+paymentID, "", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.toPayment()
+}
+
+func (yp *yookassaProvider) Refund(ctx context.Context, paymentID string, amount int64, currency string) error {
+	body := map[string]interface{}{
+		"payment_id": paymentID,
+		"amount":     yookassaAmount{Value: formatKopecks(amount), Currency: currency},
+	}
+	return yp.do(ctx, http.MethodPost, "/refunds", "refund-"+paymentID, body, nil)
+}
+
+func (yp *yookassaProvider) ParseWebhook(body []byte) (string, error) {
+	var notification yookassaNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return "", fmt.Errorf("ошибка парсинга уведомления ЮKassa: %w", err)
+	}
+	if notification.Object.ID == "" {
+		return "", ErrPaymentNotFound
+	}
+	return notification.Object.ID, nil
+}
+
+func (yp *yookassaProvider) do(ctx context.Context, method, path, idempotenceKey string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, yp.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(yp.shopID, yp.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+	if idempotenceKey != "" {
+		req.Header.Set("Idempotence-Key", idempotenceKey)
+	}
+
+	resp, err := yp.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrPaymentNotFound
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Description string `json:"description"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("ошибка ЮKassa (%s %s): %d %s", method, path, resp.StatusCode, apiErr.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("ошибка парсинга ответа ЮKassa: %w", err)
+	}
+	return nil
+}
+
+func (p *yookassaPayment) toPayment() (*Payment, error) {
+	amount, err := parseKopecks(p.Amount.Value)
+	if err != nil {
+		return nil, fmt.Errorf("неверная сумма платежа ЮKassa %q: %w", p.Amount.Value, err)
+	}
+
+	// waiting_for_capture не встречается при capture=true, но для заказа это тоже еще не оплата
+	status := PaymentStatusPending
+	switch p.Status {
+	case "succeeded":
+		status = PaymentStatusSucceeded
+	case "canceled":
+		status = PaymentStatusCanceled
+	}
+
+	return &Payment{
+		ID:              p.ID,
+		Status:          status,
+		Amount:          amount,
+		OrderID:         p.Metadata["order_id"],
+		ConfirmationURL: p.Confirmation.ConfirmationURL,
+	}, nil
+}
+
+// formatKopecks переводит сумму в копейках в строку "1500.00"
+func formatKopecks(amount int64) string {
+	return fmt.Sprintf("%d.%02d", amount/100, amount%100)
+}
+
+func parseKopecks(value string) (int64, error) {
+	rubles, kopecks, _ := strings.Cut(value, ".")
+	r, err := strconv.ParseInt(rubles, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	var k int64
+	if kopecks != "" {
+		if len(kopecks) == 1 {
+			kopecks += "0"
+		}
+		if k, err = strconv.ParseInt(kopecks[:2], 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	return r*100 + k, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FakePaymentProvider - локальная реализация PaymentProvider без сетевых запросов.
+// Используется в режиме FAKE_PAYMENTS и в тестах. С AutoSucceed платеж оплачен сразу при создании,
+// иначе остается в ожидании до вызова Succeed или Cancel. Уведомление имеет вид {"paymentID": "..."}
+type FakePaymentProvider struct {
+	mu          sync.Mutex
+	AutoSucceed bool
+	Payments    map[string]*Payment
+	Refunds     []string
+}
+
+func NewFakePaymentProvider(autoSucceed bool) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		AutoSucceed: autoSucceed,
+		Payments:    make(map[string]*Payment),
+	}
+}
+
+func (f *FakePaymentProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakePaymentProvider) CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment := &Payment{
+		ID:              uuid.New().String(),
+		Status:          PaymentStatusPending,
+		Amount:          req.Amount,
+		OrderID:         req.OrderID.String(),
+		ConfirmationURL: req.ReturnURL,
+	}
+	if f.AutoSucceed {
+		payment.Status = PaymentStatusSucceeded
+	}
+	f.Payments[payment.ID] = payment
+
+	utils.GetLogger().Info("Фейковый платеж",
+		zap.String("paymentID", payment.ID),
+		zap.String("orderID", payment.OrderID),
+		zap.Int64("amount", payment.Amount),
+	)
+	copied := *payment
+	return &copied, nil
+}
+
+func (f *FakePaymentProvider) GetPayment(ctx context.Context, paymentID string) (*Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.Payments[paymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+func (f *FakePaymentProvider) Refund(ctx context.Context, paymentID string, amount int64, currency string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.Payments[paymentID]
+	if !ok {
+		return ErrPaymentNotFound
+	}
+	if payment.Status != PaymentStatusSucceeded {
+		return fmt.Errorf("платеж %s не оплачен", paymentID)
+	}
+	f.Refunds = append(f.Refunds, paymentID)
+	return nil
+}
+
+func (f *FakePaymentProvider) ParseWebhook(body []byte) (string, error) {
+	var notification struct {
+		PaymentID string `json:"paymentID"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return "", err
+	}
+	if notification.PaymentID == "" {
+		return "", ErrPaymentNotFound
+	}
+	return notification.PaymentID, nil
+}
+
+// Succeed отмечает платеж оплаченным, как после оплаты покупателем
+func (f *FakePaymentProvider) Succeed(paymentID string) {
+	f.setStatus(paymentID, PaymentStatusSucceeded)
+}
+
+// Cancel отмечает платеж отмененным, как после истечения срока оплаты
+func (f *FakePaymentProvider) Cancel(paymentID string) {
+	f.setStatus(paymentID, PaymentStatusCanceled)
+}
+
+func (f *FakePaymentProvider) setStatus(paymentID string, status PaymentStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if payment, ok := f.Payments[paymentID]; ok {
+		payment.Status = status
+	}
+}
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type RecommendationReasonType string
+
+const (
+	RecommendationReasonInterest       RecommendationReasonType = "interest"
+	RecommendationReasonCategory       RecommendationReasonType = "category"
+	RecommendationReasonTag            RecommendationReasonType = "tag"
+	RecommendationReasonMatches        RecommendationReasonType = "matches"
+	RecommendationReasonMatchesFriends RecommendationReasonType = "matchesFriends"
+	RecommendationReasonCommunity      RecommendationReasonType = "community"
+)
+
+// Веса сигналов рекомендаций. Вес интереса пользователя (1-10) умножается на recommendationInterestWeight,
+// остальные сигналы считаются за каждое совпадение и ограничены сверху, чтобы один сигнал не перекрывал остальные
+const (
+	recommendationInterestWeight        = 1.0
+	recommendationCategoryWeight        = 2.0
+	recommendationTagWeight             = 1.0
+	recommendationMatchWeight           = 4.0
+	recommendationMatchFriendWeight     = 1.5
+	recommendationCommunityMemberWeight = 2.0
+	recommendationCommunityWeight       = 3.0
+	recommendationMaxHistoryHits        = 5
+	recommendationMaxPeopleHits         = 5
+)
+
+type RecommendationReason struct {
+	Type  RecommendationReasonType
+	Text  string
+	Score float64
+}
+
+type RecommendedEvent struct {
+	Event   models.Event
+	Score   float64
+	Reasons []RecommendationReason
+}
+
+type RecommendationService struct {
+	logger *zap.Logger
+}
+
+func NewRecommendationService() *RecommendationService {
+	return &RecommendationService{
+		logger: utils.GetLogger(),
+	}
+}
+
+// recommendationProfile - сигналы пользователя, по которым оцениваются события
+type recommendationProfile struct {
+	interests      []models.UserInterest
+	joinedEventIDs []uuid.UUID
+	categoryVisits map[string]int // название категории -> число посещенных событий
+	tagVisits      map[string]int // тег в нижнем регистре -> число посещенных событий
+	matchIDs       []uuid.UUID    // пользователи, с которыми принят запрос на совместный поход
+	matchFriendIDs []uuid.UUID    // их собственные мэтчи
+	communities    []models.MicroCommunity
+}
+
+// GetRecommendedEvents возвращает предстоящие события, отсортированные по соответствию профилю пользователя.
+// События, на которые пользователь уже записан, подал заявку или которые он организует, не попадают в выдачу
+func (rs *RecommendationService) GetRecommendedEvents(userID uuid.UUID, limit int) ([]RecommendedEvent, error) {
+	profile, err := rs.loadProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Кандидаты заранее отбираются по сигналам профиля, иначе при большом числе событий лимит заполнят ближайшие неподходящие
+	signals, signalArgs := profile.candidateCondition(userID)
+	if signals == "" {
+		return []RecommendedEvent{}, nil
+	}
+
+	query := database.DB.Preload("Organizer").Preload("Participants").Preload("Categories").
+		Where("status = ? AND visibility = ? AND start_date > ? AND organizer_id <> ?", models.EventStatusActive, models.EventVisibilityPublic, time.Now(), userID).
+		Where("id NOT IN (?)", database.DB.Model(&models.EventApplication{}).Select("event_id").Where("user_id = ?", userID)).
+		Where(signals, signalArgs...)
+	if len(profile.joinedEventIDs) > 0 {
+		query = query.Where("id NOT IN ?", profile.joinedEventIDs)
+	}
+
+	var candidates []models.Event
+	if err := query.Order("start_date ASC").Limit(utils.RecommendationCandidateLimit).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []RecommendedEvent{}, nil
+	}
+
+	candidateIDs := make([]uuid.UUID, len(candidates))
+	for i, event := range candidates {
+		candidateIDs[i] = event.ID
+	}
+
+	matchCounts, err := rs.countAttendees(candidateIDs, profile.matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	matchFriendCounts, err := rs.countAttendees(candidateIDs, profile.matchFriendIDs)
+	if err != nil {
+		return nil, err
+	}
+	communityMemberCounts, err := rs.countCommunityAttendees(candidateIDs, userID, profile.communities)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]RecommendedEvent, 0, len(candidates))
+	for _, event := range candidates {
+		var reasons []RecommendationReason
+		addReason := func(reasonType RecommendationReasonType, score float64, text string) {
+			if score > 0 {
+				reasons = append(reasons, RecommendationReason{Type: reasonType, Text: text, Score: score})
+			}
+		}
+
+		var interestNames []string
+		interestScore := 0.0
+		for _, ui := range profile.interests {
+			if eventMatchesInterest(&event, ui.Interest) {
+				interestNames = append(interestNames, ui.Interest.Name)
+				interestScore += float64(ui.Weight) * recommendationInterestWeight
+			}
+		}
+		addReason(RecommendationReasonInterest, interestScore, "Совпадает с вашими интересами: "+strings.Join(interestNames, ", "))
+
+		var categoryNames []string
+		categoryHits := 0
+		for _, category := range event.Categories {
+			if visits := profile.categoryVisits[category.Name]; visits > 0 {
+				categoryNames = append(categoryNames, "«"+category.Name+"»")
+				categoryHits += visits
+			}
+		}
+		addReason(RecommendationReasonCategory, float64(min(categoryHits, recommendationMaxHistoryHits))*recommendationCategoryWeight,
+			"Вы уже ходили на события категории "+strings.Join(categoryNames, ", "))
+
+		var tagNames []string
+		tagHits := 0
+		for _, tag := range event.Tags {
+			if visits := profile.tagVisits[strings.ToLower(strings.TrimSpace(tag))]; visits > 0 {
+				tagNames = append(tagNames, "«"+tag+"»")
+				tagHits += visits
+			}
+		}
+		addReason(RecommendationReasonTag, float64(min(tagHits, recommendationMaxHistoryHits))*recommendationTagWeight,
+			"Похоже на ваши прошлые события: "+strings.Join(tagNames, ", "))
+
+		if n := matchCounts[event.ID]; n > 0 {
+			addReason(RecommendationReasonMatches, float64(min(n, recommendationMaxPeopleHits))*recommendationMatchWeight,
+				fmt.Sprintf("Идут %d %s, с которыми вы уже ходили вместе", n, utils.PluralRu(n, "человек", "человека", "человек")))
+		}
+		if n := matchFriendCounts[event.ID]; n > 0 {
+			addReason(RecommendationReasonMatchesFriends, float64(min(n, recommendationMaxPeopleHits))*recommendationMatchFriendWeight,
+				fmt.Sprintf("Идут %d %s ваших знакомых", n, utils.PluralRu(n, "знакомый", "знакомых", "знакомых")))
+		}
+
+		if n := communityMemberCounts[event.ID]; n > 0 {
+			addReason(RecommendationReasonCommunity, float64(min(n, recommendationMaxPeopleHits))*recommendationCommunityMemberWeight,
+				fmt.Sprintf("Идут %d %s ваших сообществ", n, utils.PluralRu(n, "участник", "участника", "участников")))
+		}
+		var communityNames []string
+		for _, community := range profile.communities {
+			for _, interest := range community.Interests {
+				if eventMatchesInterest(&event, interest) {
+					communityNames = append(communityNames, "«"+community.Name+"»")
+					break
+				}
+			}
+		}
+		addReason(RecommendationReasonCommunity, float64(len(communityNames))*recommendationCommunityWeight,
+			"Подходит вашим сообществам: "+strings.Join(communityNames, ", "))
+
+		if len(reasons) == 0 {
+			continue
+		}
+
+		sort.SliceStable(reasons, func(i, j int) bool { return reasons[i].Score > reasons[j].Score })
+		score := 0.0
+		for _, reason := range reasons {
+			score += reason.Score
+		}
+		result = append(result, RecommendedEvent{Event: event, Score: score, Reasons: reasons})
+	}
+
+	// При равной оценке выше то, что начнется раньше: кандидаты уже отсортированы по дате
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// candidateCondition возвращает SQL-условие, которому удовлетворяют события хотя бы с одной причиной рекомендации:
+// категория или тег по интересам и истории, записавшиеся мэтчи, их знакомые или участники сообществ пользователя.
+// Пустое условие означает, что сигналов нет
+func (p *recommendationProfile) candidateCondition(userID uuid.UUID) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	categorySet := make(map[string]bool)
+	var interestNames []string
+	addInterest := func(interest models.Interest) {
+		if interest.Category != "" {
+			categorySet[interest.Category] = true
+		}
+		if name := strings.ToLower(interest.Name); name != "" {
+			interestNames = append(interestNames, name)
+		}
+	}
+	for _, ui := range p.interests {
+		addInterest(ui.Interest)
+	}
+	for _, community := range p.communities {
+		for _, interest := range community.Interests {
+			addInterest(interest)
+		}
+	}
+	for name := range p.categoryVisits {
+		categorySet[name] = true
+	}
+
+	if len(categorySet) > 0 {
+		categories := make([]string, 0, len(categorySet))
+		for name := range categorySet {
+			categories = append(categories, name)
+		}
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM event_categories JOIN categories ON categories.id = event_categories.category_id
+			WHERE event_categories.event_id = events.id AND categories.name IN ?)`)
+		args = append(args, categories)
+	}
+
+	// Теги сравниваются как в eventMatchesInterest: точное совпадение с тегами истории или вхождение названия интереса
+	var tagConditions []string
+	if len(p.tagVisits) > 0 {
+		tags := make([]string, 0, len(p.tagVisits))
+		for tag := range p.tagVisits {
+			tags = append(tags, tag)
+		}
+		tagConditions = append(tagConditions, "lower(trim(tag)) IN ?")
+		args = append(args, tags)
+	}
+	for _, name := range interestNames {
+		tagConditions = append(tagConditions, "strpos(?, lower(trim(tag))) > 0", "strpos(lower(trim(tag)), ?) > 0")
+		args = append(args, name, name)
+	}
+	if len(tagConditions) > 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM unnest(events.tags) AS tag WHERE trim(tag) <> '' AND ("+strings.Join(tagConditions, " OR ")+"))")
+	}
+
+	people := append(append([]uuid.UUID{}, p.matchIDs...), p.matchFriendIDs...)
+	if len(people) > 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM event_participants WHERE event_participants.event_id = events.id AND event_participants.user_id IN ?)")
+		args = append(args, people)
+	}
+
+	if len(p.communities) > 0 {
+		communityIDs := make([]uuid.UUID, len(p.communities))
+		for i, community := range p.communities {
+			communityIDs[i] = community.ID
+		}
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM event_participants JOIN community_members ON community_members.user_id = event_participants.user_id
+			WHERE event_participants.event_id = events.id AND community_members.community_id IN ? AND event_participants.user_id <> ?)`)
+		args = append(args, communityIDs, userID)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+func (rs *RecommendationService) loadProfile(userID uuid.UUID) (*recommendationProfile, error) {
+	profile := &recommendationProfile{
+		categoryVisits: make(map[string]int),
+		tagVisits:      make(map[string]int),
+	}
+
+	if err := database.DB.Preload("Interest").Where("user_id = ?", userID).Find(&profile.interests).Error; err != nil {
+		return nil, err
+	}
+
+	var participations []models.EventParticipant
+	if err := database.DB.Preload("Event.Categories").Where("user_id = ?", userID).Find(&participations).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range participations {
+		profile.joinedEventIDs = append(profile.joinedEventIDs, p.EventID)
+		for _, category := range p.Event.Categories {
+			profile.categoryVisits[category.Name]++
+		}
+		for _, tag := range p.Event.Tags {
+			profile.tagVisits[strings.ToLower(strings.TrimSpace(tag))]++
+		}
+	}
+
+	var err error
+	if profile.matchIDs, err = acceptedMatchUserIDs([]uuid.UUID{userID}); err != nil {
+		return nil, err
+	}
+	if len(profile.matchIDs) > 0 {
+		friends, err := acceptedMatchUserIDs(profile.matchIDs)
+		if err != nil {
+			return nil, err
+		}
+		exclude := map[uuid.UUID]bool{userID: true}
+		for _, id := range profile.matchIDs {
+			exclude[id] = true
+		}
+		for _, id := range friends {
+			if !exclude[id] {
+				profile.matchFriendIDs = append(profile.matchFriendIDs, id)
+			}
+		}
+	}
+
+	if err := database.DB.Preload("Interests").
+		Where("id IN (?)", database.DB.Model(&models.CommunityMember{}).Select("community_id").Where("user_id = ?", userID)).
+		Find(&profile.communities).Error; err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// acceptedMatchUserIDs возвращает собеседников пользователей userIDs по принятым запросам на совместный поход
+func acceptedMatchUserIDs(userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := database.DB.Raw(`
+		SELECT DISTINCT CASE WHEN from_user_id IN @ids THEN to_user_id ELSE from_user_id END
+		FROM match_requests
+		WHERE status = @status AND (from_user_id IN @ids OR to_user_id IN @ids)`,
+		map[string]interface{}{"ids": userIDs, "status": models.MatchRequestStatusAccepted},
+	).Scan(&ids).Error
+	return ids, err
+}
+
+type eventAttendeeCount struct {
+	EventID uuid.UUID
+	Count   int
+}
+
+// countAttendees считает, сколько из пользователей userIDs записано на каждое из событий
+func (rs *RecommendationService) countAttendees(eventIDs, userIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int)
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []eventAttendeeCount
+	if err := database.DB.Model(&models.EventParticipant{}).
+		Select("event_id, COUNT(DISTINCT user_id) AS count").
+		Where("event_id IN ? AND user_id IN ?", eventIDs, userIDs).
+		Group("event_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.EventID] = row.Count
+	}
+	return counts, nil
+}
+
+// countCommunityAttendees считает записавшихся на события участников сообществ пользователя
+func (rs *RecommendationService) countCommunityAttendees(eventIDs []uuid.UUID, userID uuid.UUID, communities []models.MicroCommunity) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int)
+	if len(communities) == 0 {
+		return counts, nil
+	}
+	communityIDs := make([]uuid.UUID, len(communities))
+	for i, community := range communities {
+		communityIDs[i] = community.ID
+	}
+
+	var rows []eventAttendeeCount
+	if err := database.DB.Table("event_participants").
+		Select("event_participants.event_id, COUNT(DISTINCT event_participants.user_id) AS count").
+		Joins("JOIN community_members ON community_members.user_id = event_participants.user_id").
+		Where("event_participants.event_id IN ? AND community_members.community_id IN ? AND event_participants.user_id <> ?", eventIDs, communityIDs, userID).
+		Group("event_participants.event_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.EventID] = row.Count
+	}
+	return counts, nil
+}
+
+// eventMatchesInterest сопоставляет интерес с событием так же, как уведомления сообществ:
+// по категории интереса и вхождению названия интереса в теги
+func eventMatchesInterest(event *models.Event, interest models.Interest) bool {
+	if interest.Category != "" {
+		for _, category := range event.Categories {
+			if category.Name == interest.Category {
+				return true
+			}
+		}
+	}
+
+	interestName := strings.ToLower(interest.Name)
+	for _, tag := range event.Tags {
+		tagLower := strings.ToLower(strings.TrimSpace(tag))
+		if tagLower == "" {
+			continue
+		}
+		if strings.Contains(interestName, tagLower) || strings.Contains(tagLower, interestName) {
+			return true
+		}
+	}
+	return false
+}
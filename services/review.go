@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"bekend/config"
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrReviewInvitationInvalid = errors.New("ссылка недействительна или устарела")
+	ErrReviewEventNotPast      = errors.New("отзыв можно оставить только для прошедших событий")
+	ErrReviewNotParticipant    = errors.New("вы не участвовали в этом событии")
+	ErrReviewAlreadyExists     = errors.New("вы уже оставили отзыв на это событие")
+)
+
+type ReviewService struct {
+	emailService    *EmailService
+	telegramService *TelegramService
+	webhookService  *WebhookService
+	logger          *zap.Logger
+}
+
+func NewReviewService() *ReviewService {
+	return &ReviewService{
+		emailService:    NewEmailService(),
+		telegramService: NewTelegramService(),
+		webhookService:  NewWebhookService(),
+		logger:          utils.GetLogger(),
+	}
+}
+
+// SendReviewInvitations рассылает участникам прошедшего события приглашение оценить его по подписанным ссылкам
+func (rs *ReviewService) SendReviewInvitations(event *models.Event) {
+	var participants []models.EventParticipant
+	if err := database.DB.Preload("User").Where("event_id = ?", event.ID).Find(&participants).Error; err != nil {
+		rs.logger.Error("Ошибка получения участников для приглашения к отзыву",
+			zap.String("eventID", event.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for i := range participants {
+		user := &participants[i].User
+
+		token, err := utils.GenerateReviewInvitationToken(event.ID, user.ID)
+		if err != nil {
+			rs.logger.Error("Ошибка генерации ссылки для отзыва", zap.String("userID", user.ID.String()), zap.Error(err))
+			continue
+		}
+		links := ReviewRatingLinks(token)
+
+		if err := rs.emailService.SendReviewInvitation(user.Email, user.FullName, event.Title, links); err != nil {
+			rs.logger.Error("Ошибка отправки приглашения к отзыву",
+				zap.String("email", user.Email),
+				zap.String("eventID", event.ID.String()),
+				zap.Error(err),
+			)
+		}
+		rs.telegramService.SendReviewInvitation(user, event, links)
+	}
+
+	rs.logger.Info("Отправлены приглашения к отзыву",
+		zap.String("eventID", event.ID.String()),
+		zap.Int("count", len(participants)),
+	)
+}
+
+// ReviewRatingLinks возвращает ссылки для оценок 1-5 по одному токену приглашения
+func ReviewRatingLinks(token string) []string {
+	links := make([]string, 5)
+	for i := range links {
+		links[i] = fmt.Sprintf("%s/api/reviews/quick?token=%s&rating=%d", config.AppConfig.PublicAPIURL, url.QueryEscape(token), i+1)
+	}
+	return links
+}
+
+// QuickReviewEvent проверяет ссылку из приглашения и возвращает событие, которое по ней оценивают
+func (rs *ReviewService) QuickReviewEvent(token string) (*models.Event, *utils.ReviewInvitationClaims, error) {
+	claims, err := utils.ValidateReviewInvitationToken(token)
+	if err != nil {
+		return nil, nil, ErrReviewInvitationInvalid
+	}
+
+	var event models.Event
+	if err := database.DB.Where("id = ?", claims.EventID).First(&event).Error; err != nil {
+		return nil, nil, ErrReviewInvitationInvalid
+	}
+
+	if event.Status != models.EventStatusPast {
+		return nil, nil, ErrReviewEventNotPast
+	}
+	return &event, claims, nil
+}
+
+// CreateQuickReview создает отзыв с оценкой по ссылке из приглашения
+func (rs *ReviewService) CreateQuickReview(token string, rating int) (*models.EventReview, error) {
+	event, claims, err := rs.QuickReviewEvent(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var participant models.EventParticipant
+	if err := database.DB.Where("event_id = ? AND user_id = ?", event.ID, claims.UserID).First(&participant).Error; err != nil {
+		return nil, ErrReviewNotParticipant
+	}
+
+	var existingReview models.EventReview
+	if err := database.DB.Where("event_id = ? AND user_id = ?", event.ID, claims.UserID).First(&existingReview).Error; err == nil {
+		return &existingReview, ErrReviewAlreadyExists
+	}
+
+	review := models.EventReview{
+		EventID: event.ID,
+		UserID:  claims.UserID,
+		Rating:  rating,
+	}
+	if err := database.DB.Create(&review).Error; err != nil {
+		return nil, err
+	}
+
+	database.DB.Preload("User").First(&review, review.ID)
+	go rs.NotifyReviewCreated(event, &review)
+
+	return &review, nil
+}
+
+// NotifyReviewCreated отправляет вебхук review.created подписчикам организатора
+func (rs *ReviewService) NotifyReviewCreated(event *models.Event, review *models.EventReview) {
+	rs.webhookService.Dispatch(models.WebhookEventReviewCreated, event, map[string]interface{}{
+		"review": map[string]interface{}{
+			"id":      review.ID.String(),
+			"rating":  review.Rating,
+			"comment": review.Comment,
+			"user": WebhookUserData{
+				ID:       review.User.ID.String(),
+				FullName: review.User.FullName,
+			},
+		},
+	})
+}
+
+// GetFeedbackSummary собирает статистику отзывов события: средняя оценка, распределение и доля ответивших участников
+func (rs *ReviewService) GetFeedbackSummary(eventID uuid.UUID) (*ReviewFeedbackSummary, error) {
+	var rows []struct {
+		Rating int
+		Count  int64
+	}
+	if err := database.DB.Model(&models.EventReview{}).
+		Select("rating, COUNT(*) AS count").
+		Where("event_id = ?", eventID).
+		Group("rating").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var participantsCount int64
+	if err := database.DB.Model(&models.EventParticipant{}).Where("event_id = ?", eventID).Count(&participantsCount).Error; err != nil {
+		return nil, err
+	}
+
+	summary := &ReviewFeedbackSummary{
+		Distribution:      map[int]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+		ParticipantsCount: participantsCount,
+	}
+	var ratingSum int64
+	for _, row := range rows {
+		summary.Distribution[row.Rating] = row.Count
+		summary.TotalReviews += row.Count
+		ratingSum += int64(row.Rating) * row.Count
+	}
+	if summary.TotalReviews > 0 {
+		summary.AverageRating = float64(ratingSum) / float64(summary.TotalReviews)
+	}
+	if participantsCount > 0 {
+		summary.ResponseRate = float64(summary.TotalReviews) / float64(participantsCount)
+	}
+
+	return summary, nil
+}
+
+type ReviewFeedbackSummary struct {
+	AverageRating     float64
+	TotalReviews      int64
+	Distribution      map[int]int64
+	ParticipantsCount int64
+	ResponseRate      float64
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"time"
+
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Веса составляющих похожести. Совместные участники учитываются логарифмически, чтобы крупные события
+// не вытесняли остальные сигналы, близость места плавно затухает с расстоянием
+const (
+	similarCategoryWeight      = 2.0
+	similarTagWeight           = 1.0
+	similarCoParticipantWeight = 3.0
+	similarDistanceWeight      = 2.0
+	similarDistanceScaleKm     = 10.0
+	similarSourceWindow        = 90 * 24 * time.Hour
+)
+
+// Пары событий считаются только среди связанных хотя бы общей категорией, тегом или участником,
+// расстояние лишь усиливает такую связь
+const similarEventsRefreshSQL = `
+WITH sources AS (
+	SELECT id FROM events
+	WHERE deleted_at IS NULL AND status IN @sourceStatuses AND end_date > @since
+),
+targets AS (
+	SELECT id FROM events
+	WHERE deleted_at IS NULL AND status = @active AND visibility = @public AND start_date > @now
+),
+pairs AS (
+	SELECT a.event_id, b.event_id AS similar_event_id, COUNT(*) AS shared_categories, 0 AS shared_tags, 0 AS co_participants
+	FROM event_categories a
+	JOIN event_categories b ON b.category_id = a.category_id AND b.event_id <> a.event_id
+	WHERE a.event_id IN (SELECT id FROM sources) AND b.event_id IN (SELECT id FROM targets)
+	GROUP BY a.event_id, b.event_id
+	UNION ALL
+	SELECT s.id, t.id, 0, cardinality(ARRAY(SELECT unnest(s.tags) INTERSECT SELECT unnest(t.tags))), 0
+	FROM events s
+	JOIN events t ON t.id <> s.id AND t.tags && s.tags
+	WHERE s.id IN (SELECT id FROM sources) AND t.id IN (SELECT id FROM targets)
+	UNION ALL
+	SELECT a.event_id, b.event_id, 0, 0, COUNT(DISTINCT a.user_id)
+	FROM event_participants a
+	JOIN event_participants b ON b.user_id = a.user_id AND b.event_id <> a.event_id
+	WHERE a.event_id IN (SELECT id FROM sources) AND b.event_id IN (SELECT id FROM targets)
+	GROUP BY a.event_id, b.event_id
+),
+scored AS (
+	SELECT p.event_id, p.similar_event_id,
+		SUM(p.shared_categories) AS shared_categories,
+		SUM(p.shared_tags) AS shared_tags,
+		SUM(p.co_participants) AS co_participants,
+		CASE WHEN s.latitude IS NOT NULL AND s.longitude IS NOT NULL AND t.latitude IS NOT NULL AND t.longitude IS NOT NULL
+			THEN earth_distance(ll_to_earth(s.latitude::float8, s.longitude::float8), ll_to_earth(t.latitude::float8, t.longitude::float8)) / 1000
+		END AS distance_km
+	FROM pairs p
+	JOIN events s ON s.id = p.event_id
+	JOIN events t ON t.id = p.similar_event_id
+	GROUP BY p.event_id, p.similar_event_id, s.latitude, s.longitude, t.latitude, t.longitude
+),
+ranked AS (
+	SELECT scored.*,
+		@categoryWeight * shared_categories
+			+ @tagWeight * shared_tags
+			+ @coParticipantWeight * ln(1 + co_participants)
+			+ CASE WHEN distance_km IS NULL THEN 0 ELSE @distanceWeight * exp(-distance_km / @distanceScaleKm) END AS score
+	FROM scored
+)
+INSERT INTO similar_events (event_id, similar_event_id, score, shared_categories, shared_tags, co_participants, distance_km, computed_at)
+SELECT event_id, similar_event_id, score, shared_categories, shared_tags, co_participants, distance_km, @now
+FROM (
+	SELECT ranked.*, ROW_NUMBER() OVER (PARTITION BY event_id ORDER BY score DESC, similar_event_id) AS rn
+	FROM ranked
+) best
+WHERE rn <= @perEvent`
+
+type SimilarEventService struct {
+	logger *zap.Logger
+}
+
+func NewSimilarEventService() *SimilarEventService {
+	return &SimilarEventService{
+		logger: utils.GetLogger(),
+	}
+}
+
+// Refresh пересчитывает таблицу similar_events для активных и недавно прошедших событий.
+// Старые данные заменяются в одной транзакции, поэтому читатели не видят пустую таблицу во время пересчета
+func (ss *SimilarEventService) Refresh() {
+	now := time.Now()
+	var inserted int64
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM similar_events").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(similarEventsRefreshSQL, map[string]interface{}{
+			"sourceStatuses":      []models.EventStatus{models.EventStatusActive, models.EventStatusPast},
+			"active":              models.EventStatusActive,
+			"public":              models.EventVisibilityPublic,
+			"since":               now.Add(-similarSourceWindow),
+			"now":                 now,
+			"categoryWeight":      similarCategoryWeight,
+			"tagWeight":           similarTagWeight,
+			"coParticipantWeight": similarCoParticipantWeight,
+			"distanceWeight":      similarDistanceWeight,
+			"distanceScaleKm":     similarDistanceScaleKm,
+			"perEvent":            utils.SimilarEventsPerEvent,
+		})
+		inserted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		ss.logger.Error("Ошибка пересчета похожих событий", zap.Error(err))
+		return
+	}
+
+	ss.logger.Info("Пересчитаны похожие события",
+		zap.Int64("pairs", inserted),
+		zap.Duration("duration", time.Since(now)),
+	)
+}
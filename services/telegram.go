@@ -0,0 +1,434 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bekend/config"
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	telegramAPIBaseURL = "https://api.telegram.org"
+
+	telegramCallbackMatchAccept = "match_accept:"
+	telegramCallbackMatchReject = "match_reject:"
+)
+
+var ErrTelegramNotConfigured = errors.New("Telegram-бот не настроен")
+
+type TelegramInlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data,omitempty"`
+	URL          string `json:"url,omitempty"`
+}
+
+type TelegramInlineKeyboard struct {
+	InlineKeyboard [][]TelegramInlineButton `json:"inline_keyboard"`
+}
+
+// TelegramBotAPI - методы Bot API, которые использует сервис уведомлений
+type TelegramBotAPI interface {
+	SendMessage(chatID int64, text string, keyboard *TelegramInlineKeyboard) error
+	AnswerCallbackQuery(callbackQueryID, text string) error
+	EditMessageText(chatID, messageID int64, text string) error
+}
+
+type telegramHTTPClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewTelegramHTTPClient(baseURL, token string) TelegramBotAPI {
+	return &telegramHTTPClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (tc *telegramHTTPClient) SendMessage(chatID int64, text string, keyboard *TelegramInlineKeyboard) error {
+	payload := map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	}
+	if keyboard != nil {
+		payload["reply_markup"] = keyboard
+	}
+	return tc.call("sendMessage", payload)
+}
+
+func (tc *telegramHTTPClient) AnswerCallbackQuery(callbackQueryID, text string) error {
+	return tc.call("answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": callbackQueryID,
+		"text":              text,
+	})
+}
+
+func (tc *telegramHTTPClient) EditMessageText(chatID, messageID int64, text string) error {
+	return tc.call("editMessageText", map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"text":       text,
+	})
+}
+
+func (tc *telegramHTTPClient) call(method string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := tc.client.Post(fmt.Sprintf("%s/bot%s/%s", tc.baseURL, tc.token, method), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("ошибка парсинга ответа Telegram: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("ошибка Telegram API (%s): %s", method, result.Description)
+	}
+	return nil
+}
+
+var fakeTelegramBot = NewFakeTelegramBotAPI()
+
+type TelegramService struct {
+	api             TelegramBotAPI
+	matchingService *MatchingService
+	logger          *zap.Logger
+}
+
+func NewTelegramService() *TelegramService {
+	var api TelegramBotAPI
+	if config.AppConfig.FakeTelegramBot {
+		api = fakeTelegramBot
+	} else if config.AppConfig.TelegramBotToken != "" {
+		api = NewTelegramHTTPClient(telegramAPIBaseURL, config.AppConfig.TelegramBotToken)
+	}
+	return NewTelegramServiceWithAPI(api)
+}
+
+// NewTelegramServiceWithAPI создает сервис с заданным клиентом Bot API (например, FakeTelegramBotAPI)
+func NewTelegramServiceWithAPI(api TelegramBotAPI) *TelegramService {
+	return &TelegramService{
+		api:             api,
+		matchingService: NewMatchingService(),
+		logger:          utils.GetLogger(),
+	}
+}
+
+func (ts *TelegramService) IsEnabled() bool {
+	return ts.api != nil
+}
+
+// CreateLinkToken создает одноразовый токен и deep-link вида https://t.me/<bot>?start=<token>
+func (ts *TelegramService) CreateLinkToken(userID uuid.UUID) (*models.TelegramLinkToken, string, error) {
+	if !ts.IsEnabled() || config.AppConfig.TelegramBotUsername == "" {
+		return nil, "", ErrTelegramNotConfigured
+	}
+
+	linkToken := models.TelegramLinkToken{
+		UserID:    userID,
+		Token:     utils.GenerateRandomString(32),
+		ExpiresAt: time.Now().Add(utils.TelegramLinkTokenExpiry),
+	}
+	if err := database.DB.Create(&linkToken).Error; err != nil {
+		return nil, "", err
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s", config.AppConfig.TelegramBotUsername, linkToken.Token)
+	return &linkToken, link, nil
+}
+
+// SendToUser отправляет сообщение пользователю, если у него привязан чат. Без привязки ничего не делает.
+func (ts *TelegramService) SendToUser(user *models.User, text string, keyboard *TelegramInlineKeyboard) error {
+	if !ts.IsEnabled() || user == nil || user.TelegramChatID == nil {
+		return nil
+	}
+
+	if err := ts.api.SendMessage(*user.TelegramChatID, text, keyboard); err != nil {
+		ts.logger.Error("Ошибка отправки сообщения в Telegram",
+			zap.String("userID", user.ID.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (ts *TelegramService) SendEventReminder(user *models.User, event *models.Event, message string) error {
+	text := fmt.Sprintf("🔔 %s\n\n%s\nНачало: %s", event.Title, message, utils.FormatEventTime(event.StartDate, event.Timezone))
+	if event.Address != "" {
+		text += "\nМесто: " + event.Address
+	}
+	return ts.SendToUser(user, text, nil)
+}
+
+func (ts *TelegramService) SendEventCancelled(user *models.User, event *models.Event) error {
+	text := fmt.Sprintf("❌ Событие \"%s\" отменено\n\nПричина: %s", event.Title, event.CancellationReason)
+	if event.RescheduledTo != nil {
+		text += "\nНовая дата проведения: " + utils.FormatEventTime(*event.RescheduledTo, event.Timezone)
+	}
+	return ts.SendToUser(user, text, nil)
+}
+
+// SendApplicationDecision сообщает заявителю решение организатора по заявке на участие
+func (ts *TelegramService) SendApplicationDecision(user *models.User, event *models.Event, application *models.EventApplication) error {
+	var text string
+	if application.Status == models.ApplicationStatusApproved {
+		text = fmt.Sprintf("✅ Заявка на участие в событии \"%s\" одобрена\nНачало: %s", event.Title, utils.FormatEventTime(event.StartDate, event.Timezone))
+	} else {
+		text = fmt.Sprintf("❌ Заявка на участие в событии \"%s\" отклонена", event.Title)
+		if application.Reason != "" {
+			text += "\n\nПричина: " + application.Reason
+		}
+	}
+	return ts.SendToUser(user, text, nil)
+}
+
+// SendReviewInvitation предлагает оценить прошедшее событие кнопками-ссылками 1-5
+func (ts *TelegramService) SendReviewInvitation(user *models.User, event *models.Event, ratingLinks []string) error {
+	text := fmt.Sprintf("Событие \"%s\" завершилось. Как вам? Оцените одним нажатием:", event.Title)
+	row := make([]TelegramInlineButton, 0, len(ratingLinks))
+	for i, link := range ratingLinks {
+		row = append(row, TelegramInlineButton{Text: fmt.Sprintf("%d ⭐", i+1), URL: link})
+	}
+	return ts.SendToUser(user, text, &TelegramInlineKeyboard{InlineKeyboard: [][]TelegramInlineButton{row}})
+}
+
+func (ts *TelegramService) SendCommunityEventNotification(user *models.User, communityName string, event *models.Event) error {
+	text := fmt.Sprintf("Новое событие в сообществе \"%s\":\n\n%s\nНачало: %s",
+		communityName, event.Title, utils.FormatEventTime(event.StartDate, event.Timezone))
+	if event.ShortDescription != "" {
+		text += "\n\n" + event.ShortDescription
+	}
+	return ts.SendToUser(user, text, nil)
+}
+
+// SendMatchRequest уведомляет получателя о запросе на совместный поход с кнопками "Принять"/"Отклонить"
+func (ts *TelegramService) SendMatchRequest(requestID uuid.UUID) error {
+	if !ts.IsEnabled() {
+		return nil
+	}
+
+	var request models.MatchRequest
+	if err := database.DB.Preload("FromUser").Preload("ToUser").Preload("Event").
+		Where("id = ?", requestID).First(&request).Error; err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("%s хочет пойти с вами на событие \"%s\" (%s).",
+		request.FromUser.FullName, request.Event.Title, utils.FormatEventTime(request.Event.StartDate, request.Event.Timezone))
+	if request.Message != "" {
+		text += "\n\nСообщение: " + request.Message
+	}
+
+	keyboard := &TelegramInlineKeyboard{
+		InlineKeyboard: [][]TelegramInlineButton{{
+			{Text: "✅ Принять", CallbackData: telegramCallbackMatchAccept + request.ID.String()},
+			{Text: "❌ Отклонить", CallbackData: telegramCallbackMatchReject + request.ID.String()},
+		}},
+	}
+
+	return ts.SendToUser(&request.ToUser, text, keyboard)
+}
+
+// SendMatchRequestResult уведомляет отправителя запроса о решении получателя
+func (ts *TelegramService) SendMatchRequestResult(request *models.MatchRequest) error {
+	if !ts.IsEnabled() {
+		return nil
+	}
+
+	var fromUser, toUser models.User
+	var event models.Event
+	if err := database.DB.Where("id = ?", request.FromUserID).First(&fromUser).Error; err != nil {
+		return err
+	}
+	database.DB.Where("id = ?", request.ToUserID).First(&toUser)
+	database.DB.Where("id = ?", request.EventID).First(&event)
+
+	var text string
+	if request.Status == models.MatchRequestStatusAccepted {
+		text = fmt.Sprintf("%s принял(а) ваш запрос на событие \"%s\". Приятной компании!", toUser.FullName, event.Title)
+	} else {
+		text = fmt.Sprintf("%s отклонил(а) ваш запрос на событие \"%s\".", toUser.FullName, event.Title)
+	}
+	return ts.SendToUser(&fromUser, text, nil)
+}
+
+// HandleUpdate обрабатывает обновление, полученное через вебхук
+func (ts *TelegramService) HandleUpdate(update *dto.TelegramUpdate) {
+	if !ts.IsEnabled() {
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		ts.handleCallbackQuery(update.CallbackQuery)
+		return
+	}
+
+	if update.Message == nil {
+		return
+	}
+
+	text := strings.TrimSpace(update.Message.Text)
+	chatID := update.Message.Chat.ID
+
+	switch {
+	case strings.HasPrefix(text, "/start"):
+		parts := strings.Fields(text)
+		if len(parts) < 2 {
+			ts.reply(chatID, "Здравствуйте! Чтобы получать уведомления о событиях, откройте профиль на сайте и нажмите \"Подключить Telegram\".")
+			return
+		}
+		username := ""
+		if update.Message.From != nil {
+			username = update.Message.From.Username
+		}
+		ts.bindChat(parts[1], chatID, username)
+	case strings.HasPrefix(text, "/stop"):
+		ts.unbindChat(chatID)
+	default:
+		ts.reply(chatID, "Я отправляю напоминания о событиях, запросы на совместный поход и новости сообществ. Команды: /stop - отключить уведомления.")
+	}
+}
+
+func (ts *TelegramService) bindChat(token string, chatID int64, username string) {
+	var linkToken models.TelegramLinkToken
+	if err := database.DB.Where("token = ? AND used = ? AND expires_at > ?", token, false, time.Now()).First(&linkToken).Error; err != nil {
+		ts.reply(chatID, "Ссылка недействительна или устарела. Получите новую ссылку в профиле на сайте.")
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Where("id = ? AND status = ?", linkToken.UserID, models.UserStatusActive).First(&user).Error; err != nil {
+		ts.reply(chatID, "Пользователь не найден.")
+		return
+	}
+
+	// Один чат может быть привязан только к одному аккаунту
+	if err := database.DB.Model(&models.User{}).Where("telegram_chat_id = ? AND id != ?", chatID, user.ID).
+		Update("telegram_chat_id", nil).Error; err != nil {
+		ts.logger.Error("Ошибка отвязки чата Telegram от другого пользователя", zap.Int64("chatID", chatID), zap.Error(err))
+	}
+
+	user.TelegramChatID = &chatID
+	if user.Telegram == "" && utils.ValidateTelegramUsername(username) {
+		user.Telegram = username
+	}
+	if err := database.DB.Save(&user).Error; err != nil {
+		ts.logger.Error("Ошибка привязки чата Telegram", zap.String("userID", user.ID.String()), zap.Error(err))
+		ts.reply(chatID, "Не удалось привязать аккаунт. Попробуйте позже.")
+		return
+	}
+
+	linkToken.Used = true
+	database.DB.Save(&linkToken)
+
+	ts.logger.Info("Чат Telegram привязан", zap.String("userID", user.ID.String()), zap.Int64("chatID", chatID))
+	ts.reply(chatID, fmt.Sprintf("Готово, %s! Теперь уведомления будут приходить сюда.", user.FullName))
+}
+
+func (ts *TelegramService) unbindChat(chatID int64) {
+	if err := database.DB.Model(&models.User{}).Where("telegram_chat_id = ?", chatID).
+		Update("telegram_chat_id", nil).Error; err != nil {
+		ts.logger.Error("Ошибка отвязки чата Telegram", zap.Int64("chatID", chatID), zap.Error(err))
+		ts.reply(chatID, "Не удалось отключить уведомления. Попробуйте позже.")
+		return
+	}
+	ts.reply(chatID, "Уведомления отключены. Подключить их снова можно в профиле на сайте.")
+}
+
+func (ts *TelegramService) handleCallbackQuery(query *dto.TelegramCallbackQuery) {
+	chatID := query.From.ID
+	if query.Message != nil {
+		chatID = query.Message.Chat.ID
+	}
+
+	var accept bool
+	var requestIDStr string
+	switch {
+	case strings.HasPrefix(query.Data, telegramCallbackMatchAccept):
+		accept = true
+		requestIDStr = strings.TrimPrefix(query.Data, telegramCallbackMatchAccept)
+	case strings.HasPrefix(query.Data, telegramCallbackMatchReject):
+		requestIDStr = strings.TrimPrefix(query.Data, telegramCallbackMatchReject)
+	default:
+		ts.answer(query.ID, "Неизвестное действие")
+		return
+	}
+
+	requestID, err := uuid.Parse(requestIDStr)
+	if err != nil {
+		ts.answer(query.ID, "Неверный запрос")
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Where("telegram_chat_id = ?", chatID).First(&user).Error; err != nil {
+		ts.answer(query.ID, "Аккаунт не привязан")
+		return
+	}
+
+	var request *models.MatchRequest
+	if accept {
+		request, err = ts.matchingService.AcceptMatchRequest(requestID, user.ID)
+	} else {
+		request, err = ts.matchingService.RejectMatchRequest(requestID, user.ID)
+	}
+	if err != nil {
+		if errors.Is(err, ErrMatchRequestNotFound) || errors.Is(err, ErrMatchRequestProcessed) {
+			ts.answer(query.ID, err.Error())
+		} else {
+			ts.logger.Error("Ошибка обработки запроса на матч из Telegram", zap.String("requestID", requestIDStr), zap.Error(err))
+			ts.answer(query.ID, "Ошибка при обработке запроса")
+		}
+		return
+	}
+
+	result := "Запрос отклонен"
+	if accept {
+		result = "Запрос принят"
+	}
+	ts.answer(query.ID, result)
+
+	if query.Message != nil {
+		if err := ts.api.EditMessageText(chatID, query.Message.MessageID, query.Message.Text+"\n\n"+result); err != nil {
+			ts.logger.Warn("Ошибка обновления сообщения Telegram", zap.Error(err))
+		}
+	}
+
+	go ts.SendMatchRequestResult(request)
+}
+
+func (ts *TelegramService) reply(chatID int64, text string) {
+	if err := ts.api.SendMessage(chatID, text, nil); err != nil {
+		ts.logger.Error("Ошибка ответа в Telegram", zap.Int64("chatID", chatID), zap.Error(err))
+	}
+}
+
+func (ts *TelegramService) answer(callbackQueryID, text string) {
+	if err := ts.api.AnswerCallbackQuery(callbackQueryID, text); err != nil {
+		ts.logger.Error("Ошибка ответа на callback Telegram", zap.Error(err))
+	}
+}
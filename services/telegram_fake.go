@@ -0,0 +1,84 @@
+package services
+
+import (
+	"sync"
+
+	"bekend/utils"
+
+	"go.uber.org/zap"
+)
+
+// FakeTelegramMessage - сообщение, "отправленное" через FakeTelegramBotAPI
+type FakeTelegramMessage struct {
+	ChatID    int64
+	MessageID int64
+	Text      string
+	Keyboard  *TelegramInlineKeyboard
+}
+
+// FakeTelegramBotAPI - локальная реализация TelegramBotAPI без сетевых запросов.
+// Используется в режиме FAKE_TELEGRAM_BOT и в тестах: все вызовы сохраняются в памяти.
+type FakeTelegramBotAPI struct {
+	mu              sync.Mutex
+	Messages        []FakeTelegramMessage
+	Edits           []FakeTelegramMessage
+	AnsweredQueries map[string]string
+}
+
+func NewFakeTelegramBotAPI() *FakeTelegramBotAPI {
+	return &FakeTelegramBotAPI{
+		AnsweredQueries: make(map[string]string),
+	}
+}
+
+func (f *FakeTelegramBotAPI) SendMessage(chatID int64, text string, keyboard *TelegramInlineKeyboard) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Messages = append(f.Messages, FakeTelegramMessage{
+		ChatID:    chatID,
+		MessageID: int64(len(f.Messages) + 1),
+		Text:      text,
+		Keyboard:  keyboard,
+	})
+
+	utils.GetLogger().Info("Фейковое сообщение Telegram",
+		zap.Int64("chatID", chatID),
+		zap.String("text", text),
+	)
+	return nil
+}
+
+func (f *FakeTelegramBotAPI) AnswerCallbackQuery(callbackQueryID, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.AnsweredQueries[callbackQueryID] = text
+	return nil
+}
+
+func (f *FakeTelegramBotAPI) EditMessageText(chatID, messageID int64, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Edits = append(f.Edits, FakeTelegramMessage{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+	})
+	return nil
+}
+
+// SentTo возвращает сообщения, отправленные в указанный чат
+func (f *FakeTelegramBotAPI) SentTo(chatID int64) []FakeTelegramMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []FakeTelegramMessage
+	for _, m := range f.Messages {
+		if m.ChatID == chatID {
+			result = append(result, m)
+		}
+	}
+	return result
+}
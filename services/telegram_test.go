@@ -0,0 +1,129 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"bekend/database"
+	"bekend/dto"
+	"bekend/models"
+)
+
+func TestHandleUpdateIgnoredWithoutBot(t *testing.T) {
+	ts := NewTelegramServiceWithAPI(nil)
+	ts.HandleUpdate(&dto.TelegramUpdate{
+		CallbackQuery: &dto.TelegramCallbackQuery{ID: "q1", Data: "match_accept:x"},
+	})
+}
+
+func TestHandleUpdateRepliesWithHelp(t *testing.T) {
+	bot := NewFakeTelegramBotAPI()
+	ts := NewTelegramServiceWithAPI(bot)
+
+	ts.HandleUpdate(&dto.TelegramUpdate{Message: &dto.TelegramMessage{Chat: dto.TelegramChat{ID: 42}, Text: "привет"}})
+	ts.HandleUpdate(&dto.TelegramUpdate{Message: &dto.TelegramMessage{Chat: dto.TelegramChat{ID: 42}, Text: "/start"}})
+
+	messages := bot.SentTo(42)
+	if len(messages) != 2 {
+		t.Fatalf("ожидалось 2 ответа, получено %d", len(messages))
+	}
+	if !strings.Contains(messages[0].Text, "/stop") {
+		t.Errorf("на произвольный текст ожидалась подсказка по командам, получено %q", messages[0].Text)
+	}
+	if !strings.Contains(messages[1].Text, "Подключить Telegram") {
+		t.Errorf("на /start без токена ожидалось приветствие, получено %q", messages[1].Text)
+	}
+}
+
+func TestHandleCallbackQueryRejectsMalformedData(t *testing.T) {
+	tests := []struct {
+		data   string
+		answer string
+	}{
+		{"unknown:123", "Неизвестное действие"},
+		{telegramCallbackMatchAccept + "not-a-uuid", "Неверный запрос"},
+		{telegramCallbackMatchReject + "", "Неверный запрос"},
+	}
+
+	for _, tt := range tests {
+		bot := NewFakeTelegramBotAPI()
+		ts := NewTelegramServiceWithAPI(bot)
+		ts.HandleUpdate(&dto.TelegramUpdate{CallbackQuery: &dto.TelegramCallbackQuery{ID: "q1", Data: tt.data}})
+
+		if got := bot.AnsweredQueries["q1"]; got != tt.answer {
+			t.Errorf("data %q: ответ %q, ожидался %q", tt.data, got, tt.answer)
+		}
+		if len(bot.Edits) != 0 {
+			t.Errorf("data %q: сообщение не должно меняться", tt.data)
+		}
+	}
+}
+
+func TestHandleCallbackQueryRoutesMatchRequest(t *testing.T) {
+	requireTestDB(t)
+
+	tests := []struct {
+		prefix string
+		status models.MatchRequestStatus
+		answer string
+	}{
+		{telegramCallbackMatchAccept, models.MatchRequestStatusAccepted, "Запрос принят"},
+		{telegramCallbackMatchReject, models.MatchRequestStatusRejected, "Запрос отклонен"},
+	}
+
+	for _, tt := range tests {
+		chatID := randomChatID()
+		from := createTestUser(t, 0)
+		to := createTestUser(t, chatID)
+		event := createTestEvent(t, from.ID, 0)
+		request := models.MatchRequest{FromUserID: from.ID, ToUserID: to.ID, EventID: event.ID, Status: models.MatchRequestStatusPending}
+		if err := database.DB.Create(&request).Error; err != nil {
+			t.Fatalf("Ошибка создания запроса: %v", err)
+		}
+
+		bot := NewFakeTelegramBotAPI()
+		ts := NewTelegramServiceWithAPI(bot)
+		query := &dto.TelegramCallbackQuery{
+			ID:      "q1",
+			From:    dto.TelegramUser{ID: chatID},
+			Message: &dto.TelegramMessage{MessageID: 7, Chat: dto.TelegramChat{ID: chatID}, Text: "Запрос на совместный поход"},
+			Data:    tt.prefix + request.ID.String(),
+		}
+		ts.HandleUpdate(&dto.TelegramUpdate{CallbackQuery: query})
+
+		if got := bot.AnsweredQueries["q1"]; got != tt.answer {
+			t.Errorf("%s: ответ %q, ожидался %q", tt.prefix, got, tt.answer)
+		}
+		var saved models.MatchRequest
+		database.DB.Where("id = ?", request.ID).First(&saved)
+		if saved.Status != tt.status {
+			t.Errorf("%s: статус запроса %q, ожидался %q", tt.prefix, saved.Status, tt.status)
+		}
+		if len(bot.Edits) != 1 || bot.Edits[0].MessageID != 7 || !strings.HasSuffix(bot.Edits[0].Text, tt.answer) {
+			t.Errorf("%s: исходное сообщение должно получить итог, правки: %+v", tt.prefix, bot.Edits)
+		}
+
+		// Повторное нажатие кнопки не меняет уже обработанный запрос
+		query.ID = "q2"
+		ts.HandleUpdate(&dto.TelegramUpdate{CallbackQuery: query})
+		if got := bot.AnsweredQueries["q2"]; got != ErrMatchRequestProcessed.Error() {
+			t.Errorf("%s: повторный ответ %q, ожидался %q", tt.prefix, got, ErrMatchRequestProcessed.Error())
+		}
+	}
+}
+
+func TestHandleCallbackQueryFromUnlinkedChat(t *testing.T) {
+	requireTestDB(t)
+
+	bot := NewFakeTelegramBotAPI()
+	ts := NewTelegramServiceWithAPI(bot)
+	ts.HandleUpdate(&dto.TelegramUpdate{CallbackQuery: &dto.TelegramCallbackQuery{
+		ID:   "q1",
+		From: dto.TelegramUser{ID: randomChatID()},
+		Data: telegramCallbackMatchAccept + "00000000-0000-0000-0000-000000000001",
+	}})
+
+	if got := bot.AnsweredQueries["q1"]; got != "Аккаунт не привязан" {
+		t.Errorf("ответ %q, ожидался %q", got, "Аккаунт не привязан")
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"time"
+
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Параметры рейтинга "в тренде". Каждая запись и каждый просмотр за последние trendingWindow дают вклад,
+// который уменьшается вдвое каждые trendingHalfLife. Средняя оценка прошлых событий организатора
+// (сглаженная к 3 звездам при малом числе отзывов) умножает результат на коэффициент от 0.5 до 1.5
+const (
+	trendingWindow       = 14 * 24 * time.Hour
+	trendingHalfLife     = 48 * time.Hour
+	trendingJoinWeight   = 5.0
+	trendingViewWeight   = 0.5
+	trendingRatingWeight = 0.5
+	trendingRatingPrior  = 5 // Сколько "виртуальных" отзывов на 3 звезды добавляется к отзывам организатора
+)
+
+const trendingRefreshSQL = `
+WITH candidates AS (
+	SELECT id, organizer_id FROM events
+	WHERE deleted_at IS NULL AND status = @active AND end_date > @now
+),
+joins AS (
+	SELECT ep.event_id, COUNT(*) AS recent_joins,
+		SUM(exp(-ln(2) * extract(epoch FROM (@now - ep.created_at)) / @halfLifeSeconds)) AS join_score
+	FROM event_participants ep
+	JOIN candidates c ON c.id = ep.event_id
+	WHERE ep.created_at > @since
+	GROUP BY ep.event_id
+),
+views AS (
+	SELECT ev.event_id, COUNT(*) AS recent_views,
+		SUM(exp(-ln(2) * extract(epoch FROM (@now - ev.created_at)) / @halfLifeSeconds)) AS view_score
+	FROM event_views ev
+	JOIN candidates c ON c.id = ev.event_id
+	WHERE ev.created_at > @since
+	GROUP BY ev.event_id
+),
+ratings AS (
+	SELECT e.organizer_id, AVG(r.rating) AS avg_rating, COUNT(*) AS reviews
+	FROM event_reviews r
+	JOIN events e ON e.id = r.event_id
+	WHERE e.organizer_id IN (SELECT organizer_id FROM candidates)
+	GROUP BY e.organizer_id
+)
+INSERT INTO event_trending_scores (event_id, score, recent_joins, recent_views, organizer_rating, computed_at)
+SELECT c.id,
+	(@joinWeight * COALESCE(j.join_score, 0) + @viewWeight * COALESCE(v.view_score, 0))
+		* (1 + @ratingWeight * ((COALESCE(r.avg_rating * r.reviews, 0) + 3 * @ratingPrior) / (COALESCE(r.reviews, 0) + @ratingPrior) - 3) / 2),
+	COALESCE(j.recent_joins, 0),
+	COALESCE(v.recent_views, 0),
+	r.avg_rating,
+	@now
+FROM candidates c
+LEFT JOIN joins j ON j.event_id = c.id
+LEFT JOIN views v ON v.event_id = c.id
+LEFT JOIN ratings r ON r.organizer_id = c.organizer_id
+WHERE j.event_id IS NOT NULL OR v.event_id IS NOT NULL`
+
+type TrendingService struct {
+	logger *zap.Logger
+}
+
+func NewTrendingService() *TrendingService {
+	return &TrendingService{
+		logger: utils.GetLogger(),
+	}
+}
+
+// RecordView фиксирует просмотр страницы события; повторные просмотры того же зрителя за день игнорируются.
+// Сутки считаются в часовом поясе платформы, а не сервера
+func (ts *TrendingService) RecordView(eventID uuid.UUID, viewerKey string) {
+	now := time.Now().In(utils.DefaultLocation())
+	view := models.EventView{
+		EventID:   eventID,
+		ViewerKey: viewerKey,
+		ViewedOn:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		CreatedAt: now,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&view).Error; err != nil {
+		ts.logger.Warn("Ошибка записи просмотра события", zap.String("eventID", eventID.String()), zap.Error(err))
+	}
+}
+
+// Refresh пересчитывает таблицу event_trending_scores в одной транзакции
+func (ts *TrendingService) Refresh() {
+	now := time.Now()
+	var inserted int64
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM event_trending_scores").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(trendingRefreshSQL, map[string]interface{}{
+			"active":          models.EventStatusActive,
+			"now":             now,
+			"since":           now.Add(-trendingWindow),
+			"halfLifeSeconds": trendingHalfLife.Seconds(),
+			"joinWeight":      trendingJoinWeight,
+			"viewWeight":      trendingViewWeight,
+			"ratingWeight":    trendingRatingWeight,
+			"ratingPrior":     trendingRatingPrior,
+		})
+		inserted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		ts.logger.Error("Ошибка пересчета трендовых событий", zap.Error(err))
+		return
+	}
+
+	ts.logger.Info("Пересчитан рейтинг трендовых событий",
+		zap.Int64("events", inserted),
+		zap.Duration("duration", time.Since(now)),
+	)
+}
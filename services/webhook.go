@@ -0,0 +1,302 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"bekend/database"
+	"bekend/models"
+	"bekend/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// WebhookPayload - тело исходящего вебхука
+type WebhookPayload struct {
+	ID        string                  `json:"id"`
+	Type      models.WebhookEventType `json:"type"`
+	CreatedAt time.Time               `json:"createdAt"`
+	Data      interface{}             `json:"data"`
+}
+
+type WebhookEventData struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	Timezone  string    `json:"timezone"`
+}
+
+type WebhookUserData struct {
+	ID       string `json:"id"`
+	FullName string `json:"fullName"`
+}
+
+var errWebhookAddressForbidden = errors.New("адрес получателя во внутренней сети")
+
+type WebhookService struct {
+	client *http.Client
+	logger *zap.Logger
+}
+
+func NewWebhookService() *WebhookService {
+	return &WebhookService{
+		client: newWebhookHTTPClient(),
+		logger: utils.GetLogger(),
+	}
+}
+
+// newWebhookHTTPClient создает клиент, который не подключается к внутренним адресам и не следует редиректам.
+// Адрес проверяется в момент подключения, после разрешения имени, поэтому не помогут ни DNS-записи
+// во внутреннюю сеть, ни их подмена после валидации URL
+func newWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: utils.WebhookRequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !utils.IsPublicIP(ip) {
+				return errWebhookAddressForbidden
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: utils.WebhookRequestTimeout,
+		// Без прокси из окружения: иначе подключение шло бы к прокси, а не к проверяемому адресу
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: utils.WebhookRequestTimeout,
+		},
+		// Ответ 3xx считается ошибкой доставки, как любой другой не 2xx
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func NewWebhookEventData(event *models.Event) WebhookEventData {
+	return WebhookEventData{
+		ID:        event.ID.String(),
+		Title:     event.Title,
+		Status:    string(event.Status),
+		StartDate: event.StartDate.In(utils.EventLocation(event.Timezone)),
+		EndDate:   event.EndDate.In(utils.EventLocation(event.Timezone)),
+		Timezone:  utils.EventLocation(event.Timezone).String(),
+	}
+}
+
+// Dispatch ставит в очередь вебхук для подписок организатора события и администраторов и сразу пытается его доставить
+func (ws *WebhookService) Dispatch(eventType models.WebhookEventType, event *models.Event, data map[string]interface{}) {
+	var subscriptions []models.WebhookSubscription
+	if err := database.DB.Joins("JOIN users ON users.id = webhook_subscriptions.owner_id").
+		Where("webhook_subscriptions.is_active = ? AND ? = ANY(webhook_subscriptions.event_types)", true, string(eventType)).
+		Where("webhook_subscriptions.owner_id = ? OR users.role = ?", event.OrganizerID, models.RoleAdmin).
+		Find(&subscriptions).Error; err != nil {
+		ws.logger.Error("Ошибка получения подписок на вебхуки",
+			zap.String("eventType", string(eventType)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["event"] = NewWebhookEventData(event)
+
+	for i := range subscriptions {
+		delivery, err := ws.enqueue(&subscriptions[i], eventType, data)
+		if err != nil {
+			ws.logger.Error("Ошибка создания доставки вебхука",
+				zap.String("subscriptionID", subscriptions[i].ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		ws.Deliver(delivery, &subscriptions[i])
+	}
+}
+
+// Ping отправляет тестовый вебхук синхронно и возвращает результат доставки
+func (ws *WebhookService) Ping(subscription *models.WebhookSubscription) (*models.WebhookDelivery, error) {
+	delivery, err := ws.enqueue(subscription, models.WebhookEventPing, map[string]interface{}{
+		"message": "Тестовое уведомление",
+	})
+	if err != nil {
+		return nil, err
+	}
+	ws.Deliver(delivery, subscription)
+	return delivery, nil
+}
+
+// Replay создает новую доставку с тем же содержимым, что и у исходной
+func (ws *WebhookService) Replay(original *models.WebhookDelivery, subscription *models.WebhookSubscription) (*models.WebhookDelivery, error) {
+	delivery := models.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryStatusPending,
+		NextAttemptAt:  webhookAttemptLease(),
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	ws.Deliver(&delivery, subscription)
+	return &delivery, nil
+}
+
+// RetryPending повторяет доставки, у которых подошло время следующей попытки
+func (ws *WebhookService) RetryPending() {
+	var deliveries []models.WebhookDelivery
+	if err := database.DB.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, time.Now()).
+		Order("next_attempt_at ASC").Limit(100).Find(&deliveries).Error; err != nil {
+		ws.logger.Error("Ошибка получения доставок вебхуков для повтора", zap.Error(err))
+		return
+	}
+
+	for i := range deliveries {
+		// Доставка захватывается переносом next_attempt_at: если предыдущий запуск еще не закончил попытку
+		// или ее уже забрал другой процесс, строка не обновится и повторной отправки не будет
+		result := database.DB.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", deliveries[i].ID, models.WebhookDeliveryStatusPending, time.Now()).
+			Update("next_attempt_at", webhookAttemptLease())
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+
+		var subscription models.WebhookSubscription
+		if err := database.DB.Where("id = ?", deliveries[i].SubscriptionID).First(&subscription).Error; err != nil || !subscription.IsActive {
+			deliveries[i].Status = models.WebhookDeliveryStatusFailed
+			deliveries[i].LastError = "Подписка удалена или отключена"
+			deliveries[i].NextAttemptAt = nil
+			database.DB.Save(&deliveries[i])
+			continue
+		}
+		ws.Deliver(&deliveries[i], &subscription)
+	}
+
+	if len(deliveries) > 0 {
+		ws.logger.Info("Повторная доставка вебхуков", zap.Int("count", len(deliveries)))
+	}
+}
+
+// Deliver выполняет одну попытку доставки и планирует следующую с экспоненциальной задержкой при ошибке
+func (ws *WebhookService) Deliver(delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) {
+	delivery.Attempts++
+	statusCode, err := ws.send(delivery, subscription)
+	delivery.ResponseStatus = statusCode
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliveryStatusSuccess
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= utils.WebhookMaxAttempts {
+			delivery.Status = models.WebhookDeliveryStatusFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			next := time.Now().Add(utils.WebhookRetryBaseDelay * time.Duration(1<<(delivery.Attempts-1)))
+			delivery.Status = models.WebhookDeliveryStatusPending
+			delivery.NextAttemptAt = &next
+		}
+		ws.logger.Warn("Ошибка доставки вебхука",
+			zap.String("deliveryID", delivery.ID.String()),
+			zap.String("url", subscription.URL),
+			zap.Int("attempt", delivery.Attempts),
+			zap.Error(err),
+		)
+	}
+
+	if err := database.DB.Save(delivery).Error; err != nil {
+		ws.logger.Error("Ошибка сохранения доставки вебхука", zap.String("deliveryID", delivery.ID.String()), zap.Error(err))
+	}
+}
+
+func (ws *WebhookService) enqueue(subscription *models.WebhookSubscription, eventType models.WebhookEventType, data interface{}) (*models.WebhookDelivery, error) {
+	deliveryID := uuid.New()
+	payload, err := json.Marshal(WebhookPayload{
+		ID:        deliveryID.String(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Первая попытка выполняется сразу, а RetryPending подхватит доставку только после ее таймаута:
+	// если попытка не состоится (например, процесс перезапустится), доставку повторит cron
+	delivery := models.WebhookDelivery{
+		ID:             deliveryID,
+		SubscriptionID: subscription.ID,
+		EventType:      eventType,
+		Payload:        string(payload),
+		Status:         models.WebhookDeliveryStatusPending,
+		NextAttemptAt:  webhookAttemptLease(),
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// webhookAttemptLease возвращает время, до которого доставка считается занятой текущей попыткой:
+// таймаут запроса с запасом, чтобы RetryPending не отправил ее повторно, пока попытка еще идет
+func webhookAttemptLease() *time.Time {
+	lease := time.Now().Add(utils.WebhookRequestTimeout + 30*time.Second)
+	return &lease
+}
+
+func (ws *WebhookService) send(delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Bekend-Webhooks/1.0")
+	req.Header.Set("X-Webhook-ID", delivery.ID.String())
+	req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил статусом %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload возвращает HMAC-SHA256 от "<timestamp>.<payload>" в hex.
+// Получатель должен вычислить ту же подпись и сравнить с заголовком X-Webhook-Signature.
+func SignWebhookPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
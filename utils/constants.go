@@ -17,7 +17,98 @@ const (
 	MaxAddressLength          = 500
 	MaxMapLinkLength          = 1000
 	MaxPaymentInfoLength      = 2000
-	EventReminderHours        = 24
+	MaxReminderMessageLength  = 500
+	ReviewInvitationExpiry    = 30 * 24 * time.Hour
 	MaxAvatarFileSize         = 10 * 1024 * 1024 // 10MB
+	TelegramLinkTokenExpiry   = 15 * time.Minute
+	WebhookMaxAttempts        = 6
+	WebhookRetryBaseDelay     = 1 * time.Minute
+	WebhookRequestTimeout     = 10 * time.Second
+	MaxWebhooksPerUser        = 10
+	GeocoderRequestTimeout    = 5 * time.Second
+	GeocodeCacheTTL           = 30 * 24 * time.Hour
+	EventGeocodeRetryDelay    = 5 * time.Minute // Через сколько повторять геокодинг, если провайдеры были недоступны
+	EventGeocodeBatchSize     = 50
+	MaxVenueNameLength        = 200
+	MaxAccessibilityInfoLength = 2000
+	MaxVenuePhotos            = 10
 )
 
+const (
+	DefaultSuggestLimit        = 5
+	MaxSuggestLimit            = 10
+	// Порог word_similarity для подсказок: ниже значения pg_trgm по умолчанию (0.6), чтобы находить слова с опечатками
+	SuggestSimilarityThreshold = 0.3
+)
+
+const (
+	DefaultGeoRadiusKm = 10
+	MaxGeoRadiusKm     = 500
+	MaxMapMarkers      = 500
+)
+
+// Часовой пояс платформы, если DEFAULT_TIMEZONE не задан
+const DefaultTimezoneName = "Europe/Moscow"
+
+const (
+	DefaultCityRadiusKm = 50
+	MaxCityRadiusKm     = 300
+	MaxCityNameLength   = 100
+)
+
+const (
+	DefaultRecommendationLimit = 20
+	MaxRecommendationLimit     = 50
+	// Сколько ближайших событий, подходящих хотя бы по одному сигналу пользователя, оценивается при построении ленты рекомендаций
+	RecommendationCandidateLimit = 500
+)
+
+const (
+	DefaultSimilarEventsLimit = 6
+	MaxSimilarEventsLimit     = 20
+	// Сколько похожих событий хранится для каждого события в similar_events
+	SimilarEventsPerEvent = 20
+)
+
+const (
+	DefaultTrendingLimit = 20
+	MaxTrendingLimit     = 50
+)
+
+const (
+	MaxSessionTitleLength = 200
+	MaxSessionRoomLength  = 100
+	MaxSessionTrackLength = 100
+	MaxSpeakerNameLength  = 100
+	MaxSpeakerBioLength   = 2000
+	MaxSpeakerLinks       = 10
+	MaxEventSessions      = 200
+	MaxEventSpeakers      = 100
+)
+
+const (
+	MaxRegistrationQuestions  = 20
+	MaxQuestionLabelLength    = 200
+	MaxQuestionOptions        = 20
+	MaxQuestionOptionLength   = 100
+	MaxRegistrationTextAnswer = 1000
+)
+
+// Доля занятых мест, при которой пользователям с событием в избранном приходит напоминание
+const BookmarkNearlyFullRatio = 0.8
+
+// Сколько адресов можно пригласить на событие одним запросом
+const MaxInvitationsPerRequest = 100
+
+// Максимальная длина сообщения в заявке на участие и причины отказа
+const MaxApplicationMessageLength = 1000
+
+const (
+	MaxTicketTypes           = 20
+	MaxTicketTypeNameLength  = 100
+	MaxTicketTypeDescription = 1000
+	MaxTicketPrice           = 100_000_000 // 1 000 000 ₽ в копейках
+	TicketCurrency           = "RUB"
+	OrderReservationTTL      = 15 * time.Minute // Сколько неоплаченный заказ держит место
+	PaymentRequestTimeout    = 15 * time.Second
+)
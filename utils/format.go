@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// FormatDurationRu форматирует интервал для текста напоминаний: "7 дней", "24 часа", "30 минут"
+func FormatDurationRu(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		days := int(d / (24 * time.Hour))
+		return fmt.Sprintf("%d %s", days, PluralRu(days, "день", "дня", "дней"))
+	case d >= time.Hour && d%time.Hour == 0:
+		hours := int(d / time.Hour)
+		return fmt.Sprintf("%d %s", hours, PluralRu(hours, "час", "часа", "часов"))
+	default:
+		minutes := int(d / time.Minute)
+		return fmt.Sprintf("%d %s", minutes, PluralRu(minutes, "минуту", "минуты", "минут"))
+	}
+}
+
+// PluralRu выбирает форму слова для числа n: 1 день, 2 дня, 5 дней
+func PluralRu(n int, one, few, many string) string {
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	default:
+		return many
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"time"
 
 	"bekend/config"
@@ -15,6 +17,55 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+const reviewInvitationSubject = "review_invitation"
+
+// reviewInvitationKey - ключ подписи приглашений, производный от JWT_SECRET, но отличный от ключа токенов входа:
+// ссылка из письма не должна работать как токен авторизации
+func reviewInvitationKey() []byte {
+	mac := hmac.New(sha256.New, []byte(config.AppConfig.JWTSecret))
+	mac.Write([]byte(reviewInvitationSubject))
+	return mac.Sum(nil)
+}
+
+// ReviewInvitationClaims - подписанное приглашение участника оценить прошедшее событие в один клик
+type ReviewInvitationClaims struct {
+	EventID uuid.UUID `json:"eventId"`
+	UserID  uuid.UUID `json:"userId"`
+	jwt.RegisteredClaims
+}
+
+func GenerateReviewInvitationToken(eventID, userID uuid.UUID) (string, error) {
+	claims := &ReviewInvitationClaims{
+		EventID: eventID,
+		UserID:  userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   reviewInvitationSubject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ReviewInvitationExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(reviewInvitationKey())
+}
+
+func ValidateReviewInvitationToken(tokenString string) (*ReviewInvitationClaims, error) {
+	claims := &ReviewInvitationClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return reviewInvitationKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid || claims.Subject != reviewInvitationSubject || claims.EventID == uuid.Nil || claims.UserID == uuid.Nil {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	return claims, nil
+}
+
 func GenerateToken(userID uuid.UUID, email, role string) (string, error) {
 	claims := &Claims{
 		UserID: userID,
@@ -34,13 +85,14 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(config.AppConfig.JWTSecret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
+	// Токены входа выдаются без Subject; токены другого назначения (приглашения и т.п.) не авторизуют
+	if !token.Valid || claims.Subject != "" {
 		return nil, jwt.ErrSignatureInvalid
 	}
 
@@ -0,0 +1,18 @@
+package utils
+
+import "fmt"
+
+// YandexMapLink - ссылка на точку в Яндекс.Картах
+func YandexMapLink(latitude, longitude float64) string {
+	return fmt.Sprintf("https://yandex.ru/maps/?pt=%f,%f&z=16", longitude, latitude)
+}
+
+// TwoGISMapLink - ссылка на точку в 2ГИС
+func TwoGISMapLink(latitude, longitude float64) string {
+	return fmt.Sprintf("https://2gis.ru/geo/%f,%f", longitude, latitude)
+}
+
+// OSMMapLink - ссылка на точку в OpenStreetMap
+func OSMMapLink(latitude, longitude float64) string {
+	return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%f&mlon=%f#map=16/%f/%f", latitude, longitude, latitude, longitude)
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"sync"
+	"time"
+
+	"bekend/config"
+)
+
+// EventTimeLayout - формат даты и времени события в письмах, сообщениях бота и выгрузках.
+// Сокращение пояса (MSK, +05) показывает, в каком часовом поясе указано время
+const EventTimeLayout = "02.01.2006 15:04 MST"
+
+var locationCache sync.Map
+
+// ValidateTimezone проверяет название часового пояса из базы IANA (например, Europe/Moscow).
+// Пустая строка и Local не допускаются: время на сервере не должно зависеть от его настроек
+func ValidateTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// DefaultLocation возвращает часовой пояс платформы из DEFAULT_TIMEZONE
+func DefaultLocation() *time.Location {
+	name := DefaultTimezoneName
+	if config.AppConfig != nil && config.AppConfig.DefaultTimezone != "" {
+		name = config.AppConfig.DefaultTimezone
+	}
+	return loadCachedLocation(name)
+}
+
+// EventLocation возвращает часовой пояс события; пустой или неизвестный пояс заменяется поясом платформы
+func EventLocation(name string) *time.Location {
+	if !ValidateTimezone(name) {
+		return DefaultLocation()
+	}
+	return loadCachedLocation(name)
+}
+
+// FormatEventTime форматирует момент времени в часовом поясе события
+func FormatEventTime(t time.Time, timezone string) string {
+	return t.In(EventLocation(timezone)).Format(EventTimeLayout)
+}
+
+func loadCachedLocation(name string) *time.Location {
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		loc = time.UTC
+	}
+	locationCache.Store(name, loc)
+	return loc
+}
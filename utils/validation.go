@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"net"
+	"net/url"
 	"regexp"
 	"strings"
 	"unicode"
@@ -112,3 +114,42 @@ func ValidateTags(tags []string) (bool, string) {
 	return true, ""
 }
 
+
+// ValidateWebhookURL проверяет адрес вебхука. Внутренние адреса, указанные явно, отклоняются сразу;
+// имена, которые разрешаются во внутреннюю сеть, отсекаются при подключении (см. services.newWebhookHTTPClient)
+func ValidateWebhookURL(rawURL string) bool {
+	if rawURL == "" || len(rawURL) > 1000 {
+		return false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return false
+	}
+	return true
+}
+
+// carrierGradeNAT - общее адресное пространство провайдеров (RFC 6598), в нем же метаданные некоторых облаков
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP - адрес из публичного интернета: не loopback, не частная сеть, не link-local
+// (в том числе 169.254.169.254 - метаданные облака), не multicast и не неопределенный адрес
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil && (ip4[0] == 0 || carrierGradeNAT.Contains(ip4)) {
+		return false
+	}
+	return true
+}